@@ -0,0 +1,137 @@
+package goref
+
+import (
+	"go/types"
+	"sort"
+)
+
+// An APIEntry describes an exported declaration of a package, along
+// with how other packages in the graph use it.
+type APIEntry struct {
+	// Kind of declaration
+	Kind DeclKind `json:"kind"`
+
+	// Ident is the declared identifier, qualified by its type for
+	// methods and fields.
+	Ident string `json:"ident"`
+
+	// Signature is the declaration's Go signature, with
+	// identifiers of the declaring package unqualified.
+	Signature string `json:"signature"`
+
+	// Position of the declared identifier
+	Position Position `json:"position"`
+
+	// RefCounts is the number of inbound Refs from other packages
+	// to this declaration, by RefType.
+	RefCounts map[string]int `json:"ref_counts"`
+
+	// Consumers is the sorted list of packages that reference
+	// this declaration.
+	Consumers []string `json:"consumers"`
+
+	// Methods and Fields are the exported methods and fields
+	// declared on a type. They are only set for types.
+	Methods []*APIEntry `json:"methods,omitempty"`
+	Fields  []*APIEntry `json:"fields,omitempty"`
+
+	// MethodSet is the method set of a type's pointer type (or of
+	// the type itself for interfaces), including promoted and
+	// embedded methods. It is only set for types.
+	MethodSet []string `json:"method_set,omitempty"`
+}
+
+// A PackageAPI is the exported API of a Package.
+type PackageAPI struct {
+	Path    string      `json:"loadpath"`
+	Version int64       `json:"version"`
+	Entries []*APIEntry `json:"entries"`
+}
+
+// newAPIEntry creates an APIEntry for a Decl and annotates it with
+// the Refs from other packages to it.
+func newAPIEntry(d *Decl) *APIEntry {
+	e := &APIEntry{
+		Kind:      d.Kind,
+		Ident:     d.Ident(),
+		Signature: types.ObjectString(d.Object, types.RelativeTo(d.Package.Types)),
+		Position:  d.Position,
+		RefCounts: make(map[string]int),
+		Consumers: make([]string, 0),
+	}
+	consumers := make(map[string]struct{})
+	for _, r := range d.Package.InRefsTo(d) {
+		if r.FromPackage == d.Package {
+			continue
+		}
+		e.RefCounts[r.RefType.String()]++
+		consumers[r.FromPackage.Path] = struct{}{}
+	}
+	for c := range consumers {
+		e.Consumers = append(e.Consumers, c)
+	}
+	sort.Strings(e.Consumers)
+	return e
+}
+
+// API returns the exported API of this package. Methods and fields
+// are listed under their type, and only for exported types.
+func (p *Package) API() *PackageAPI {
+	api := &PackageAPI{
+		Path:    p.Path,
+		Version: p.Version,
+		Entries: make([]*APIEntry, 0),
+	}
+	typeEntries := make(map[string]*APIEntry)
+	members := make([]*Decl, 0)
+	for _, d := range p.Decls() {
+		if !d.Exported() {
+			continue
+		}
+		switch d.Kind {
+		case MethodDecl, FieldDecl:
+			members = append(members, d)
+			continue
+		}
+		e := newAPIEntry(d)
+		if d.Kind == TypeDecl {
+			typeEntries[d.Name] = e
+			typ := d.Object.Type()
+			if !types.IsInterface(typ) {
+				typ = types.NewPointer(typ)
+			}
+			mset := types.NewMethodSet(typ)
+			for i := 0; i < mset.Len(); i++ {
+				if m := mset.At(i).Obj(); m.Exported() {
+					e.MethodSet = append(e.MethodSet, m.Name())
+				}
+			}
+		}
+		api.Entries = append(api.Entries, e)
+	}
+	for _, d := range members {
+		t, in := typeEntries[d.Recv]
+		if !in {
+			continue
+		}
+		if d.Kind == MethodDecl {
+			t.Methods = append(t.Methods, newAPIEntry(d))
+		} else {
+			t.Fields = append(t.Fields, newAPIEntry(d))
+		}
+	}
+	return api
+}
+
+// API returns the exported API of all packages in the graph, sorted
+// by load path.
+func (pg *PackageGraph) API() []*PackageAPI {
+	apis := make([]*PackageAPI, 0, len(pg.Packages))
+	for _, p := range pg.Packages {
+		apis = append(apis, p.API())
+	}
+	sort.Slice(apis, func(i, j int) bool {
+		return apis[i].Path < apis[j].Path
+	})
+	return apis
+}
@@ -0,0 +1,85 @@
+package goref_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+// findAPIEntry returns the APIEntry with the provided ident in a
+// slice of APIEntries, or nil.
+func findAPIEntry(entries []*goref.APIEntry, ident string) *goref.APIEntry {
+	for _, e := range entries {
+		if e.Ident == ident {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestPackageAPI(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/unused"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	api := pg.Packages[libpath].API()
+	assert.Equal(t, libpath, api.Path)
+
+	// Unexported identifiers are not part of the API.
+	assert.Nil(t, findAPIEntry(api.Entries, "unexportedFunc"))
+
+	f := findAPIEntry(api.Entries, "NewUsedType")
+	assert.NotNil(t, f)
+	assert.Equal(t, goref.FuncDecl, f.Kind)
+	assert.Equal(t, "func NewUsedType() *UsedType", f.Signature)
+	assert.Equal(t, map[string]int{"Call": 1}, f.RefCounts)
+	assert.Equal(t, []string{pkgpath}, f.Consumers)
+
+	c := findAPIEntry(api.Entries, "UnusedConst")
+	assert.NotNil(t, c)
+	assert.Empty(t, c.RefCounts)
+	assert.Empty(t, c.Consumers)
+
+	typ := findAPIEntry(api.Entries, "UsedType")
+	assert.NotNil(t, typ)
+	assert.Equal(t, []string{"Error", "String", "UnusedMethod", "UsedMethod"}, typ.MethodSet)
+	assert.Len(t, typ.Methods, 4)
+	assert.Len(t, typ.Fields, 2)
+	m := findAPIEntry(typ.Methods, "UsedType.UsedMethod")
+	assert.NotNil(t, m)
+	assert.Equal(t, "func (*UsedType).UsedMethod()", m.Signature)
+	assert.Equal(t, map[string]int{"Call": 1}, m.RefCounts)
+	field := findAPIEntry(typ.Fields, "UsedType.UsedField")
+	assert.NotNil(t, field)
+	assert.Equal(t, goref.FieldDecl, field.Kind)
+	assert.Equal(t, map[string]int{"Reference": 1}, field.RefCounts)
+
+	_, err := json.Marshal(api)
+	assert.NoError(t, err)
+}
+
+func TestPackageAPI_interfaces(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	pg.ComputeInterfaceImplementationMatrix()
+	api := pg.Packages[libpath].API()
+
+	iface := findAPIEntry(api.Entries, "IfaceLibA")
+	assert.NotNil(t, iface)
+	// A and AB from package main implement IfaceLibA. Extensions
+	// are IfaceA and IfaceAB.
+	assert.Equal(t, map[string]int{"Implementation": 2, "Extension": 2}, iface.RefCounts)
+	assert.Equal(t, []string{pkgpath}, iface.Consumers)
+	assert.Equal(t, []string{"A"}, iface.MethodSet)
+	assert.Len(t, iface.Methods, 1)
+}
@@ -0,0 +1,165 @@
+package goref
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"sort"
+)
+
+// APIChangeKind is an enum of the ways an exported declaration can
+// change between two versions of a package.
+type APIChangeKind int
+
+// These are the possible kinds of API changes.
+const (
+	// Removed is a declaration that no longer exists.
+	Removed APIChangeKind = iota
+
+	// Changed is a declaration whose signature changed.
+	Changed
+)
+
+func (k APIChangeKind) String() string {
+	switch k {
+	case Removed:
+		return "Removed"
+	case Changed:
+		return "Changed"
+	}
+	panic("Unknown APIChangeKind used")
+}
+
+// MarshalJSON implements encoding/json.Marshaler interface
+func (k APIChangeKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// An APIChange is a breaking change to an exported declaration
+// between two versions of a package.
+type APIChange struct {
+	Kind APIChangeKind
+
+	// Old is the declaration in the older version.
+	Old *Decl
+
+	// New is the declaration in the newer version. It is nil if
+	// the declaration was removed.
+	New *Decl
+
+	// BrokenRefs are the Refs from other packages that this
+	// change breaks.
+	BrokenRefs []*Ref
+}
+
+func (c *APIChange) String() string {
+	if c.New == nil {
+		return fmt.Sprintf("%s: %s", c.Kind, c.Old)
+	}
+	return fmt.Sprintf("%s: %s (%s -> %s)", c.Kind, c.Old, apiSignature(c.Old), apiSignature(c.New))
+}
+
+// apiSignature returns a string that changes if and only if a change
+// to the declaration may break its users. Struct types only compare
+// by name, as their fields are compared individually.
+func apiSignature(d *Decl) string {
+	if d.Kind == TypeDecl {
+		if _, ok := d.Object.Type().Underlying().(*types.Struct); ok {
+			return "type " + d.Name + " struct"
+		}
+	}
+	return types.ObjectString(d.Object, types.RelativeTo(d.Package.Types))
+}
+
+// brokenImplementations returns the cross-package Implementation
+// Refs from the receiver type of a method that require that method.
+func brokenImplementations(m *Decl) []*Ref {
+	refs := make([]*Ref, 0)
+	for _, r := range m.Package.OutRefs {
+		if r.RefType != Implementation || r.FromIdent != m.Recv || r.ToPackage == m.Package || r.ToPackage.Types == nil {
+			continue
+		}
+		iface, ok := r.ToPackage.Types.Scope().Lookup(r.ToIdent).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if obj, _, _ := types.LookupFieldOrMethod(iface.Type(), false, nil, m.Name); obj != nil {
+			refs = append(refs, r)
+		}
+	}
+	return refs
+}
+
+// brokenRefs returns the Refs from other packages to the declaration
+// that an APIChange affects, skipping Refs from packages that were
+// loaded at a different version in newpg: those were type-checked
+// against the newer API already.
+func brokenRefs(d *Decl, newpg *PackageGraph) []*Ref {
+	refs := d.Package.InRefsTo(d)
+	if d.Kind == MethodDecl {
+		refs = append(refs, brokenImplementations(d)...)
+	}
+	broken := make([]*Ref, 0)
+	for _, r := range refs {
+		if r.FromPackage == d.Package && r.ToPackage == d.Package {
+			continue
+		}
+		dependent := r.FromPackage
+		if r.FromPackage == d.Package {
+			dependent = r.ToPackage
+		}
+		if p, in := newpg.Packages[dependent.Path]; in && p.Version != dependent.Version {
+			continue
+		}
+		broken = append(broken, r)
+	}
+	return broken
+}
+
+// DiffAPI compares the exported declarations of packages loaded in
+// two PackageGraphs, typically the same corpus at two versions, and
+// returns the removed and changed declarations.
+//
+// Each APIChange lists the cross-package Refs of oldpg that the
+// change breaks, including Implementation Refs broken by removed or
+// changed methods. Refs from dependents that newpg loaded at another
+// version are not reported, as these dependents type-check against
+// the newer API.
+//
+// Packages of oldpg that are absent from newpg, and main packages,
+// are ignored.
+func DiffAPI(oldpg, newpg *PackageGraph) []*APIChange {
+	changes := make([]*APIChange, 0)
+	for path, oldp := range oldpg.Packages {
+		newp, in := newpg.Packages[path]
+		if !in || oldp.Name == "main" {
+			continue
+		}
+		newDecls := make(map[string]*Decl)
+		for _, d := range newp.Decls() {
+			if d.Exported() {
+				newDecls[d.Ident()] = d
+			}
+		}
+		for _, d := range oldp.Decls() {
+			if !d.Exported() {
+				continue
+			}
+			c := &APIChange{Old: d}
+			if nd, in := newDecls[d.Ident()]; !in {
+				c.Kind = Removed
+			} else if nd.Kind != d.Kind || apiSignature(nd) != apiSignature(d) {
+				c.Kind = Changed
+				c.New = nd
+			} else {
+				continue
+			}
+			c.BrokenRefs = brokenRefs(d, newpg)
+			changes = append(changes, c)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Old.Package.Path < changes[j].Old.Package.Path
+	})
+	return changes
+}
@@ -0,0 +1,69 @@
+package goref_test
+
+import (
+	"go/build"
+	"path/filepath"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+// loadFromGOPATH loads packages into a new PackageGraph using a
+// GOPATH relative to the testprograms directory.
+func loadFromGOPATH(t *testing.T, gopath string, packages ...string) *goref.PackageGraph {
+	abs, err := filepath.Abs(filepath.Join("testprograms", gopath))
+	assert.NoError(t, err)
+	ctxt := build.Default
+	ctxt.GOPATH = abs
+	pg := goref.NewPackageGraph(goref.FileMTimeVersion)
+	pg.SetBuildContext(&ctxt)
+	assert.NoError(t, pg.LoadPackages(packages, false))
+	pg.ComputeInterfaceImplementationMatrix()
+	return pg
+}
+
+func TestDiffAPI(t *testing.T) {
+	oldpg := loadFromGOPATH(t, "apidiff/testdata/old", "example.com/app")
+	newpg := loadFromGOPATH(t, "apidiff/testdata/new", "example.com/lib")
+
+	changes := make(map[string]*goref.APIChange)
+	for _, c := range goref.DiffAPI(oldpg, newpg) {
+		assert.Equal(t, "example.com/lib", c.Old.Package.Path)
+		changes[c.Old.Ident()] = c
+	}
+	assert.Len(t, changes, 4)
+
+	if c := changes["Config.Timeout"]; assert.NotNil(t, c) {
+		assert.Equal(t, goref.Removed, c.Kind)
+		assert.Nil(t, c.New)
+		assert.Len(t, c.BrokenRefs, 1)
+	}
+	if c := changes["New"]; assert.NotNil(t, c) {
+		assert.Equal(t, goref.Changed, c.Kind)
+		assert.Equal(t, "Changed: func example.com/lib.New (func New(name string) *Config -> func New(name string, verbose bool) *Config)", c.String())
+		assert.Len(t, c.BrokenRefs, 1)
+		assert.EqualValues(t, goref.Call, c.BrokenRefs[0].RefType)
+	}
+	if c := changes["Removed"]; assert.NotNil(t, c) {
+		assert.Equal(t, goref.Removed, c.Kind)
+		assert.Len(t, c.BrokenRefs, 1)
+	}
+	if c := changes["Config.Close"]; assert.NotNil(t, c) {
+		assert.Equal(t, goref.Removed, c.Kind)
+		// Nothing calls Close, but lib.Config no longer
+		// implements app.Closer.
+		if assert.Len(t, c.BrokenRefs, 1) {
+			r := c.BrokenRefs[0]
+			assert.EqualValues(t, goref.Implementation, r.RefType)
+			assert.Equal(t, "Closer", r.ToIdent)
+			assert.Equal(t, "example.com/app", r.ToPackage.Path)
+		}
+	}
+}
+
+func TestDiffAPI_noChanges(t *testing.T) {
+	oldpg := loadFromGOPATH(t, "apidiff/testdata/new", "example.com/lib")
+	newpg := loadFromGOPATH(t, "apidiff/testdata/new", "example.com/lib")
+	assert.Empty(t, goref.DiffAPI(oldpg, newpg))
+}
@@ -0,0 +1,175 @@
+package goref
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// CallGraphAlgorithm is an enum of the call graph construction
+// algorithms that can be used to resolve dynamic calls.
+type CallGraphAlgorithm int
+
+// These are the supported call graph algorithms, from the least to
+// the most precise (and expensive).
+const (
+	// NoCallGraph disables the call graph analysis. This is the
+	// default.
+	NoCallGraph CallGraphAlgorithm = iota
+
+	// CHA (Class Hierarchy Analysis) assumes that a call through
+	// an interface may dispatch to any type that implements the
+	// interface.
+	CHA
+
+	// RTA (Rapid Type Analysis) only considers the types that
+	// are instantiated in code reachable from the main packages
+	// being loaded. Without main packages, it finds no calls.
+	RTA
+
+	// VTA (Variable Type Analysis) tracks the types that flow to
+	// each call site.
+	VTA
+)
+
+func (a CallGraphAlgorithm) String() string {
+	switch a {
+	case NoCallGraph:
+		return "none"
+	case CHA:
+		return "cha"
+	case RTA:
+		return "rta"
+	case VTA:
+		return "vta"
+	}
+	panic("Unknown CallGraphAlgorithm used")
+}
+
+// ParseCallGraphAlgorithm returns the CallGraphAlgorithm designated by
+// s, which is one of "none", "cha", "rta" or "vta".
+func ParseCallGraphAlgorithm(s string) (CallGraphAlgorithm, error) {
+	for _, a := range []CallGraphAlgorithm{NoCallGraph, CHA, RTA, VTA} {
+		if a.String() == s {
+			return a, nil
+		}
+	}
+	return NoCallGraph, fmt.Errorf("Unknown call graph algorithm %s", s)
+}
+
+// buildCallGraph builds the SSA form of a loaded program and computes
+// its call graph with the provided algorithm.
+func buildCallGraph(lprog *loader.Program, algo CallGraphAlgorithm) *callgraph.Graph {
+	prog := ssautil.CreateProgram(lprog, ssa.InstantiateGenerics)
+	prog.Build()
+
+	switch algo {
+	case CHA:
+		return cha.CallGraph(prog)
+	case RTA:
+		roots := make([]*ssa.Function, 0)
+		for _, main := range ssautil.MainPackages(prog.AllPackages()) {
+			for _, name := range []string{"init", "main"} {
+				if f := main.Func(name); f != nil {
+					roots = append(roots, f)
+				}
+			}
+		}
+		if len(roots) == 0 {
+			log.Warn("RTA call graph requested but no main packages were loaded.")
+			return callgraph.New(nil)
+		}
+		return rta.Analyze(roots, true).CallGraph
+	case VTA:
+		return vta.CallGraph(ssautil.AllFunctions(prog), cha.CallGraph(prog))
+	}
+	panic("Unknown CallGraphAlgorithm used")
+}
+
+// callSiteIdent returns the expression that designates the callee of
+// a call site: the method or function identifier if there is one, or
+// the whole function expression otherwise (e.g. for `fns[i]()`).
+func callSiteIdent(prog *loader.Program, site ssa.CallInstruction) ast.Expr {
+	pos := site.Common().Pos()
+	_, path, _ := prog.PathEnclosingInterval(pos, pos)
+	for _, n := range path {
+		if call, ok := n.(*ast.CallExpr); ok && call.Lparen == pos {
+			fun := astutil.Unparen(call.Fun)
+			if sel, ok := fun.(*ast.SelectorExpr); ok {
+				return sel.Sel
+			}
+			return fun
+		}
+	}
+	return nil
+}
+
+// addDynamicCalls computes the call graph of a loaded program and
+// adds a DynamicCall Ref from each dynamic call site (calls through
+// an interface or a function value) to each function or method it
+// may dispatch to. Only call sites in the packages of `loaded` are
+// considered, so that Refs are not duplicated when a package is part
+// of several loaded programs.
+//
+// Unlike most RefTypes, DynamicCall Refs are also recorded when the
+// call site and the callee are in the same package. Calls to
+// anonymous functions are ignored, as they can't be referenced.
+func (pg *PackageGraph) addDynamicCalls(lprog *loader.Program, loaded map[*Package]bool) {
+	cg := buildCallGraph(lprog, pg.callGraph)
+
+	type edge struct {
+		site   ssa.CallInstruction
+		callee types.Object
+	}
+	seen := make(map[edge]bool)
+	for _, n := range cg.Nodes {
+		for _, e := range n.Out {
+			if e.Site == nil || e.Site.Common().StaticCallee() != nil {
+				continue
+			}
+			callee := e.Callee.Func.Object()
+			if callee == nil || callee.Pkg() == nil || seen[edge{e.Site, callee}] {
+				continue
+			}
+			seen[edge{e.Site, callee}] = true
+
+			caller := e.Site.Parent()
+			if caller.Pkg == nil && caller.Origin() != nil {
+				caller = caller.Origin()
+			}
+			if caller.Pkg == nil {
+				continue
+			}
+			fromPkg := pg.Packages[caller.Pkg.Pkg.Path()]
+			toPkg := pg.Packages[callee.Pkg().Path()]
+			if fromPkg == nil || toPkg == nil || !loaded[fromPkg] {
+				continue
+			}
+			id := callSiteIdent(lprog, e.Site)
+			if id == nil {
+				continue
+			}
+			r := &Ref{
+				RefType:      DynamicCall,
+				ToIdent:      callee.Name(),
+				ToPackage:    toPkg,
+				ToPosition:   NewPosition(toPkg.Corpus, lprog.Fset, callee.Pos(), NoPos),
+				FromIdent:    types.ExprString(id),
+				FromPackage:  fromPkg,
+				FromPosition: NewPosition(fromPkg.Corpus, lprog.Fset, id.Pos(), id.End()),
+			}
+			toPkg.InRefs = append(toPkg.InRefs, r)
+			fromPkg.OutRefs = append(fromPkg.OutRefs, r)
+		}
+	}
+}
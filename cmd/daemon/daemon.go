@@ -2,18 +2,19 @@ package main
 
 import (
 	"flag"
-	"fmt"
-	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
-	"path/filepath"
+	"strings"
 
 	"golang.org/x/net/context"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/korfuri/goref"
 	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/cmd/serve/rpc"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/bolt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -26,99 +27,17 @@ const (
 var (
 	includeTests = flag.Bool("include_tests", true,
 		"Whether XTest packages should be included in the index.")
+	callGraph = flag.String("callgraph", "none",
+		"Algorithm used to resolve dynamic calls: none, cha, rta or vta.")
+	localRefs = flag.Bool("local_refs", false,
+		"Whether references within a package are recorded, so that GetDefinition resolves them. "+
+			"This roughly doubles the memory used by the graph.")
+	storeSpec = flag.String("store", "",
+		"If set to bolt:<path>, references are served from a bbolt database built by index -store "+
+			"instead of loading the requested packages, so that the daemon restarts without loading them again.")
 )
 
-// server implements pb.GorefServer
-type server struct {
-	graph goref.PackageGraph
-}
-
-func (s server) GetAnnotations(ctx context.Context, req *pb.GetAnnotationsRequest) (*pb.GetAnnotationsResponse, error) {
-	fpath := req.Path
-	corpus, err := s.findCorpus(fpath)
-	if err != nil {
-		return nil, err
-	}
-	fmt.Printf("%s\n", corpus.Pkg(fpath))
-	pkg, in := s.graph.Packages[corpus.Pkg(fpath)]
-	if !in {
-		return nil, fmt.Errorf("Internal server error")
-	}
-
-	res := &pb.GetAnnotationsResponse{
-		Path: fpath,
-	}
-	for _, r := range pkg.InRefs {
-		if r.ToPosition.File == fpath {
-			res.Annotation = append(res.Annotation, r.ToProto())
-		}
-	}
-	for _, r := range pkg.OutRefs {
-		if r.FromPosition.File == fpath {
-			res.Annotation = append(res.Annotation, r.ToProto())
-		}
-	}
-
-	return res, nil
-}
-
-func (s server) GetFiles(ctx context.Context, req *pb.GetFilesRequest) (*pb.GetFilesResponse, error) {
-	pkg, in := s.graph.Packages[req.Package]
-	if !in {
-		return nil, fmt.Errorf("Unknown package")
-	}
-
-	res := &pb.GetFilesResponse{
-		Package: req.Package,
-	}
-	for _, f := range pkg.Files {
-		res.Filename = append(res.Filename, f)
-	}
-
-	return res, nil
-}
-
-func (s server) GetPackages(ctx context.Context, req *pb.GetPackagesRequest) (*pb.GetPackagesResponse, error) {
-	res := &pb.GetPackagesResponse{}
-	for _, pkg := range s.graph.Packages {
-		res.Package = append(res.Package, pkg.Path)
-	}
-	return res, nil
-}
-
-func (s server) findCorpus(fpath string) (goref.Corpus, error) {
-	if filepath.Ext(fpath) != ".go" {
-		return goref.Corpus(""), fmt.Errorf("Not found: invalid extension")
-	}
-	var corpus goref.Corpus
-	for _, c := range s.graph.Corpora {
-		if c.ContainsRel(fpath) {
-			corpus = c
-			break
-		}
-	}
-	if corpus == "" {
-		return goref.Corpus(""), fmt.Errorf("Not found under any corpus")
-	}
-	return corpus, nil
-}
-
-func (s server) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFileResponse, error) {
-	fpath := req.Path
-	corpus, err := s.findCorpus(fpath)
-	if err != nil {
-		return nil, err
-	}
-	if f, err := ioutil.ReadFile(corpus.Abs(fpath)); err == nil {
-		return &pb.GetFileResponse{
-			Path:     fpath,
-			Contents: string(f),
-		}, nil
-	}
-	return nil, fmt.Errorf("Internal server error")
-}
-
-func runGRPC(s *server, grpcReady chan struct{}) error {
+func runGRPC(s pb.GorefServer, grpcReady chan struct{}) error {
 	lis, err := net.Listen("tcp", grpcListenAddr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
@@ -151,18 +70,42 @@ func runGateway(grpcReady <-chan struct{}) error {
 	return http.ListenAndServe(gatewayListenAddr, mux)
 }
 
+// openStore opens the bbolt database selected by -store.
+func openStore() store.Store {
+	if !strings.HasPrefix(*storeSpec, "bolt:") {
+		log.Fatalf("Unsupported store %q", *storeSpec)
+	}
+	// The database is memory-mapped, so it's served without
+	// loading any package.
+	path := strings.TrimPrefix(*storeSpec, "bolt:")
+	st, err := bolt.OpenReadOnly(path)
+	if err != nil {
+		log.Fatalf("Couldn't open %s: %s", path, err)
+	}
+	return st
+}
+
 func main() {
 	flag.Parse()
 	args := flag.Args()
 
+	grpcReady := make(chan struct{})
+	go runGateway(grpcReady)
+	if *storeSpec != "" {
+		runGRPC(rpc.NewStoreServer(goref.DefaultCorpora(), openStore()), grpcReady)
+		return
+	}
+
 	// Index the requested packages
 	pg := goref.NewPackageGraph(goref.FileMTimeVersion)
+	algo, err := goref.ParseCallGraphAlgorithm(*callGraph)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pg.SetCallGraph(algo)
+	pg.SetLocalRefs(*localRefs)
 	pg.LoadPackages(args, *includeTests)
+	pg.ComputeInterfaceImplementationMatrix()
 
-	grpcReady := make(chan struct{})
-	go runGateway(grpcReady)
-	s := &server{
-		graph: *pg,
-	}
-	runGRPC(s, grpcReady)
+	runGRPC(rpc.NewGraphServer(*pg), grpcReady)
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/korfuri/goref"
+)
+
+var (
+	apiFlags        = flag.NewFlagSet("api", flag.ExitOnError)
+	apiIncludeTests = apiFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded. References from tests count as uses.")
+	apiAll = apiFlags.Bool("all", false,
+		"Report the API of all loaded packages, including dependencies outside of the requested load paths.")
+
+	apiCmd = &command{
+		help:  "dump the exported API of packages as JSON, annotated with its uses",
+		flags: apiFlags,
+		run:   runAPI,
+	}
+)
+
+func runAPI(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	pg, err := loadGraph(args, *apiIncludeTests, false)
+	if err != nil {
+		return err
+	}
+	apis := make([]*goref.PackageAPI, 0)
+	for _, api := range pg.API() {
+		if *apiAll || hasAnyPrefix(api.Path, args) {
+			apis = append(apis, api)
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(apis)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+
+	"github.com/korfuri/goref"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	apidiffFlags        = flag.NewFlagSet("apidiff", flag.ExitOnError)
+	apidiffIncludeTests = apidiffFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded. References from tests can break too.")
+	apidiffOldGOPATH = apidiffFlags.String("old_gopath", "",
+		"GOPATH containing the older version of the corpus.")
+	apidiffNewGOPATH = apidiffFlags.String("new_gopath", "",
+		"GOPATH containing the newer version of the corpus.")
+	apidiffNewPackages = apidiffFlags.String("new_packages", "",
+		"Comma-separated list of packages to load from the newer version, if different from the packages loaded from the older version.")
+
+	apidiffCmd = &command{
+		help:  "list breaking API changes between two versions of a corpus",
+		flags: apidiffFlags,
+		run:   runAPIDiff,
+	}
+)
+
+// loadGraphFromGOPATH is like loadGraph, but locates packages in the
+// provided GOPATH.
+func loadGraphFromGOPATH(gopath string, packages []string, includeTests bool) (*goref.PackageGraph, error) {
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	log.Infof("Loading packages from GOPATH %s: %v", gopath, packages)
+	pg := goref.NewPackageGraph(goref.FileMTimeVersion)
+	pg.SetBuildContext(&ctxt)
+	if err := pg.LoadPackages(packages, includeTests); err != nil {
+		return nil, err
+	}
+	pg.ComputeInterfaceImplementationMatrix()
+	return pg, nil
+}
+
+func runAPIDiff(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	if *apidiffOldGOPATH == "" || *apidiffNewGOPATH == "" {
+		return errors.New("both -old_gopath and -new_gopath are required")
+	}
+	newArgs := args
+	if *apidiffNewPackages != "" {
+		newArgs = strings.Split(*apidiffNewPackages, ",")
+	}
+	oldpg, err := loadGraphFromGOPATH(*apidiffOldGOPATH, args, *apidiffIncludeTests)
+	if err != nil {
+		return err
+	}
+	newpg, err := loadGraphFromGOPATH(*apidiffNewGOPATH, newArgs, *apidiffIncludeTests)
+	if err != nil {
+		return err
+	}
+
+	breaking := 0
+	for _, c := range goref.DiffAPI(oldpg, newpg) {
+		fmt.Fprintf(os.Stdout, "%s: %s\n", c.Old.Position, c)
+		for _, r := range c.BrokenRefs {
+			fmt.Fprintf(os.Stdout, "\tbreaks %s\n", r)
+		}
+		if len(c.BrokenRefs) > 0 {
+			breaking++
+		}
+	}
+	if breaking > 0 {
+		return fmt.Errorf("%d changes break other packages", breaking)
+	}
+	return nil
+}
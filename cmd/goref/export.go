@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/export"
+)
+
+var (
+	exportFlags        = flag.NewFlagSet("export", flag.ExitOnError)
+	exportIncludeTests = exportFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded and exported.")
+	exportFormat = exportFlags.String("format", "jsonl",
+		"Output format: jsonl, with one package, file or ref record per line, which index -import reads; "+
+			"cypher, with Cypher statements that create a Neo4j graph; or neo4j-csv, with CSV files for "+
+			"neo4j-admin import, written to -out.")
+	exportOut = exportFlags.String("out", ".",
+		"Directory where -format=neo4j-csv writes its files.")
+	exportAll = exportFlags.Bool("all", false,
+		"Export all loaded packages, including dependencies outside of the requested load paths.")
+
+	exportCmd = &command{
+		help:  "export packages, files and references to be imported elsewhere",
+		flags: exportFlags,
+		run:   runExport,
+	}
+
+	// exporters write the packages of a PackageGraph that include
+	// accepts to stdout, in each supported format.
+	exporters = map[string]func(io.Writer, *goref.PackageGraph, func(string) bool) error{
+		"jsonl":  export.WriteJSONL,
+		"cypher": export.WriteCypher,
+	}
+)
+
+func runExport(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	write, ok := exporters[*exportFormat]
+	if !ok && *exportFormat != "neo4j-csv" {
+		return fmt.Errorf("unsupported format %q", *exportFormat)
+	}
+
+	// Neo4j graphs have references between declarations of the
+	// same package.
+	pg, err := loadGraph(args, *exportIncludeTests, *exportFormat != "jsonl")
+	if err != nil {
+		return err
+	}
+
+	include := func(loadpath string) bool {
+		return hasAnyPrefix(loadpath, args)
+	}
+	if *exportAll {
+		include = nil
+	}
+	if *exportFormat == "neo4j-csv" {
+		if err := os.MkdirAll(*exportOut, 0755); err != nil {
+			return err
+		}
+		return export.WriteNeo4jCSV(*exportOut, pg, include)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := write(w, pg, include); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/export"
+)
+
+var (
+	graphFlags        = flag.NewFlagSet("graph", flag.ExitOnError)
+	graphIncludeTests = graphFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded. References from tests are part of the graph.")
+	graphFormat = graphFlags.String("format", "dot",
+		"Output format: dot, graphml or json (node-link).")
+	graphGranularity = graphFlags.String("granularity", "package",
+		"What nodes stand for: package, or ident for declarations.")
+	graphTypes = graphFlags.String("types", "",
+		"Comma-separated types of references to include, e.g. Import,Call. All types are included if empty.")
+	graphPrefixes = graphFlags.String("prefixes", "",
+		"Comma-separated load path prefixes. Only references between packages under them are included. "+
+			"Defaults to the requested load paths.")
+	graphAll = graphFlags.Bool("all", false,
+		"Include references between all loaded packages, including dependencies outside of -prefixes.")
+
+	graphCmd = &command{
+		help:  "export the package or symbol reference graph as DOT, GraphML or JSON",
+		flags: graphFlags,
+		run:   runGraph,
+	}
+
+	graphWriters = map[string]func(io.Writer, *goref.Graph) error{
+		"dot":     export.WriteDOT,
+		"graphml": export.WriteGraphML,
+		"json":    export.WriteNodeLinkJSON,
+	}
+)
+
+// splitList splits a comma-separated list, ignoring empty elements.
+func splitList(s string) []string {
+	l := make([]string, 0)
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+func runGraph(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	write, ok := graphWriters[*graphFormat]
+	if !ok {
+		return fmt.Errorf("unsupported format %q", *graphFormat)
+	}
+	opts := goref.GraphOptions{Prefixes: args}
+	switch *graphGranularity {
+	case "package":
+		opts.Granularity = goref.PackageGranularity
+	case "ident":
+		opts.Granularity = goref.IdentGranularity
+	default:
+		return fmt.Errorf("unsupported granularity %q", *graphGranularity)
+	}
+	for _, t := range splitList(*graphTypes) {
+		rt, err := goref.ParseRefType(t)
+		if err != nil {
+			return err
+		}
+		opts.RefTypes = append(opts.RefTypes, rt)
+	}
+	if prefixes := splitList(*graphPrefixes); len(prefixes) > 0 {
+		opts.Prefixes = prefixes
+	}
+	if *graphAll {
+		opts.Prefixes = nil
+	}
+
+	// References within a package are edges between declarations.
+	pg, err := loadGraph(args, *graphIncludeTests, opts.Granularity == goref.IdentGranularity)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := write(w, pg.Graph(opts)); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/korfuri/goref"
+)
+
+var (
+	impactFlags        = flag.NewFlagSet("impact", flag.ExitOnError)
+	impactIncludeTests = impactFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded. This is required to find affected tests.")
+	impactDiff = impactFlags.String("diff", "",
+		"Path to a unified diff describing the change, or - to read it from stdin.")
+	impactChanged = impactFlags.String("changed", "",
+		"Comma-separated list of changed files and line ranges, of the form file.go:start-end, file.go:line or file.go.")
+	impactTestsOnly = impactFlags.Bool("tests_only", false,
+		"Only print the load paths of packages whose tests should run, one per line.")
+
+	impactCmd = &command{
+		help:  "list declarations, packages and tests affected by a change",
+		flags: impactFlags,
+		run:   runImpact,
+	}
+)
+
+// readChanges returns the changed line ranges described by the
+// -diff and -changed flags.
+func readChanges() ([]goref.LineRange, error) {
+	changes := make([]goref.LineRange, 0)
+	if *impactDiff != "" {
+		var r io.Reader = os.Stdin
+		if *impactDiff != "-" {
+			f, err := os.Open(*impactDiff)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		ranges, err := goref.ParseUnifiedDiff(r)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, ranges...)
+	}
+	if *impactChanged != "" {
+		for _, s := range strings.Split(*impactChanged, ",") {
+			lr, err := goref.ParseLineRange(s)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, lr)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("one of -diff or -changed is required")
+	}
+	return changes, nil
+}
+
+func runImpact(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	changes, err := readChanges()
+	if err != nil {
+		return err
+	}
+	pg, err := loadGraph(args, *impactIncludeTests, true)
+	if err != nil {
+		return err
+	}
+
+	impact := pg.Impact(changes)
+	if *impactTestsOnly {
+		for _, p := range impact.TestPackages {
+			fmt.Fprintln(os.Stdout, p)
+		}
+		return nil
+	}
+	fmt.Fprintln(os.Stdout, "Changed declarations:")
+	for _, d := range impact.Changed {
+		fmt.Fprintf(os.Stdout, "\t%s: %s\n", d.Position, d)
+	}
+	fmt.Fprintln(os.Stdout, "Affected declarations:")
+	for _, d := range impact.Affected {
+		fmt.Fprintf(os.Stdout, "\t%s: %s\n", d.Position, d)
+	}
+	fmt.Fprintln(os.Stdout, "Affected packages:")
+	for _, p := range impact.Packages {
+		fmt.Fprintf(os.Stdout, "\t%s\n", p)
+	}
+	fmt.Fprintln(os.Stdout, "Test packages:")
+	for _, p := range impact.TestPackages {
+		fmt.Fprintf(os.Stdout, "\t%s\n", p)
+	}
+	return nil
+}
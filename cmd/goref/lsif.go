@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/korfuri/goref/export"
+)
+
+var (
+	lsifFlags        = flag.NewFlagSet("lsif", flag.ExitOnError)
+	lsifIncludeTests = lsifFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded and included in the dump.")
+	lsifAll = lsifFlags.Bool("all", false,
+		"Include the files of all loaded packages, including dependencies outside of the requested load paths.")
+	lsifRoot = lsifFlags.String("root", ".",
+		"Root directory of the project, recorded in the dump.")
+
+	lsifCmd = &command{
+		help:  "dump references as LSIF, for code intelligence tools",
+		flags: lsifFlags,
+		run:   runLSIF,
+	}
+)
+
+func runLSIF(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	root, err := filepath.Abs(*lsifRoot)
+	if err != nil {
+		return err
+	}
+	// Definitions and references within a package are part of
+	// the dump.
+	pg, err := loadGraph(args, *lsifIncludeTests, true)
+	if err != nil {
+		return err
+	}
+	include := func(loadpath string) bool {
+		return hasAnyPrefix(loadpath, args)
+	}
+	if *lsifAll {
+		include = nil
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := export.WriteLSIF(w, pg, root, include); err != nil {
+		return err
+	}
+	return w.Flush()
+}
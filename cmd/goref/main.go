@@ -0,0 +1,109 @@
+// Command goref runs analyses over a PackageGraph built from a set of
+// Go packages and prints their results.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/korfuri/goref"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Usage help line
+	Usage = `goref <command> [flags] packages...
+
+Commands:
+`
+)
+
+// A command is a goref subcommand.
+type command struct {
+	// Help line for this command
+	help string
+
+	// run executes the command with the provided flags and
+	// arguments. Flags are parsed by main before run is called.
+	run func(args []string) error
+
+	// flags returns the FlagSet for this command.
+	flags *flag.FlagSet
+}
+
+var (
+	errNoPackages = errors.New("no packages specified")
+
+	commands = map[string]*command{
+		"api":     apiCmd,
+		"apidiff": apidiffCmd,
+		"export":  exportCmd,
+		"graph":   graphCmd,
+		"impact":  impactCmd,
+		"lsif":    lsifCmd,
+		"unused":  unusedCmd,
+	}
+)
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %-10s %s", name, commands[name].help))
+	}
+	fmt.Fprintf(os.Stderr, "%s%s\n", Usage, strings.Join(lines, "\n"))
+	os.Exit(2)
+}
+
+// loadGraph loads the provided packages into a new PackageGraph and
+// computes its interface-implementation matrix. References within a
+// package are only recorded with localRefs.
+func loadGraph(packages []string, includeTests, localRefs bool) (*goref.PackageGraph, error) {
+	log.Infof("Loading packages: %v", packages)
+	pg := goref.NewPackageGraph(goref.FileMTimeVersion)
+	pg.SetLocalRefs(localRefs)
+	if err := pg.LoadPackages(packages, includeTests); err != nil {
+		return nil, err
+	}
+	log.Info("Computing the interface-implementation matrix.")
+	pg.ComputeInterfaceImplementationMatrix()
+	log.Infof("%d packages in the graph.", len(pg.Packages))
+	return pg, nil
+}
+
+// hasAnyPrefix returns whether loadpath starts with any of the
+// provided prefixes.
+func hasAnyPrefix(loadpath string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(loadpath, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+	}
+	if err := cmd.flags.Parse(flag.Args()[1:]); err != nil {
+		log.Fatal(err)
+	}
+	if err := cmd.run(cmd.flags.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+var (
+	unusedFlags        = flag.NewFlagSet("unused", flag.ExitOnError)
+	unusedIncludeTests = unusedFlags.Bool("include_tests", true,
+		"Whether XTest packages should be loaded. References from tests count as uses.")
+	unusedAll = unusedFlags.Bool("all", false,
+		"Report unused identifiers in all loaded packages, including dependencies outside of the requested load paths.")
+
+	unusedCmd = &command{
+		help:  "list exported identifiers that no other package references",
+		flags: unusedFlags,
+		run:   runUnused,
+	}
+)
+
+func runUnused(args []string) error {
+	if len(args) == 0 {
+		return errNoPackages
+	}
+	pg, err := loadGraph(args, *unusedIncludeTests, false)
+	if err != nil {
+		return err
+	}
+	for _, d := range pg.UnusedDecls() {
+		if !*unusedAll && !hasAnyPrefix(d.Package.Path, args) {
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s: %s\n", d.Position, d)
+	}
+	return nil
+}
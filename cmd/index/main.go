@@ -1,24 +1,72 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/korfuri/goref"
 	"github.com/korfuri/goref/elasticsearch"
+	"github.com/korfuri/goref/export"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/bolt"
+	"github.com/korfuri/goref/store/sqlite"
 	log "github.com/sirupsen/logrus"
 	elastic "gopkg.in/olivere/elastic.v5"
 )
 
 const (
 	// Usage help line
-	Usage = `index -include_tests <true|false> \\
+	Usage = `index -include_tests <true|false> -callgraph <none|cha|rta|vta> \\
   -elastic_url http://localhost:9200/ -elastic_user elastic -elastic_password changeme \\
-  github.com/korfuri/goref github.com/korfuri/goref/elastic/main`
+  github.com/korfuri/goref github.com/korfuri/goref/elastic/main
+
+index -init -elastic_url http://localhost:9200/ -elastic_index goref
+
+index -gc -gc_keep 3 -gc_newer_than 168h -dry_run -elastic_url http://localhost:9200/
+
+index -reindex -elastic_index goref github.com/korfuri/goref
+index -rollback -elastic_index goref
+
+index -store sqlite:goref.db github.com/korfuri/goref
+index -store bolt:goref.bolt github.com/korfuri/goref
+
+goref export github.com/korfuri/goref | index -import -`
 )
 
 var (
+	storeSpec = flag.String("store", "elastic",
+		"Where to store references: elastic, for the ElasticSearch cluster configured by the elastic_* flags, "+
+			"sqlite:<path> for a SQLite database or bolt:<path> for a bbolt database. Databases are created if they "+
+			"don't exist.")
+	initIndex = flag.Bool("init", false,
+		"Create the index with goref's mapping, or check the mapping of an existing index, and exit.")
+	gc = flag.Bool("gc", false,
+		"Delete old versions of packages from the index, as selected by -gc_keep and -gc_newer_than, and exit.")
+	gcKeep = flag.Int("gc_keep", 1,
+		"Number of most recent versions of each package kept by -gc.")
+	gcNewerThan = flag.Duration("gc_newer_than", 0,
+		"Versions more recent than this are kept by -gc, even if there are more than -gc_keep of them. "+
+			"Versions are compared as file modification times. Zero disables this.")
+	dryRun = flag.Bool("dry_run", false,
+		"With -gc, list the package versions that would be deleted without deleting them.")
+	reindex = flag.Bool("reindex", false,
+		"Index into a fresh timestamped index, named <elastic_index>-<timestamp>, then atomically point "+
+			"the alias <elastic_index> to it. Readers of the alias never see a partial index.")
+	rollback = flag.Bool("rollback", false,
+		"Point the alias <elastic_index> back to the index that was built before the current one, and exit.")
+	importFile = flag.String("import", "",
+		"Instead of loading packages, store the packages of a file written by goref export -format=jsonl, "+
+			"or - to read it from stdin. Packages are stored as they're read, with the symbols of their declarations. "+
+			"Exports written by versions of goref that didn't write declarations have no symbols.")
 	includeTests = flag.Bool("include_tests", true,
 		"Whether XTest packages should be included in the index.")
+	callGraph = flag.String("callgraph", "none",
+		"Algorithm used to resolve dynamic calls: none, cha, rta or vta.")
 	elasticURL = flag.String("elastic_url", "http://localhost:9200",
 		"URL of the ElasticSearch cluster.")
 	elasticUsername = flag.String("elastic_user", "elastic",
@@ -27,28 +75,175 @@ var (
 		"Password to authenticate with ElasticSearch.")
 	elasticIndex = flag.String("elastic_index", "goref",
 		"Name of the index to use in ElasticSearch.")
+	elasticVersion = flag.Int("elastic_version", 5,
+		"Major version of the ElasticSearch cluster. With 5, documents are stored in a single index with mapping types. "+
+			"With 7 or later, each type of document is stored in its own index, named <elastic_index>-<type>.")
+	bulkSize = flag.Int("bulk_size", elasticsearch.DefaultBulkConfig.BatchSize,
+		"Number of documents sent to ElasticSearch in each bulk request.")
+	bulkFlushInterval = flag.Duration("bulk_flush_interval", elasticsearch.DefaultBulkConfig.FlushInterval,
+		"Maximum time documents wait before being sent to ElasticSearch.")
+	bulkWorkers = flag.Int("bulk_workers", elasticsearch.DefaultBulkConfig.Workers,
+		"Number of concurrent bulk requests to ElasticSearch.")
 )
 
 func usage() {
 	log.Fatal(Usage)
 }
 
+// newClient creates a client for the provided index, for the
+// configured version of ElasticSearch.
+func newClient(index string) elasticsearch.Client {
+	switch {
+	case *elasticVersion == 5:
+		eClient, err := elastic.NewClient(
+			elastic.SetURL(*elasticURL),
+			elastic.SetBasicAuth(*elasticUsername, *elasticPassword))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return elasticsearch.NewClient(eClient, index)
+	case *elasticVersion >= 7:
+		return elasticsearch.NewTypelessClient(*elasticURL, *elasticUsername, *elasticPassword, index)
+	}
+	log.Fatalf("Unsupported ElasticSearch version %d", *elasticVersion)
+	return nil
+}
+
+// openElastic runs the actions of -rollback, -init and -gc, which
+// exit with a nil Store, or returns a Store for the configured
+// index. With -reindex, finish must be called once everything is
+// indexed to point the alias to the new index.
+func openElastic() (store.Store, func()) {
+	client := newClient(*elasticIndex)
+	if *rollback {
+		target, err := elasticsearch.RollbackAlias(context.Background(), client, *elasticIndex)
+		if err != nil {
+			log.Fatalf("Couldn't roll back %s: %s", *elasticIndex, err)
+		}
+		log.Infof("Alias %s now points to %s.", *elasticIndex, target)
+		return nil, nil
+	}
+
+	// With -reindex, everything is indexed into a fresh index and
+	// the alias is only swapped once it's complete.
+	aliasClient := client
+	indexName := *elasticIndex
+	if *reindex {
+		indexName = elasticsearch.TimestampedIndex(*elasticIndex, time.Now())
+		client = newClient(indexName)
+		log.Infof("Building index %s for alias %s.", indexName, *elasticIndex)
+	}
+
+	// Create the index, or make sure that its mapping is one we
+	// can use.
+	if err := elasticsearch.EnsureIndex(context.Background(), client); err != nil {
+		log.Fatalf("Index %s can't be used: %s", indexName, err)
+	}
+	if *initIndex {
+		log.Infof("Index %s is ready.", indexName)
+		return nil, nil
+	}
+	if *gc {
+		policy := elasticsearch.GCPolicy{KeepLatest: *gcKeep}
+		if *gcNewerThan != 0 {
+			// Versions are computed with FileMTimeVersion.
+			policy.KeepNewerThan = time.Now().Add(-*gcNewerThan).UnixNano()
+		}
+		removed, err := elasticsearch.GarbageCollect(context.Background(), client, policy, *dryRun)
+		for _, p := range removed {
+			if *dryRun {
+				fmt.Printf("Would delete %s@%d\n", p.Path, p.Version)
+			} else {
+				fmt.Printf("Deleted %s@%d\n", p.Path, p.Version)
+			}
+		}
+		if err != nil {
+			log.Fatalf("Garbage collection failed: %s", err)
+		}
+		return nil, nil
+	}
+
+	config := elasticsearch.BulkConfig{
+		BatchSize:     *bulkSize,
+		FlushInterval: *bulkFlushInterval,
+		Workers:       *bulkWorkers,
+	}
+	finish := func() {
+		if !*reindex {
+			return
+		}
+		previous, err := elasticsearch.SwapAlias(context.Background(), aliasClient, *elasticIndex, indexName)
+		if err != nil {
+			log.Fatalf("Couldn't point alias %s to %s: %s", *elasticIndex, indexName, err)
+		}
+		log.Infof("Alias %s now points to %s instead of %q.", *elasticIndex, indexName, previous)
+	}
+	return elasticsearch.NewStore(client, config), finish
+}
+
+// importJSONL stores the packages of a JSONL export that the Store
+// doesn't have yet, one package at a time.
+func importJSONL(st store.Store, path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	ctx := context.Background()
+	return export.ReadJSONL(r, func(p *goref.Package) error {
+		return store.PutNewPackages(ctx, st, []*goref.Package{p})
+	})
+}
+
 func main() {
 	flag.Parse()
 	args := flag.Args()
 
-	if len(args) == 0 {
+	if len(args) == 0 && *importFile == "" && !*initIndex && !*gc && !*rollback {
 		usage()
 	}
 
-	// Create a client
-	eClient, err := elastic.NewClient(
-		elastic.SetURL(*elasticURL),
-		elastic.SetBasicAuth(*elasticUsername, *elasticPassword))
-	if err != nil {
-		log.Fatal(err)
+	var st store.Store
+	finish := func() {}
+	switch {
+	case *storeSpec == "elastic":
+		if st, finish = openElastic(); st == nil {
+			return
+		}
+	case strings.HasPrefix(*storeSpec, "sqlite:"), strings.HasPrefix(*storeSpec, "bolt:"):
+		if *gc || *reindex || *rollback {
+			log.Fatal("-gc, -reindex and -rollback are only supported with -store=elastic")
+		}
+		open := sqlite.Open
+		if strings.HasPrefix(*storeSpec, "bolt:") {
+			open = bolt.Open
+		}
+		path := (*storeSpec)[strings.Index(*storeSpec, ":")+1:]
+		var err error
+		if st, err = open(path); err != nil {
+			log.Fatalf("Couldn't open %s: %s", path, err)
+		}
+		if *initIndex {
+			log.Infof("Database %s is ready.", path)
+			return
+		}
+	default:
+		log.Fatalf("Unsupported store %q", *storeSpec)
+	}
+	defer st.Close()
+
+	if *importFile != "" {
+		if err := importJSONL(st, *importFile); err != nil {
+			log.Fatalf("Couldn't import %s: %s", *importFile, err)
+		}
+		finish()
+		log.Info("Done, bye.")
+		return
 	}
-	client := elasticsearch.NewClient(eClient, *elasticIndex)
 
 	packages := args
 
@@ -58,18 +253,26 @@ func main() {
 		log.Info("This index will include XTests.")
 	}
 	pg := goref.NewPackageGraph(goref.FileMTimeVersion)
+	algo, err := goref.ParseCallGraphAlgorithm(*callGraph)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pg.SetCallGraph(algo)
 	// Set FilterF to skip any packages that exist in our index
-	pg.SetFilterF(elasticsearch.FilterF(client))
-	pg.LoadPackages(packages, *includeTests)
+	pg.SetFilterF(store.FilterF(st))
+	if err := pg.LoadPackages(packages, *includeTests); err != nil {
+		log.Fatalf("Couldn't load packages: %s", err)
+	}
 	log.Info("Computing the interface-implementation matrix.")
 	pg.ComputeInterfaceImplementationMatrix()
 
 	log.Infof("%d packages in the graph.", len(pg.Packages))
 
-	// Load the indexed references into ElasticSearch
-	log.Info("Inserting references into ElasticSearch.")
-	if err := elasticsearch.LoadGraphToElastic(*pg, client); err != nil {
+	// Load the indexed references into the store
+	log.Info("Storing references.")
+	if err := store.LoadGraph(context.Background(), st, *pg); err != nil {
 		log.Fatalf("Couldn't load some references. Error: %s", err)
 	}
+	finish()
 	log.Info("Done, bye.")
 }
@@ -16,6 +16,19 @@ It has these top-level messages:
 	GetFilesResponse
 	GetPackagesRequest
 	GetPackagesResponse
+	FindReferencesRequest
+	FileReferences
+	FindReferencesResponse
+	GetDefinitionRequest
+	GetDefinitionResponse
+	TypeLocation
+	GetImplementationsRequest
+	GetImplementationsResponse
+	GetInterfacesRequest
+	GetInterfacesResponse
+	SearchSymbolsRequest
+	Symbol
+	SearchSymbolsResponse
 */
 package serve
 
@@ -82,7 +95,9 @@ func (m *GetFileResponse) GetContents() string {
 }
 
 type GetAnnotationsRequest struct {
-	Path string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Path      string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	PageSize  int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
 }
 
 func (m *GetAnnotationsRequest) Reset()                    { *m = GetAnnotationsRequest{} }
@@ -97,9 +112,24 @@ func (m *GetAnnotationsRequest) GetPath() string {
 	return ""
 }
 
+func (m *GetAnnotationsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetAnnotationsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetAnnotationsResponse struct {
-	Path       string       `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
-	Annotation []*goref.Ref `protobuf:"bytes,2,rep,name=annotation" json:"annotation,omitempty"`
+	Path          string       `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Annotation    []*goref.Ref `protobuf:"bytes,2,rep,name=annotation" json:"annotation,omitempty"`
+	NextPageToken string       `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *GetAnnotationsResponse) Reset()                    { *m = GetAnnotationsResponse{} }
@@ -121,8 +151,17 @@ func (m *GetAnnotationsResponse) GetAnnotation() []*goref.Ref {
 	return nil
 }
 
+func (m *GetAnnotationsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type GetFilesRequest struct {
-	Package string `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	Package   string `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	PageSize  int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
 }
 
 func (m *GetFilesRequest) Reset()                    { *m = GetFilesRequest{} }
@@ -137,9 +176,24 @@ func (m *GetFilesRequest) GetPackage() string {
 	return ""
 }
 
+func (m *GetFilesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetFilesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetFilesResponse struct {
-	Package  string   `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
-	Filename []string `protobuf:"bytes,2,rep,name=filename" json:"filename,omitempty"`
+	Package       string   `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	Filename      []string `protobuf:"bytes,2,rep,name=filename" json:"filename,omitempty"`
+	NextPageToken string   `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *GetFilesResponse) Reset()                    { *m = GetFilesResponse{} }
@@ -161,9 +215,18 @@ func (m *GetFilesResponse) GetFilename() []string {
 	return nil
 }
 
+func (m *GetFilesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type GetPackagesRequest struct {
 	Prefix        string `protobuf:"bytes,1,opt,name=prefix" json:"prefix,omitempty"`
 	IncludeVendor bool   `protobuf:"varint,2,opt,name=includeVendor" json:"includeVendor,omitempty"`
+	PageSize      int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
 }
 
 func (m *GetPackagesRequest) Reset()                    { *m = GetPackagesRequest{} }
@@ -185,8 +248,23 @@ func (m *GetPackagesRequest) GetIncludeVendor() bool {
 	return false
 }
 
+func (m *GetPackagesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetPackagesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetPackagesResponse struct {
-	Package []string `protobuf:"bytes,1,rep,name=package" json:"package,omitempty"`
+	Package       []string `protobuf:"bytes,1,rep,name=package" json:"package,omitempty"`
+	NextPageToken string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *GetPackagesResponse) Reset()                    { *m = GetPackagesResponse{} }
@@ -201,6 +279,401 @@ func (m *GetPackagesResponse) GetPackage() []string {
 	return nil
 }
 
+func (m *GetPackagesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+// FindReferences returns the references to an identifier of a
+// package, or to any identifier of the package if ident is empty,
+// grouped by the file they're made from. Only references of the
+// provided types are returned, if any. exclude_tests leaves out
+// references made from _test.go files.
+type FindReferencesRequest struct {
+	Package      string       `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	Ident        string       `protobuf:"bytes,2,opt,name=ident" json:"ident,omitempty"`
+	RefTypes     []goref.Type `protobuf:"varint,3,rep,packed,name=ref_types,json=refTypes,enum=goref.Type" json:"ref_types,omitempty"`
+	ExcludeTests bool         `protobuf:"varint,4,opt,name=exclude_tests,json=excludeTests" json:"exclude_tests,omitempty"`
+}
+
+func (m *FindReferencesRequest) Reset()                    { *m = FindReferencesRequest{} }
+func (m *FindReferencesRequest) String() string            { return proto.CompactTextString(m) }
+func (*FindReferencesRequest) ProtoMessage()               {}
+func (*FindReferencesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *FindReferencesRequest) GetPackage() string {
+	if m != nil {
+		return m.Package
+	}
+	return ""
+}
+
+func (m *FindReferencesRequest) GetIdent() string {
+	if m != nil {
+		return m.Ident
+	}
+	return ""
+}
+
+func (m *FindReferencesRequest) GetRefTypes() []goref.Type {
+	if m != nil {
+		return m.RefTypes
+	}
+	return nil
+}
+
+func (m *FindReferencesRequest) GetExcludeTests() bool {
+	if m != nil {
+		return m.ExcludeTests
+	}
+	return false
+}
+
+type FileReferences struct {
+	Filename string       `protobuf:"bytes,1,opt,name=filename" json:"filename,omitempty"`
+	Ref      []*goref.Ref `protobuf:"bytes,2,rep,name=ref" json:"ref,omitempty"`
+}
+
+func (m *FileReferences) Reset()                    { *m = FileReferences{} }
+func (m *FileReferences) String() string            { return proto.CompactTextString(m) }
+func (*FileReferences) ProtoMessage()               {}
+func (*FileReferences) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *FileReferences) GetFilename() string {
+	if m != nil {
+		return m.Filename
+	}
+	return ""
+}
+
+func (m *FileReferences) GetRef() []*goref.Ref {
+	if m != nil {
+		return m.Ref
+	}
+	return nil
+}
+
+type FindReferencesResponse struct {
+	Package string            `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	Ident   string            `protobuf:"bytes,2,opt,name=ident" json:"ident,omitempty"`
+	File    []*FileReferences `protobuf:"bytes,3,rep,name=file" json:"file,omitempty"`
+}
+
+func (m *FindReferencesResponse) Reset()                    { *m = FindReferencesResponse{} }
+func (m *FindReferencesResponse) String() string            { return proto.CompactTextString(m) }
+func (*FindReferencesResponse) ProtoMessage()               {}
+func (*FindReferencesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *FindReferencesResponse) GetPackage() string {
+	if m != nil {
+		return m.Package
+	}
+	return ""
+}
+
+func (m *FindReferencesResponse) GetIdent() string {
+	if m != nil {
+		return m.Ident
+	}
+	return ""
+}
+
+func (m *FindReferencesResponse) GetFile() []*FileReferences {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+// GetDefinition returns the location of the declaration that the
+// identifier at line:col of a file refers to. definition is unset if
+// no identifier is known at that position.
+type GetDefinitionRequest struct {
+	Path string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Line int32  `protobuf:"varint,2,opt,name=line" json:"line,omitempty"`
+	Col  int32  `protobuf:"varint,3,opt,name=col" json:"col,omitempty"`
+}
+
+func (m *GetDefinitionRequest) Reset()                    { *m = GetDefinitionRequest{} }
+func (m *GetDefinitionRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetDefinitionRequest) ProtoMessage()               {}
+func (*GetDefinitionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *GetDefinitionRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *GetDefinitionRequest) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *GetDefinitionRequest) GetCol() int32 {
+	if m != nil {
+		return m.Col
+	}
+	return 0
+}
+
+type GetDefinitionResponse struct {
+	Definition *goref.Location `protobuf:"bytes,1,opt,name=definition" json:"definition,omitempty"`
+}
+
+func (m *GetDefinitionResponse) Reset()                    { *m = GetDefinitionResponse{} }
+func (m *GetDefinitionResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetDefinitionResponse) ProtoMessage()               {}
+func (*GetDefinitionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *GetDefinitionResponse) GetDefinition() *goref.Location {
+	if m != nil {
+		return m.Definition
+	}
+	return nil
+}
+
+// A TypeLocation is a type or an interface related to the requested
+// one. Its type is Extension if both are interfaces and one extends
+// the other, and Implementation otherwise.
+type TypeLocation struct {
+	Location *goref.Location `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
+	Type     goref.Type      `protobuf:"varint,2,opt,name=type,enum=goref.Type" json:"type,omitempty"`
+}
+
+func (m *TypeLocation) Reset()                    { *m = TypeLocation{} }
+func (m *TypeLocation) String() string            { return proto.CompactTextString(m) }
+func (*TypeLocation) ProtoMessage()               {}
+func (*TypeLocation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *TypeLocation) GetLocation() *goref.Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *TypeLocation) GetType() goref.Type {
+	if m != nil {
+		return m.Type
+	}
+	return goref.Type_Instantiation
+}
+
+// GetImplementations returns the types that implement an interface
+// and the interfaces that extend it. With transitive, the interfaces
+// that extend those are followed through their Extension references,
+// along with their implementations.
+type GetImplementationsRequest struct {
+	Package    string `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	Iface      string `protobuf:"bytes,2,opt,name=iface" json:"iface,omitempty"`
+	Transitive bool   `protobuf:"varint,3,opt,name=transitive" json:"transitive,omitempty"`
+}
+
+func (m *GetImplementationsRequest) Reset()                    { *m = GetImplementationsRequest{} }
+func (m *GetImplementationsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetImplementationsRequest) ProtoMessage()               {}
+func (*GetImplementationsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *GetImplementationsRequest) GetPackage() string {
+	if m != nil {
+		return m.Package
+	}
+	return ""
+}
+
+func (m *GetImplementationsRequest) GetIface() string {
+	if m != nil {
+		return m.Iface
+	}
+	return ""
+}
+
+func (m *GetImplementationsRequest) GetTransitive() bool {
+	if m != nil {
+		return m.Transitive
+	}
+	return false
+}
+
+type GetImplementationsResponse struct {
+	Implementation []*TypeLocation `protobuf:"bytes,1,rep,name=implementation" json:"implementation,omitempty"`
+}
+
+func (m *GetImplementationsResponse) Reset()                    { *m = GetImplementationsResponse{} }
+func (m *GetImplementationsResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetImplementationsResponse) ProtoMessage()               {}
+func (*GetImplementationsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *GetImplementationsResponse) GetImplementation() []*TypeLocation {
+	if m != nil {
+		return m.Implementation
+	}
+	return nil
+}
+
+// GetInterfaces returns the interfaces that a type implements, or
+// that an interface extends. With transitive, the interfaces that
+// those extend are followed through their Extension references.
+type GetInterfacesRequest struct {
+	Package    string `protobuf:"bytes,1,opt,name=package" json:"package,omitempty"`
+	Type       string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Transitive bool   `protobuf:"varint,3,opt,name=transitive" json:"transitive,omitempty"`
+}
+
+func (m *GetInterfacesRequest) Reset()                    { *m = GetInterfacesRequest{} }
+func (m *GetInterfacesRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetInterfacesRequest) ProtoMessage()               {}
+func (*GetInterfacesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *GetInterfacesRequest) GetPackage() string {
+	if m != nil {
+		return m.Package
+	}
+	return ""
+}
+
+func (m *GetInterfacesRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *GetInterfacesRequest) GetTransitive() bool {
+	if m != nil {
+		return m.Transitive
+	}
+	return false
+}
+
+type GetInterfacesResponse struct {
+	Interface []*TypeLocation `protobuf:"bytes,1,rep,name=interface" json:"interface,omitempty"`
+}
+
+func (m *GetInterfacesResponse) Reset()                    { *m = GetInterfacesResponse{} }
+func (m *GetInterfacesResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetInterfacesResponse) ProtoMessage()               {}
+func (*GetInterfacesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *GetInterfacesResponse) GetInterface() []*TypeLocation {
+	if m != nil {
+		return m.Interface
+	}
+	return nil
+}
+
+// SearchSymbols returns the identifiers declared in packages whose
+// load path starts with package_prefix that match query, by prefix,
+// by substring, by the words of their camelCase name (e.g. "NPG" for
+// NewPackageGraph) or with typos. Only declarations of the provided
+// kinds (e.g. "func", "method") are returned, if any. Symbols are
+// ranked by how well they match, then by their number of inbound
+// references.
+type SearchSymbolsRequest struct {
+	Query         string   `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
+	Kinds         []string `protobuf:"bytes,2,rep,name=kinds" json:"kinds,omitempty"`
+	PackagePrefix string   `protobuf:"bytes,3,opt,name=package_prefix,json=packagePrefix" json:"package_prefix,omitempty"`
+	Limit         int32    `protobuf:"varint,4,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *SearchSymbolsRequest) Reset()                    { *m = SearchSymbolsRequest{} }
+func (m *SearchSymbolsRequest) String() string            { return proto.CompactTextString(m) }
+func (*SearchSymbolsRequest) ProtoMessage()               {}
+func (*SearchSymbolsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *SearchSymbolsRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchSymbolsRequest) GetKinds() []string {
+	if m != nil {
+		return m.Kinds
+	}
+	return nil
+}
+
+func (m *SearchSymbolsRequest) GetPackagePrefix() string {
+	if m != nil {
+		return m.PackagePrefix
+	}
+	return ""
+}
+
+func (m *SearchSymbolsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// A Symbol is a declared identifier that matched a query. match is
+// how it matched: "exact", "prefix", "camelcase", "substring" or
+// "fuzzy".
+type Symbol struct {
+	Location *goref.Location `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
+	Kind     string          `protobuf:"bytes,2,opt,name=kind" json:"kind,omitempty"`
+	RefCount int64           `protobuf:"varint,3,opt,name=ref_count,json=refCount" json:"ref_count,omitempty"`
+	Match    string          `protobuf:"bytes,4,opt,name=match" json:"match,omitempty"`
+}
+
+func (m *Symbol) Reset()                    { *m = Symbol{} }
+func (m *Symbol) String() string            { return proto.CompactTextString(m) }
+func (*Symbol) ProtoMessage()               {}
+func (*Symbol) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *Symbol) GetLocation() *goref.Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *Symbol) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Symbol) GetRefCount() int64 {
+	if m != nil {
+		return m.RefCount
+	}
+	return 0
+}
+
+func (m *Symbol) GetMatch() string {
+	if m != nil {
+		return m.Match
+	}
+	return ""
+}
+
+type SearchSymbolsResponse struct {
+	Symbol []*Symbol `protobuf:"bytes,1,rep,name=symbol" json:"symbol,omitempty"`
+}
+
+func (m *SearchSymbolsResponse) Reset()                    { *m = SearchSymbolsResponse{} }
+func (m *SearchSymbolsResponse) String() string            { return proto.CompactTextString(m) }
+func (*SearchSymbolsResponse) ProtoMessage()               {}
+func (*SearchSymbolsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *SearchSymbolsResponse) GetSymbol() []*Symbol {
+	if m != nil {
+		return m.Symbol
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*GetFileRequest)(nil), "serve.GetFileRequest")
 	proto.RegisterType((*GetFileResponse)(nil), "serve.GetFileResponse")
@@ -210,6 +683,19 @@ func init() {
 	proto.RegisterType((*GetFilesResponse)(nil), "serve.GetFilesResponse")
 	proto.RegisterType((*GetPackagesRequest)(nil), "serve.GetPackagesRequest")
 	proto.RegisterType((*GetPackagesResponse)(nil), "serve.GetPackagesResponse")
+	proto.RegisterType((*FindReferencesRequest)(nil), "serve.FindReferencesRequest")
+	proto.RegisterType((*FileReferences)(nil), "serve.FileReferences")
+	proto.RegisterType((*FindReferencesResponse)(nil), "serve.FindReferencesResponse")
+	proto.RegisterType((*GetDefinitionRequest)(nil), "serve.GetDefinitionRequest")
+	proto.RegisterType((*GetDefinitionResponse)(nil), "serve.GetDefinitionResponse")
+	proto.RegisterType((*TypeLocation)(nil), "serve.TypeLocation")
+	proto.RegisterType((*GetImplementationsRequest)(nil), "serve.GetImplementationsRequest")
+	proto.RegisterType((*GetImplementationsResponse)(nil), "serve.GetImplementationsResponse")
+	proto.RegisterType((*GetInterfacesRequest)(nil), "serve.GetInterfacesRequest")
+	proto.RegisterType((*GetInterfacesResponse)(nil), "serve.GetInterfacesResponse")
+	proto.RegisterType((*SearchSymbolsRequest)(nil), "serve.SearchSymbolsRequest")
+	proto.RegisterType((*Symbol)(nil), "serve.Symbol")
+	proto.RegisterType((*SearchSymbolsResponse)(nil), "serve.SearchSymbolsResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -227,6 +713,11 @@ type GorefClient interface {
 	GetAnnotations(ctx context.Context, in *GetAnnotationsRequest, opts ...grpc.CallOption) (*GetAnnotationsResponse, error)
 	GetFiles(ctx context.Context, in *GetFilesRequest, opts ...grpc.CallOption) (*GetFilesResponse, error)
 	GetPackages(ctx context.Context, in *GetPackagesRequest, opts ...grpc.CallOption) (*GetPackagesResponse, error)
+	FindReferences(ctx context.Context, in *FindReferencesRequest, opts ...grpc.CallOption) (*FindReferencesResponse, error)
+	GetDefinition(ctx context.Context, in *GetDefinitionRequest, opts ...grpc.CallOption) (*GetDefinitionResponse, error)
+	GetImplementations(ctx context.Context, in *GetImplementationsRequest, opts ...grpc.CallOption) (*GetImplementationsResponse, error)
+	GetInterfaces(ctx context.Context, in *GetInterfacesRequest, opts ...grpc.CallOption) (*GetInterfacesResponse, error)
+	SearchSymbols(ctx context.Context, in *SearchSymbolsRequest, opts ...grpc.CallOption) (*SearchSymbolsResponse, error)
 }
 
 type gorefClient struct {
@@ -273,6 +764,51 @@ func (c *gorefClient) GetPackages(ctx context.Context, in *GetPackagesRequest, o
 	return out, nil
 }
 
+func (c *gorefClient) FindReferences(ctx context.Context, in *FindReferencesRequest, opts ...grpc.CallOption) (*FindReferencesResponse, error) {
+	out := new(FindReferencesResponse)
+	err := grpc.Invoke(ctx, "/serve.Goref/FindReferences", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gorefClient) GetDefinition(ctx context.Context, in *GetDefinitionRequest, opts ...grpc.CallOption) (*GetDefinitionResponse, error) {
+	out := new(GetDefinitionResponse)
+	err := grpc.Invoke(ctx, "/serve.Goref/GetDefinition", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gorefClient) GetImplementations(ctx context.Context, in *GetImplementationsRequest, opts ...grpc.CallOption) (*GetImplementationsResponse, error) {
+	out := new(GetImplementationsResponse)
+	err := grpc.Invoke(ctx, "/serve.Goref/GetImplementations", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gorefClient) GetInterfaces(ctx context.Context, in *GetInterfacesRequest, opts ...grpc.CallOption) (*GetInterfacesResponse, error) {
+	out := new(GetInterfacesResponse)
+	err := grpc.Invoke(ctx, "/serve.Goref/GetInterfaces", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gorefClient) SearchSymbols(ctx context.Context, in *SearchSymbolsRequest, opts ...grpc.CallOption) (*SearchSymbolsResponse, error) {
+	out := new(SearchSymbolsResponse)
+	err := grpc.Invoke(ctx, "/serve.Goref/SearchSymbols", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Goref service
 
 type GorefServer interface {
@@ -280,6 +816,11 @@ type GorefServer interface {
 	GetAnnotations(context.Context, *GetAnnotationsRequest) (*GetAnnotationsResponse, error)
 	GetFiles(context.Context, *GetFilesRequest) (*GetFilesResponse, error)
 	GetPackages(context.Context, *GetPackagesRequest) (*GetPackagesResponse, error)
+	FindReferences(context.Context, *FindReferencesRequest) (*FindReferencesResponse, error)
+	GetDefinition(context.Context, *GetDefinitionRequest) (*GetDefinitionResponse, error)
+	GetImplementations(context.Context, *GetImplementationsRequest) (*GetImplementationsResponse, error)
+	GetInterfaces(context.Context, *GetInterfacesRequest) (*GetInterfacesResponse, error)
+	SearchSymbols(context.Context, *SearchSymbolsRequest) (*SearchSymbolsResponse, error)
 }
 
 func RegisterGorefServer(s *grpc.Server, srv GorefServer) {
@@ -358,6 +899,96 @@ func _Goref_GetPackages_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Goref_FindReferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindReferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GorefServer).FindReferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serve.Goref/FindReferences",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GorefServer).FindReferences(ctx, req.(*FindReferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Goref_GetDefinition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDefinitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GorefServer).GetDefinition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serve.Goref/GetDefinition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GorefServer).GetDefinition(ctx, req.(*GetDefinitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Goref_GetImplementations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetImplementationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GorefServer).GetImplementations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serve.Goref/GetImplementations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GorefServer).GetImplementations(ctx, req.(*GetImplementationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Goref_GetInterfaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInterfacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GorefServer).GetInterfaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serve.Goref/GetInterfaces",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GorefServer).GetInterfaces(ctx, req.(*GetInterfacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Goref_SearchSymbols_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSymbolsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GorefServer).SearchSymbols(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serve.Goref/SearchSymbols",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GorefServer).SearchSymbols(ctx, req.(*SearchSymbolsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Goref_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "serve.Goref",
 	HandlerType: (*GorefServer)(nil),
@@ -378,6 +1009,26 @@ var _Goref_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPackages",
 			Handler:    _Goref_GetPackages_Handler,
 		},
+		{
+			MethodName: "FindReferences",
+			Handler:    _Goref_FindReferences_Handler,
+		},
+		{
+			MethodName: "GetDefinition",
+			Handler:    _Goref_GetDefinition_Handler,
+		},
+		{
+			MethodName: "GetImplementations",
+			Handler:    _Goref_GetImplementations_Handler,
+		},
+		{
+			MethodName: "GetInterfaces",
+			Handler:    _Goref_GetInterfaces_Handler,
+		},
+		{
+			MethodName: "SearchSymbols",
+			Handler:    _Goref_SearchSymbols_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "serve.proto",
@@ -386,34 +1037,74 @@ var _Goref_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("serve.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 451 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x93, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc7, 0xd5, 0x84, 0xb6, 0xe9, 0x44, 0x7c, 0x68, 0xa0, 0xc1, 0xac, 0x02, 0x84, 0x55, 0x24,
-	0xa2, 0x54, 0xf2, 0x8a, 0x72, 0xe6, 0xd0, 0x0b, 0xe1, 0x88, 0x7c, 0x40, 0x88, 0x13, 0xdb, 0x74,
-	0xec, 0xae, 0x9a, 0xee, 0x9a, 0xf5, 0x26, 0x42, 0x42, 0x5c, 0x78, 0x05, 0x5e, 0x80, 0x77, 0xe2,
-	0x15, 0x78, 0x10, 0x94, 0xf5, 0xd6, 0x76, 0xdc, 0x34, 0x37, 0xcf, 0xce, 0x5f, 0xf3, 0xfb, 0xcf,
-	0x87, 0xa1, 0x5f, 0x90, 0x5d, 0x51, 0x9c, 0x5b, 0xe3, 0x0c, 0xee, 0xfb, 0x80, 0x0d, 0x33, 0x63,
-	0xb2, 0x05, 0x09, 0x99, 0x2b, 0x21, 0xb5, 0x36, 0x4e, 0x3a, 0x65, 0x74, 0x51, 0x8a, 0xd8, 0x24,
-	0x53, 0xee, 0x72, 0x79, 0x1e, 0xcf, 0xcd, 0xb5, 0xb8, 0x32, 0x36, 0x5d, 0x5a, 0x25, 0x32, 0x63,
-	0x29, 0x15, 0x3e, 0x2f, 0x2c, 0xa5, 0xa5, 0x92, 0x8f, 0xe1, 0xc1, 0x8c, 0xdc, 0x7b, 0xb5, 0xa0,
-	0x84, 0xbe, 0x2d, 0xa9, 0x70, 0x88, 0x70, 0x2f, 0x97, 0xee, 0x32, 0xda, 0x1b, 0xed, 0x4d, 0x8e,
-	0x12, 0xff, 0xcd, 0xcf, 0xe0, 0x61, 0xa5, 0x2a, 0x72, 0xa3, 0x0b, 0xda, 0x26, 0x43, 0x06, 0xbd,
-	0xb9, 0xd1, 0x8e, 0xb4, 0x2b, 0xa2, 0x8e, 0x7f, 0xaf, 0x62, 0x7e, 0x02, 0xc7, 0x33, 0x72, 0x67,
-	0xb5, 0xd5, 0x5d, 0xbc, 0xcf, 0x30, 0x68, 0x8b, 0x77, 0x60, 0xa7, 0x00, 0xf5, 0x08, 0xa2, 0xce,
-	0xa8, 0x3b, 0xe9, 0x9f, 0x42, 0xec, 0xfb, 0x8d, 0x13, 0x4a, 0x93, 0x46, 0x96, 0x9f, 0x54, 0x9d,
-	0x54, 0x06, 0x22, 0x38, 0xcc, 0xe5, 0xfc, 0x4a, 0x66, 0x14, 0xaa, 0xde, 0x84, 0xfc, 0x03, 0x3c,
-	0xaa, 0xc5, 0xc1, 0xc0, 0x9d, 0xea, 0x75, 0xf7, 0xa9, 0x5a, 0x90, 0x96, 0xd7, 0xe4, 0x4d, 0x1c,
-	0x25, 0x55, 0xcc, 0x13, 0xc0, 0x19, 0xb9, 0x8f, 0xa5, 0xb2, 0x22, 0x0f, 0xe0, 0x20, 0xb7, 0x94,
-	0xaa, 0xef, 0xa1, 0x54, 0x88, 0x70, 0x0c, 0xf7, 0x95, 0x9e, 0x2f, 0x96, 0x17, 0xf4, 0x89, 0xf4,
-	0x85, 0xb1, 0x7e, 0x98, 0xbd, 0x64, 0xf3, 0x91, 0x0b, 0x78, 0xbc, 0x51, 0x73, 0x9b, 0xc1, 0x6e,
-	0xc3, 0xe0, 0xe9, 0x9f, 0x2e, 0xec, 0xcf, 0xd6, 0x53, 0xc1, 0x2f, 0x70, 0x18, 0x1a, 0xc3, 0xe3,
-	0xb8, 0xbc, 0xae, 0xcd, 0x2b, 0x60, 0x83, 0xf6, 0x73, 0x59, 0x9d, 0x8f, 0x7e, 0xfd, 0xfd, 0xf7,
-	0xbb, 0xc3, 0x30, 0x12, 0xab, 0x37, 0xe1, 0xa4, 0xd6, 0x4d, 0x8a, 0x1f, 0xeb, 0x4d, 0xbc, 0x9b,
-	0x4e, 0x7f, 0xe2, 0xca, 0x5f, 0x54, 0x63, 0x77, 0x38, 0xac, 0x6b, 0xdd, 0xde, 0x3f, 0x7b, 0x7e,
-	0x47, 0x36, 0x00, 0x5f, 0x7b, 0xe0, 0x2b, 0x7c, 0x59, 0x03, 0x1b, 0xf7, 0xde, 0xe0, 0x4a, 0xe8,
-	0xdd, 0x2c, 0x0b, 0x5b, 0xee, 0x2b, 0xd6, 0xd3, 0x5b, 0xef, 0x81, 0x32, 0xf6, 0x94, 0x17, 0x38,
-	0xdc, 0x6c, 0xcb, 0xd7, 0xf7, 0xc3, 0xf3, 0x88, 0xaf, 0xd0, 0x6f, 0x4c, 0x1c, 0x9f, 0xd5, 0xd5,
-	0x5a, 0x9b, 0x65, 0x6c, 0x5b, 0x2a, 0xb0, 0x98, 0x67, 0x3d, 0x41, 0xac, 0x59, 0x01, 0x52, 0x9c,
-	0x1f, 0xf8, 0xbf, 0xf2, 0xed, 0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0x80, 0xa0, 0x58, 0x99, 0xf3,
-	0x03, 0x00, 0x00,
+	// 1091 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0xdd, 0x6e, 0x1b, 0x45,
+	0x14, 0x96, 0x63, 0x3b, 0xb5, 0x8f, 0x6b, 0xa7, 0x4c, 0xec, 0xe0, 0x6c, 0x9c, 0xd4, 0xd9, 0xa6,
+	0xc1, 0x0d, 0x92, 0xad, 0x9a, 0x4b, 0x04, 0x52, 0x05, 0xaa, 0x69, 0xc5, 0x45, 0xb4, 0x89, 0x40,
+	0x20, 0x24, 0xb3, 0x59, 0x9f, 0x75, 0x46, 0x5e, 0xcf, 0x6e, 0x77, 0xc7, 0x51, 0x52, 0x40, 0x20,
+	0xee, 0xb8, 0xe1, 0x06, 0x89, 0x17, 0xe3, 0x15, 0x78, 0x08, 0x2e, 0xd1, 0xcc, 0x8e, 0xf7, 0xcf,
+	0x6b, 0x37, 0x88, 0xbb, 0x39, 0x3f, 0x9e, 0xef, 0x7c, 0xdf, 0xcc, 0x39, 0xb3, 0x86, 0x5a, 0x80,
+	0xfe, 0x0d, 0xf6, 0x3d, 0xdf, 0xe5, 0x2e, 0x29, 0x4b, 0x43, 0xeb, 0x4c, 0x5d, 0x77, 0xea, 0xe0,
+	0xc0, 0xf4, 0xe8, 0xc0, 0x64, 0xcc, 0xe5, 0x26, 0xa7, 0x2e, 0x0b, 0xc2, 0x24, 0xad, 0x37, 0xa5,
+	0xfc, 0x7a, 0x71, 0xd5, 0xb7, 0xdc, 0xf9, 0x60, 0xe6, 0xfa, 0xf6, 0xc2, 0xa7, 0x83, 0xa9, 0xeb,
+	0xa3, 0x3d, 0x90, 0xf1, 0x81, 0x8f, 0x76, 0x98, 0xa9, 0x9f, 0x40, 0x63, 0x84, 0xfc, 0x25, 0x75,
+	0xd0, 0xc0, 0x37, 0x0b, 0x0c, 0x38, 0x21, 0x50, 0xf2, 0x4c, 0x7e, 0xdd, 0x2e, 0x74, 0x0b, 0xbd,
+	0xaa, 0x21, 0xd7, 0xfa, 0x0b, 0xd8, 0x89, 0xb2, 0x02, 0xcf, 0x65, 0x01, 0xe6, 0xa5, 0x11, 0x0d,
+	0x2a, 0x96, 0xcb, 0x38, 0x32, 0x1e, 0xb4, 0xb7, 0xa4, 0x3f, 0xb2, 0xf5, 0x29, 0xb4, 0x46, 0xc8,
+	0x5f, 0xc4, 0xa5, 0x6e, 0xc0, 0x23, 0x07, 0x50, 0xf5, 0xcc, 0x29, 0x8e, 0x03, 0xfa, 0x16, 0xe5,
+	0x4e, 0x65, 0xa3, 0x22, 0x1c, 0x17, 0xf4, 0x2d, 0x92, 0x43, 0x00, 0x19, 0xe4, 0xee, 0x0c, 0x59,
+	0xbb, 0x28, 0x7f, 0x26, 0xd3, 0x2f, 0x85, 0x43, 0xff, 0xa5, 0x00, 0x7b, 0x59, 0xa4, 0x0d, 0x35,
+	0x9f, 0x01, 0xc4, 0xfa, 0xb5, 0xb7, 0xba, 0xc5, 0x5e, 0x6d, 0x08, 0x7d, 0x29, 0x56, 0xdf, 0x40,
+	0xdb, 0x48, 0x44, 0xc9, 0x29, 0xec, 0x30, 0xbc, 0xe5, 0xe3, 0x15, 0xf8, 0xba, 0x70, 0x9f, 0x47,
+	0x25, 0x4c, 0x23, 0xb9, 0x22, 0x96, 0x6d, 0x78, 0xe0, 0x99, 0xd6, 0xcc, 0x9c, 0xa2, 0x42, 0x5f,
+	0x9a, 0xff, 0x8b, 0xab, 0x07, 0x8f, 0x62, 0x20, 0x45, 0x72, 0x3d, 0x92, 0x06, 0x15, 0x9b, 0x3a,
+	0xc8, 0xcc, 0x39, 0x4a, 0xa2, 0x55, 0x23, 0xb2, 0xef, 0x4d, 0xed, 0xf7, 0x02, 0x90, 0x11, 0xf2,
+	0xf3, 0x70, 0xcb, 0x88, 0xde, 0x1e, 0x6c, 0x7b, 0x3e, 0xda, 0xf4, 0x56, 0x61, 0x2a, 0x8b, 0x9c,
+	0x40, 0x9d, 0x32, 0xcb, 0x59, 0x4c, 0xf0, 0x2b, 0x64, 0x13, 0xd7, 0x97, 0x04, 0x2b, 0x46, 0xda,
+	0x99, 0x96, 0xa0, 0xb8, 0x51, 0x82, 0x52, 0x56, 0x82, 0xaf, 0x61, 0x37, 0x55, 0x4f, 0x9e, 0x0a,
+	0xc5, 0xa4, 0x0a, 0x39, 0x4c, 0xb7, 0xf2, 0x98, 0xfe, 0x59, 0x80, 0xd6, 0x4b, 0xca, 0x26, 0x06,
+	0xda, 0xe8, 0x23, 0xb3, 0xee, 0x73, 0x96, 0x4d, 0x28, 0xd3, 0x09, 0x32, 0xae, 0x76, 0x0c, 0x0d,
+	0xd2, 0x83, 0xaa, 0x8f, 0xf6, 0x98, 0xdf, 0x79, 0x18, 0xb4, 0x8b, 0xdd, 0x62, 0xaf, 0x31, 0xac,
+	0xa9, 0x1b, 0x76, 0x79, 0xe7, 0xa1, 0x51, 0xf1, 0xd1, 0x16, 0x8b, 0x80, 0x3c, 0x81, 0x3a, 0xde,
+	0x4a, 0x65, 0xc6, 0x1c, 0x03, 0x1e, 0x48, 0xba, 0x15, 0xe3, 0xa1, 0x72, 0x5e, 0x0a, 0x9f, 0xfe,
+	0x1a, 0x1a, 0x61, 0x27, 0x2e, 0xeb, 0x4a, 0x1d, 0x6c, 0x58, 0x51, 0x7c, 0xb0, 0x1d, 0x28, 0xfa,
+	0x68, 0xe7, 0x5c, 0x6c, 0xe1, 0xd6, 0x03, 0xd8, 0xcb, 0x72, 0x7c, 0xe7, 0x35, 0xca, 0x27, 0xf9,
+	0x0c, 0x4a, 0x02, 0x53, 0xf2, 0xab, 0x0d, 0x5b, 0xfd, 0x70, 0x66, 0xa5, 0x0b, 0x35, 0x64, 0x8a,
+	0x7e, 0x0e, 0xcd, 0x11, 0xf2, 0xcf, 0xd1, 0xa6, 0x8c, 0x8a, 0xbe, 0xda, 0x34, 0x09, 0x08, 0x94,
+	0x1c, 0xca, 0x96, 0x8d, 0x21, 0xd7, 0xe4, 0x11, 0x14, 0x2d, 0xd7, 0x51, 0x17, 0x45, 0x2c, 0xf5,
+	0x2f, 0xa0, 0x95, 0xd9, 0x51, 0xb1, 0x18, 0x00, 0x4c, 0x22, 0xaf, 0xdc, 0xb8, 0x36, 0xdc, 0x51,
+	0x22, 0x7c, 0xe9, 0x5a, 0xb2, 0xad, 0x8d, 0x44, 0x8a, 0xfe, 0x1d, 0x3c, 0x14, 0x47, 0xb1, 0x8c,
+	0x91, 0x0f, 0xa1, 0xe2, 0xa8, 0xf5, 0xba, 0x9f, 0x47, 0x09, 0xe4, 0x31, 0x94, 0xc4, 0x21, 0xcb,
+	0x62, 0x33, 0x67, 0x2c, 0x03, 0xfa, 0x0c, 0xf6, 0x47, 0xc8, 0x5f, 0xcd, 0x3d, 0x07, 0xe7, 0xc8,
+	0x32, 0x83, 0x70, 0xb3, 0xe2, 0xb6, 0x69, 0x61, 0xa4, 0xb8, 0x30, 0xc8, 0x11, 0x00, 0xf7, 0x4d,
+	0x16, 0x50, 0x4e, 0x6f, 0xc2, 0xb6, 0xa9, 0x18, 0x09, 0x8f, 0xfe, 0x0d, 0x68, 0x79, 0x60, 0x4a,
+	0x99, 0x8f, 0xa1, 0x41, 0x53, 0x21, 0xd9, 0x27, 0xb5, 0xe1, 0xae, 0x3a, 0xb9, 0xa4, 0x0a, 0x46,
+	0x26, 0x55, 0x9f, 0xc8, 0x13, 0x7c, 0xc5, 0x38, 0xfa, 0xa2, 0x94, 0x7b, 0x50, 0x20, 0x09, 0x69,
+	0xaa, 0xa1, 0x1a, 0xef, 0x24, 0xf0, 0x1a, 0x5a, 0x19, 0x14, 0x55, 0xfb, 0x73, 0xa8, 0xd2, 0xa5,
+	0x77, 0x53, 0xd9, 0x71, 0x96, 0xfe, 0x33, 0x34, 0x2f, 0xd0, 0xf4, 0xad, 0xeb, 0x8b, 0xbb, 0xf9,
+	0x95, 0xeb, 0x44, 0x15, 0x37, 0xa1, 0xfc, 0x66, 0x81, 0xfe, 0x9d, 0xaa, 0x37, 0x34, 0x84, 0x77,
+	0x46, 0xd9, 0x24, 0x50, 0x63, 0x32, 0x34, 0xc8, 0x53, 0x68, 0x28, 0x3a, 0x63, 0x35, 0xec, 0xd4,
+	0x88, 0x54, 0xde, 0x73, 0xe9, 0x14, 0x3f, 0x76, 0xe8, 0x9c, 0x72, 0xd9, 0xbc, 0x65, 0x23, 0x34,
+	0xf4, 0x1f, 0x61, 0x3b, 0x84, 0xfe, 0x6f, 0x57, 0x8a, 0x40, 0x49, 0x80, 0x2f, 0x75, 0x13, 0x6b,
+	0x72, 0x10, 0xce, 0x13, 0xcb, 0x5d, 0x30, 0x2e, 0x4b, 0x28, 0xca, 0x11, 0xf2, 0x99, 0xb0, 0x05,
+	0xfa, 0xdc, 0xe4, 0xd6, 0xb5, 0x9a, 0x94, 0xa1, 0xa1, 0x7f, 0x0a, 0xad, 0x0c, 0x7d, 0x25, 0xe5,
+	0x53, 0xd8, 0x0e, 0xa4, 0x4b, 0xe9, 0x58, 0x57, 0x3a, 0x86, 0x79, 0x86, 0x0a, 0x0e, 0xff, 0x79,
+	0x00, 0xe5, 0x91, 0xa8, 0x91, 0x7c, 0x0b, 0x0f, 0xd4, 0x93, 0x43, 0x96, 0x4d, 0x9e, 0xfe, 0x80,
+	0xd0, 0xf6, 0xb2, 0xee, 0x10, 0x4a, 0xef, 0xfe, 0xfa, 0xd7, 0xdf, 0x7f, 0x6c, 0x69, 0xa4, 0x3d,
+	0xb8, 0x79, 0xae, 0xbe, 0x46, 0xc4, 0x38, 0x18, 0xfc, 0x20, 0x1a, 0xfd, 0x93, 0xb3, 0xb3, 0x9f,
+	0xc8, 0x0d, 0x34, 0xd2, 0x2f, 0x37, 0xe9, 0xc4, 0x7b, 0xad, 0x7e, 0x3a, 0x68, 0x87, 0x6b, 0xa2,
+	0x0a, 0xf0, 0x03, 0x09, 0x78, 0x4c, 0x1e, 0xc7, 0x80, 0x89, 0x4f, 0xa5, 0x04, 0xae, 0x09, 0x95,
+	0xe5, 0x33, 0x4a, 0x32, 0xd5, 0x47, 0x58, 0xef, 0xaf, 0xf8, 0x15, 0xca, 0x89, 0x44, 0x39, 0x22,
+	0x9d, 0x34, 0x2d, 0xb9, 0xbf, 0xbc, 0x16, 0x12, 0xe2, 0x7b, 0xa8, 0x25, 0x9e, 0x29, 0xb2, 0x1f,
+	0xef, 0x96, 0x79, 0x4a, 0x35, 0x2d, 0x2f, 0xa4, 0xb0, 0x34, 0x89, 0xd5, 0x24, 0x24, 0xc6, 0xf2,
+	0x96, 0x5b, 0xde, 0x42, 0x23, 0x3d, 0xca, 0x23, 0xf1, 0x72, 0x5f, 0x31, 0xed, 0x70, 0x4d, 0x54,
+	0x41, 0x3d, 0x93, 0x50, 0x4f, 0xc8, 0x71, 0x0c, 0xe5, 0x47, 0x59, 0x69, 0x6e, 0x3e, 0xd4, 0x53,
+	0xd3, 0x97, 0x1c, 0xc4, 0x14, 0x56, 0xa6, 0xbc, 0xd6, 0xc9, 0x0f, 0x2a, 0xd8, 0x53, 0x09, 0xdb,
+	0x25, 0x47, 0x31, 0x6c, 0x3c, 0x9d, 0x13, 0x47, 0xf6, 0x5b, 0xf8, 0x1d, 0x92, 0x99, 0x6e, 0xa4,
+	0x1b, 0x6f, 0x9e, 0x3f, 0x65, 0xb5, 0xe3, 0x0d, 0x19, 0xaa, 0x86, 0xbe, 0xac, 0xa1, 0x47, 0x4e,
+	0xe3, 0x1a, 0xd2, 0xf3, 0x2f, 0xc3, 0x7f, 0x01, 0xf5, 0xd4, 0x9c, 0x4a, 0xf2, 0x5f, 0x99, 0x91,
+	0x5a, 0x27, 0x3f, 0xb8, 0x5e, 0xf6, 0x68, 0x88, 0x65, 0x60, 0x11, 0xea, 0xa9, 0x9e, 0x8e, 0x60,
+	0xf3, 0x06, 0x9d, 0xd6, 0xc9, 0x0f, 0x2a, 0xd8, 0x7d, 0x09, 0xbb, 0x4b, 0xde, 0x8b, 0x61, 0xc3,
+	0xce, 0x0f, 0xae, 0xb6, 0xe5, 0x1f, 0x85, 0x8f, 0xfe, 0x1d, 0x00, 0x1f, 0x1e, 0x92, 0x59, 0x86,
+	0x0c, 0x00, 0x00,
 }
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	gorefpb "github.com/korfuri/goref/proto"
+)
+
+// before returns whether line:col a is strictly before line:col b.
+func before(aL, aC, bL, bC int32) bool {
+	return aL < bL || (aL == bL && aC < bC)
+}
+
+// spans returns whether line:col lies within an identifier at p. Its
+// end is the end of the identifier if p has one, or is computed from
+// the identifier's name otherwise. Positions without an end, such as
+// those of declarations, have an end of -1:-1, or of 0:0 once stored
+// as JSON without one.
+func spans(p *gorefpb.Position, ident, filename string, line, col int32) bool {
+	if p.GetFilename() != filename {
+		return false
+	}
+	endL, endC := p.EndLine, p.EndCol
+	if endL < 0 || (endL == 0 && endC == 0) {
+		endL, endC = p.StartLine, p.StartCol+int32(len(ident))
+	}
+	return !before(line, col, p.StartLine, p.StartCol) && before(line, col, endL, endC)
+}
+
+// resolves returns whether a Ref resolves its source identifier to
+// the declaration it denotes. Implementation, Extension and
+// DynamicCall Refs are derived from the types and calls of a program,
+// and don't.
+func resolves(r *gorefpb.Ref) bool {
+	switch r.Type {
+	case gorefpb.Type_Implementation, gorefpb.Type_Extension, gorefpb.Type_DynamicCall:
+		return false
+	}
+	return true
+}
+
+// DefinitionFrom returns the target of the Ref among refs whose
+// source is the identifier at line:col of a file, or nil if there's
+// none.
+func DefinitionFrom(refs []*gorefpb.Ref, filename string, line, col int32) *gorefpb.Location {
+	for _, r := range refs {
+		if resolves(r) && spans(r.GetFrom().GetPosition(), r.GetFrom().GetIdent(), filename, line, col) {
+			return r.To
+		}
+	}
+	return nil
+}
+
+// DefinitionTo returns the target of the Ref among refs whose target
+// is the identifier at line:col of a file, or nil if there's none.
+// This resolves declarations, whose definition is themselves.
+func DefinitionTo(refs []*gorefpb.Ref, filename string, line, col int32) *gorefpb.Location {
+	for _, r := range refs {
+		if spans(r.GetTo().GetPosition(), r.GetTo().GetIdent(), filename, line, col) {
+			return r.To
+		}
+	}
+	return nil
+}
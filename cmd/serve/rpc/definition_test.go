@@ -0,0 +1,134 @@
+package rpc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/cmd/serve/rpc"
+	gorefpb "github.com/korfuri/goref/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpans(t *testing.T) {
+	ident := &gorefpb.Position{Filename: "a.go", StartLine: 3, StartCol: 5, EndLine: 3, EndCol: 9}
+	// Positions of declarations have no end.
+	noEnd := &gorefpb.Position{Filename: "a.go", StartLine: 3, StartCol: 5, EndLine: -1, EndCol: -1}
+	noEndJSON := &gorefpb.Position{Filename: "a.go", StartLine: 3, StartCol: 5}
+	multiLine := &gorefpb.Position{Filename: "a.go", StartLine: 3, StartCol: 5, EndLine: 5, EndCol: 2}
+	for _, tc := range []struct {
+		p         *gorefpb.Position
+		filename  string
+		line, col int32
+		expected  bool
+	}{
+		{ident, "a.go", 3, 5, true},
+		{ident, "a.go", 3, 8, true},
+		{ident, "a.go", 3, 4, false},
+		{ident, "a.go", 3, 9, false},
+		{ident, "a.go", 2, 6, false},
+		{ident, "a.go", 4, 6, false},
+		{ident, "b.go", 3, 5, false},
+		{noEnd, "a.go", 3, 5, true},
+		{noEnd, "a.go", 3, 8, true},
+		{noEnd, "a.go", 3, 9, false},
+		{noEnd, "a.go", 4, 5, false},
+		{noEnd, "b.go", 3, 6, false},
+		{noEndJSON, "a.go", 3, 5, true},
+		{noEndJSON, "a.go", 3, 8, true},
+		{noEndJSON, "a.go", 3, 9, false},
+		{multiLine, "a.go", 3, 5, true},
+		{multiLine, "a.go", 3, 100, true},
+		{multiLine, "a.go", 4, 1, true},
+		{multiLine, "a.go", 5, 1, true},
+		{multiLine, "a.go", 5, 2, false},
+		{multiLine, "a.go", 3, 4, false},
+		{multiLine, "b.go", 4, 1, false},
+	} {
+		assert.Equal(t, tc.expected, rpc.Spans(tc.p, "Name", tc.filename, tc.line, tc.col),
+			"%s:%d:%d in %v", tc.filename, tc.line, tc.col, tc.p)
+	}
+}
+
+func TestResolves(t *testing.T) {
+	for _, typ := range []gorefpb.Type{gorefpb.Type_Implementation, gorefpb.Type_Extension, gorefpb.Type_DynamicCall} {
+		assert.False(t, rpc.Resolves(&gorefpb.Ref{Type: typ}), "%s", typ)
+	}
+	for _, typ := range []gorefpb.Type{gorefpb.Type_Instantiation, gorefpb.Type_Call, gorefpb.Type_Import, gorefpb.Type_Reference} {
+		assert.True(t, rpc.Resolves(&gorefpb.Ref{Type: typ}), "%s", typ)
+	}
+}
+
+func TestDefinitionFrom(t *testing.T) {
+	from := &gorefpb.Location{
+		Position: &gorefpb.Position{Filename: "a.go", StartLine: 3, StartCol: 5},
+		Ident:    "T",
+	}
+	iface := &gorefpb.Location{
+		Position: &gorefpb.Position{Filename: "b.go", StartLine: 10, StartCol: 6},
+		Package:  "p",
+		Ident:    "Iface",
+	}
+	to := &gorefpb.Location{
+		Position: &gorefpb.Position{Filename: "b.go", StartLine: 20, StartCol: 6},
+		Package:  "p",
+		Ident:    "T",
+	}
+	refs := []*gorefpb.Ref{
+		{From: from, To: iface, Type: gorefpb.Type_Implementation},
+		{From: from, To: to, Type: gorefpb.Type_Reference},
+	}
+	assert.Equal(t, to, rpc.DefinitionFrom(refs, "a.go", 3, 5))
+	assert.Nil(t, rpc.DefinitionFrom(refs, "a.go", 3, 6))
+	assert.Nil(t, rpc.DefinitionFrom(refs[:1], "a.go", 3, 5))
+
+	assert.Equal(t, iface, rpc.DefinitionTo(refs, "b.go", 10, 10))
+	assert.Equal(t, to, rpc.DefinitionTo(refs, "b.go", 20, 6))
+	assert.Nil(t, rpc.DefinitionTo(refs, "a.go", 3, 5))
+}
+
+// definition returns the definition of the identifier at line:col of
+// a file as "package.ident filename:line:col", or "" if it has none.
+func definition(t *testing.T, s pb.GorefServer, filename string, line, col int32) string {
+	res, err := s.GetDefinition(context.Background(), &pb.GetDefinitionRequest{Path: filename, Line: line, Col: col})
+	assert.NoError(t, err)
+	d := res.GetDefinition()
+	if d == nil {
+		return ""
+	}
+	p := d.Position
+	return fmt.Sprintf("%s.%s %s:%d:%d", d.Package, d.Ident, p.Filename, p.StartLine, p.StartCol)
+}
+
+func TestGetDefinition(t *testing.T) {
+	graph, st, cleanup := servers(t, true)
+	defer cleanup()
+	main := pkgpath + "/main.go"
+	libA := libpath + ".LibA " + libpath + "/lib.go:24:6"
+
+	for _, s := range []pb.GorefServer{graph, st} {
+		// LibA in lib.LibA(0) spans main.go:13:10-13:14.
+		assert.Equal(t, libA, definition(t, s, main, 13, 10))
+		assert.Equal(t, libA, definition(t, s, main, 13, 13))
+		assert.Equal(t, "", definition(t, s, main, 13, 14))
+
+		// LibA's declaration is its own definition.
+		assert.Equal(t, libA, definition(t, s, libpath+"/lib.go", 24, 7))
+
+		// The func keyword of main.
+		assert.Equal(t, "", definition(t, s, main, 12, 1))
+
+		_, err := s.GetDefinition(context.Background(), &pb.GetDefinitionRequest{Path: pkgpath + "/nothere.go", Line: 1, Col: 1})
+		assert.Error(t, err)
+	}
+
+	// acceptAB(AB(0)) at main.go:14:2 refers to the same package.
+	// Only the graph has same-package Refs, and only with
+	// -local_refs.
+	assert.Equal(t, pkgpath+".acceptAB "+main+":65:6", definition(t, graph, main, 14, 2))
+	assert.Equal(t, "", definition(t, st, main, 14, 2))
+	graph, _, cleanup = servers(t, false)
+	defer cleanup()
+	assert.Equal(t, "", definition(t, graph, main, 14, 2))
+}
@@ -0,0 +1,7 @@
+package rpc
+
+// Spans and Resolves export spans and resolves to rpc_test.
+var (
+	Spans    = spans
+	Resolves = resolves
+)
@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	gorefpb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/symbols"
+)
+
+// A GraphServer implements pb.GorefServer over a PackageGraph, which
+// it keeps in memory.
+type GraphServer struct {
+	graph goref.PackageGraph
+
+	// symbols indexes the declarations of graph for
+	// SearchSymbols.
+	symbols *symbols.Index
+}
+
+// NewGraphServer returns a GraphServer that serves the references
+// and files of pg.
+func NewGraphServer(pg goref.PackageGraph) *GraphServer {
+	return &GraphServer{
+		graph:   pg,
+		symbols: symbols.NewIndex(symbols.FromGraph(pg)),
+	}
+}
+
+func (s *GraphServer) GetAnnotations(ctx context.Context, req *pb.GetAnnotationsRequest) (*pb.GetAnnotationsResponse, error) {
+	fpath := req.Path
+	corpus, err := findCorpus(s.graph.Corpora, fpath)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("%s\n", corpus.Pkg(fpath))
+	pkg, in := s.graph.Packages[corpus.Pkg(fpath)]
+	if !in {
+		return nil, fmt.Errorf("Internal server error")
+	}
+
+	p, err := store.NewPage(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	// The graph doesn't change while it's served, so pages are
+	// offsets into the file's Refs.
+	offset := 0
+	if p.Token != "" {
+		if err := store.DecodeToken(p.Token, &offset); err != nil || offset < 0 {
+			return nil, store.ErrInvalidPageToken
+		}
+	}
+	refs := make([]*goref.Ref, 0)
+	for _, r := range pkg.InRefs {
+		if r.ToPosition.File == fpath {
+			refs = append(refs, r)
+		}
+	}
+	for _, r := range pkg.OutRefs {
+		if r.FromPosition.File == fpath {
+			refs = append(refs, r)
+		}
+	}
+
+	res := &pb.GetAnnotationsResponse{
+		Path: fpath,
+	}
+	for i := offset; i < len(refs) && i < offset+p.Limit(); i++ {
+		res.Annotation = append(res.Annotation, refs[i].ToProto())
+	}
+	if offset+p.Limit() < len(refs) {
+		res.NextPageToken = store.EncodeToken(offset + p.Limit())
+	}
+	return res, nil
+}
+
+func (s *GraphServer) GetFiles(ctx context.Context, req *pb.GetFilesRequest) (*pb.GetFilesResponse, error) {
+	pkg, in := s.graph.Packages[req.Package]
+	if !in {
+		return nil, ErrUnknownPackage
+	}
+
+	p, err := store.NewPage(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	files := append([]string(nil), pkg.Files...)
+	sort.Strings(files)
+	files, next, err := store.PageStrings(files, p)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetFilesResponse{
+		Package:       req.Package,
+		Filename:      files,
+		NextPageToken: next,
+	}, nil
+}
+
+func (s *GraphServer) GetPackages(ctx context.Context, req *pb.GetPackagesRequest) (*pb.GetPackagesResponse, error) {
+	p, err := store.NewPage(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	packages := make([]string, 0)
+	for _, pkg := range s.graph.Packages {
+		if strings.HasPrefix(pkg.Path, req.Prefix) {
+			packages = append(packages, pkg.Path)
+		}
+	}
+	sort.Strings(packages)
+	packages, next, err := store.PageStrings(packages, p)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetPackagesResponse{
+		Package:       packages,
+		NextPageToken: next,
+	}, nil
+}
+
+func (s *GraphServer) FindReferences(ctx context.Context, req *pb.FindReferencesRequest) (*pb.FindReferencesResponse, error) {
+	pkg, in := s.graph.Packages[req.Package]
+	if !in {
+		return nil, ErrUnknownPackage
+	}
+
+	refs := make([]*gorefpb.Ref, 0)
+	for _, r := range pkg.InRefs {
+		if req.Ident == "" || r.ToIdent == req.Ident {
+			refs = append(refs, r.ToProto())
+		}
+	}
+	return NewFindReferencesResponse(req, refs), nil
+}
+
+func (s *GraphServer) GetDefinition(ctx context.Context, req *pb.GetDefinitionRequest) (*pb.GetDefinitionResponse, error) {
+	corpus, err := findCorpus(s.graph.Corpora, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	pkg, in := s.graph.Packages[corpus.Pkg(req.Path)]
+	if !in {
+		return nil, fmt.Errorf("Internal server error")
+	}
+
+	// fromFile returns the Refs among refs that are made from the
+	// requested file.
+	fromFile := func(refs []*goref.Ref) []*gorefpb.Ref {
+		l := make([]*gorefpb.Ref, 0)
+		for _, r := range refs {
+			if r.FromPosition.File == req.Path {
+				l = append(l, r.ToProto())
+			}
+		}
+		return l
+	}
+	if def := DefinitionFrom(fromFile(pkg.OutRefs), req.Path, req.Line, req.Col); def != nil {
+		return &pb.GetDefinitionResponse{Definition: def}, nil
+	}
+	// LocalRefs are only recorded with -local_refs.
+	if def := DefinitionFrom(fromFile(pkg.LocalRefs), req.Path, req.Line, req.Col); def != nil {
+		return &pb.GetDefinitionResponse{Definition: def}, nil
+	}
+
+	// Declarations are their own definition.
+	line, col := int(req.Line), int(req.Col)
+	for _, d := range pkg.Decls() {
+		p := d.Position
+		if p.File == req.Path && p.PosL == line && p.PosC <= col && col < p.PosC+len(d.Name) {
+			return &pb.GetDefinitionResponse{
+				Definition: &gorefpb.Location{
+					Position: p.ToProto(),
+					Package:  pkg.Path,
+					Ident:    d.Name,
+				},
+			}, nil
+		}
+	}
+	return &pb.GetDefinitionResponse{}, nil
+}
+
+func (s *GraphServer) GetImplementations(ctx context.Context, req *pb.GetImplementationsRequest) (*pb.GetImplementationsResponse, error) {
+	if _, in := s.graph.Packages[req.Package]; !in {
+		return nil, ErrUnknownPackage
+	}
+	refsTo := func(loadpath, ident string) ([]*gorefpb.Ref, error) {
+		refs := make([]*gorefpb.Ref, 0)
+		if pkg, in := s.graph.Packages[loadpath]; in {
+			for _, r := range pkg.InRefs {
+				if r.ToIdent == ident {
+					refs = append(refs, r.ToProto())
+				}
+			}
+		}
+		return refs, nil
+	}
+	impls, err := FindImplementations(req.Package, req.Iface, req.Transitive, refsTo)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetImplementationsResponse{Implementation: impls}, nil
+}
+
+func (s *GraphServer) GetInterfaces(ctx context.Context, req *pb.GetInterfacesRequest) (*pb.GetInterfacesResponse, error) {
+	if _, in := s.graph.Packages[req.Package]; !in {
+		return nil, ErrUnknownPackage
+	}
+	refsFrom := func(loadpath, ident string) ([]*gorefpb.Ref, error) {
+		refs := make([]*gorefpb.Ref, 0)
+		if pkg, in := s.graph.Packages[loadpath]; in {
+			for _, r := range pkg.OutRefs {
+				if r.FromIdent == ident {
+					refs = append(refs, r.ToProto())
+				}
+			}
+		}
+		return refs, nil
+	}
+	ifaces, err := FindInterfaces(req.Package, req.Type, req.Transitive, refsFrom)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetInterfacesResponse{Interface: ifaces}, nil
+}
+
+func (s *GraphServer) SearchSymbols(ctx context.Context, req *pb.SearchSymbolsRequest) (*pb.SearchSymbolsResponse, error) {
+	q, err := SymbolsQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	return NewSearchSymbolsResponse(s.symbols.Search(q)), nil
+}
+
+func (s *GraphServer) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFileResponse, error) {
+	fpath := req.Path
+	corpus, err := findCorpus(s.graph.Corpora, fpath)
+	if err != nil {
+		return nil, err
+	}
+	if f, err := ioutil.ReadFile(corpus.Abs(fpath)); err == nil {
+		return &pb.GetFileResponse{
+			Path:     fpath,
+			Contents: string(f),
+		}, nil
+	}
+	return nil, fmt.Errorf("Internal server error")
+}
@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"sort"
+
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	gorefpb "github.com/korfuri/goref/proto"
+)
+
+// A RefsFunc returns Refs to or from an identifier of a package.
+type RefsFunc func(loadpath, ident string) ([]*gorefpb.Ref, error)
+
+// relatedTypes walks the Implementation and Extension Refs that refs
+// returns for an identifier of a package, and returns the other end
+// of each of them, as returned by other. With transitive, the walk
+// goes on from the ends for which expand returns true. A location's
+// type is Extension if it's only reached through Extension Refs, and
+// Implementation otherwise.
+func relatedTypes(loadpath, ident string, transitive bool, refs RefsFunc, other func(r *gorefpb.Ref) *gorefpb.Location, expand func(r *gorefpb.Ref) bool) ([]*pb.TypeLocation, error) {
+	key := func(l *gorefpb.Location) string {
+		return l.Package + "." + l.Ident
+	}
+	start := &pb.TypeLocation{
+		Location: &gorefpb.Location{Package: loadpath, Ident: ident},
+		Type:     gorefpb.Type_Extension,
+	}
+	seen := map[string]bool{key(start.Location): true}
+	result := make([]*pb.TypeLocation, 0)
+	for queue := []*pb.TypeLocation{start}; len(queue) > 0; queue = queue[1:] {
+		from := queue[0]
+		l, err := refs(from.Location.Package, from.Location.Ident)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range l {
+			if r.Type != gorefpb.Type_Implementation && r.Type != gorefpb.Type_Extension {
+				continue
+			}
+			loc := other(r)
+			if seen[key(loc)] {
+				continue
+			}
+			seen[key(loc)] = true
+			tl := &pb.TypeLocation{Location: loc, Type: r.Type}
+			if from.Type == gorefpb.Type_Implementation {
+				tl.Type = gorefpb.Type_Implementation
+			}
+			result = append(result, tl)
+			if transitive && expand(r) {
+				queue = append(queue, tl)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Location, result[j].Location
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Ident < b.Ident
+	})
+	return result, nil
+}
+
+// FindImplementations returns the types that implement an interface
+// and the interfaces that extend it, from the Refs to them that
+// refsTo returns. With transitive, it also returns those of the
+// interfaces that extend it.
+func FindImplementations(loadpath, iface string, transitive bool, refsTo RefsFunc) ([]*pb.TypeLocation, error) {
+	return relatedTypes(loadpath, iface, transitive, refsTo,
+		func(r *gorefpb.Ref) *gorefpb.Location { return r.From },
+		// Only interfaces are extended.
+		func(r *gorefpb.Ref) bool { return r.Type == gorefpb.Type_Extension })
+}
+
+// FindInterfaces returns the interfaces that a type implements, or
+// that an interface extends, from the Refs from them that refsFrom
+// returns. With transitive, it also returns the interfaces that
+// those extend.
+func FindInterfaces(loadpath, typ string, transitive bool, refsFrom RefsFunc) ([]*pb.TypeLocation, error) {
+	return relatedTypes(loadpath, typ, transitive, refsFrom,
+		func(r *gorefpb.Ref) *gorefpb.Location { return r.To },
+		func(r *gorefpb.Ref) bool { return true })
+}
@@ -0,0 +1,177 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/cmd/serve/rpc"
+	gorefpb "github.com/korfuri/goref/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// typeRefs are the Refs between the types and interfaces of a fake
+// package p. J extends I, K extends J, and I extends K, which makes
+// a cycle. T implements all three, and U only implements K.
+var typeRefs = []*gorefpb.Ref{
+	typeRef("J", "I", gorefpb.Type_Extension),
+	typeRef("K", "J", gorefpb.Type_Extension),
+	typeRef("I", "K", gorefpb.Type_Extension),
+	typeRef("T", "I", gorefpb.Type_Implementation),
+	typeRef("T", "J", gorefpb.Type_Implementation),
+	typeRef("T", "K", gorefpb.Type_Implementation),
+	typeRef("U", "K", gorefpb.Type_Implementation),
+	// Only Implementation and Extension Refs are walked.
+	typeRef("T", "F", gorefpb.Type_Call),
+	typeRef("F", "I", gorefpb.Type_Reference),
+}
+
+func typeRef(from, to string, typ gorefpb.Type) *gorefpb.Ref {
+	return &gorefpb.Ref{
+		From: &gorefpb.Location{Package: "p", Ident: from},
+		To:   &gorefpb.Location{Package: "p", Ident: to},
+		Type: typ,
+	}
+}
+
+// refsTo and refsFrom are the RefsFuncs of typeRefs.
+func refsTo(loadpath, ident string) ([]*gorefpb.Ref, error) {
+	l := make([]*gorefpb.Ref, 0)
+	for _, r := range typeRefs {
+		if r.To.Package == loadpath && r.To.Ident == ident {
+			l = append(l, r)
+		}
+	}
+	return l, nil
+}
+
+func refsFrom(loadpath, ident string) ([]*gorefpb.Ref, error) {
+	l := make([]*gorefpb.Ref, 0)
+	for _, r := range typeRefs {
+		if r.From.Package == loadpath && r.From.Ident == ident {
+			l = append(l, r)
+		}
+	}
+	return l, nil
+}
+
+// typeLocations returns the locations of TypeLocations as
+// "package.ident" with their type.
+func typeLocations(t *testing.T, l []*pb.TypeLocation) map[string]gorefpb.Type {
+	m := make(map[string]gorefpb.Type)
+	for _, tl := range l {
+		m[tl.Location.Package+"."+tl.Location.Ident] = tl.Type
+	}
+	assert.Len(t, m, len(l), "locations are only returned once")
+	return m
+}
+
+func TestFindImplementations(t *testing.T) {
+	l, err := rpc.FindImplementations("p", "I", false, refsTo)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]gorefpb.Type{
+		"p.J": gorefpb.Type_Extension,
+		"p.T": gorefpb.Type_Implementation,
+	}, typeLocations(t, l))
+
+	// T is reached from I, J and K, and the walk stops at I.
+	l, err = rpc.FindImplementations("p", "I", true, refsTo)
+	assert.NoError(t, err)
+	assert.Len(t, l, 4)
+	assert.Equal(t, map[string]gorefpb.Type{
+		"p.J": gorefpb.Type_Extension,
+		"p.K": gorefpb.Type_Extension,
+		"p.T": gorefpb.Type_Implementation,
+		"p.U": gorefpb.Type_Implementation,
+	}, typeLocations(t, l))
+	// Results are sorted.
+	assert.Equal(t, "J", l[0].Location.Ident)
+	assert.Equal(t, "U", l[3].Location.Ident)
+
+	l, err = rpc.FindImplementations("p", "T", true, refsTo)
+	assert.NoError(t, err)
+	assert.Empty(t, l)
+}
+
+func TestFindInterfaces(t *testing.T) {
+	l, err := rpc.FindInterfaces("p", "U", false, refsFrom)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]gorefpb.Type{
+		"p.K": gorefpb.Type_Implementation,
+	}, typeLocations(t, l))
+
+	// The interfaces that K extends are implemented by U, too.
+	l, err = rpc.FindInterfaces("p", "U", true, refsFrom)
+	assert.NoError(t, err)
+	assert.Len(t, l, 3)
+	assert.Equal(t, map[string]gorefpb.Type{
+		"p.I": gorefpb.Type_Implementation,
+		"p.J": gorefpb.Type_Implementation,
+		"p.K": gorefpb.Type_Implementation,
+	}, typeLocations(t, l))
+
+	// T reaches K directly and through J and I.
+	l, err = rpc.FindInterfaces("p", "T", true, refsFrom)
+	assert.NoError(t, err)
+	assert.Len(t, l, 3)
+
+	// K extends J, which extends I, which extends K.
+	l, err = rpc.FindInterfaces("p", "K", true, refsFrom)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]gorefpb.Type{
+		"p.I": gorefpb.Type_Extension,
+		"p.J": gorefpb.Type_Extension,
+	}, typeLocations(t, l))
+}
+
+func TestFindImplementations_error(t *testing.T) {
+	errRefs := errors.New("refs")
+	calls := 0
+	refs := func(loadpath, ident string) ([]*gorefpb.Ref, error) {
+		calls++
+		if calls > 1 {
+			return nil, errRefs
+		}
+		return refsTo(loadpath, ident)
+	}
+	_, err := rpc.FindImplementations("p", "I", true, refs)
+	assert.Equal(t, errRefs, err)
+}
+
+func TestGetImplementations(t *testing.T) {
+	graph, st, cleanup := servers(t, false)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, s := range []pb.GorefServer{graph, st} {
+		res, err := s.GetImplementations(ctx, &pb.GetImplementationsRequest{Package: libpath, Iface: "IfaceLibA"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]gorefpb.Type{
+			pkgpath + ".IfaceA":     gorefpb.Type_Extension,
+			pkgpath + ".IfaceAB":    gorefpb.Type_Extension,
+			pkgpath + ".A":          gorefpb.Type_Implementation,
+			pkgpath + ".AB":         gorefpb.Type_Implementation,
+			libpath + ".IfaceLibAB": gorefpb.Type_Extension,
+			libpath + ".LibA":       gorefpb.Type_Implementation,
+			libpath + ".LibAB":      gorefpb.Type_Implementation,
+		}, typeLocations(t, res.Implementation))
+
+		res2, err := s.GetInterfaces(ctx, &pb.GetInterfacesRequest{Package: pkgpath, Type: "IfaceAB", Transitive: true})
+		assert.NoError(t, err)
+		// IfaceAB has the same methods as IfaceLibAB, so each
+		// extends the other.
+		assert.Equal(t, map[string]gorefpb.Type{
+			pkgpath + ".IfaceA":     gorefpb.Type_Extension,
+			pkgpath + ".IfaceB":     gorefpb.Type_Extension,
+			libpath + ".IfaceLibA":  gorefpb.Type_Extension,
+			libpath + ".IfaceLibAB": gorefpb.Type_Extension,
+			libpath + ".IfaceLibB":  gorefpb.Type_Extension,
+		}, typeLocations(t, res2.Interface))
+
+		_, err = s.GetImplementations(ctx, &pb.GetImplementationsRequest{Package: "does/not/exist", Iface: "I"})
+		assert.Equal(t, rpc.ErrUnknownPackage, err)
+		_, err = s.GetInterfaces(ctx, &pb.GetInterfacesRequest{Package: "does/not/exist", Type: "T"})
+		assert.Equal(t, rpc.ErrUnknownPackage, err)
+	}
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"sort"
+	"strings"
+
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	gorefpb "github.com/korfuri/goref/proto"
+)
+
+// isTestFile returns whether a file is a test file, which is the case
+// of both in-package tests and XTest packages.
+func isTestFile(filename string) bool {
+	return strings.HasSuffix(filename, "_test.go")
+}
+
+// NewFindReferencesResponse returns the response to a
+// FindReferencesRequest from the Refs to its identifier (or package).
+// Refs are filtered by the request's ref_types and exclude_tests, and
+// grouped by the file they're made from. Files are sorted by name,
+// and each file's Refs by position.
+func NewFindReferencesResponse(req *pb.FindReferencesRequest, refs []*gorefpb.Ref) *pb.FindReferencesResponse {
+	types := make(map[gorefpb.Type]bool)
+	for _, t := range req.RefTypes {
+		types[t] = true
+	}
+	byFile := make(map[string]*pb.FileReferences)
+	for _, r := range refs {
+		if len(types) > 0 && !types[r.Type] {
+			continue
+		}
+		filename := r.GetFrom().GetPosition().GetFilename()
+		if req.ExcludeTests && isTestFile(filename) {
+			continue
+		}
+		f, in := byFile[filename]
+		if !in {
+			f = &pb.FileReferences{Filename: filename}
+			byFile[filename] = f
+		}
+		f.Ref = append(f.Ref, r)
+	}
+
+	res := &pb.FindReferencesResponse{
+		Package: req.Package,
+		Ident:   req.Ident,
+	}
+	for _, f := range byFile {
+		sort.SliceStable(f.Ref, func(i, j int) bool {
+			a, b := f.Ref[i].GetFrom().GetPosition(), f.Ref[j].GetFrom().GetPosition()
+			if a.GetStartLine() != b.GetStartLine() {
+				return a.GetStartLine() < b.GetStartLine()
+			}
+			return a.GetStartCol() < b.GetStartCol()
+		})
+		res.File = append(res.File, f)
+	}
+	sort.Slice(res.File, func(i, j int) bool { return res.File[i].Filename < res.File[j].Filename })
+	return res
+}
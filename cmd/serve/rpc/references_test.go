@@ -0,0 +1,122 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/cmd/serve/rpc"
+	gorefpb "github.com/korfuri/goref/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// refFrom returns a Ref of the provided type made from line:col of a
+// file.
+func refFrom(filename string, line, col int32, typ gorefpb.Type) *gorefpb.Ref {
+	return &gorefpb.Ref{
+		From: &gorefpb.Location{
+			Position: &gorefpb.Position{Filename: filename, StartLine: line, StartCol: col},
+		},
+		Type: typ,
+	}
+}
+
+// files returns the files of a FindReferencesResponse, with the
+// positions of their Refs.
+func files(res *pb.FindReferencesResponse) map[string][][2]int32 {
+	m := make(map[string][][2]int32)
+	for _, f := range res.File {
+		for _, r := range f.Ref {
+			p := r.From.Position
+			m[f.Filename] = append(m[f.Filename], [2]int32{p.StartLine, p.StartCol})
+		}
+	}
+	return m
+}
+
+func TestNewFindReferencesResponse(t *testing.T) {
+	refs := []*gorefpb.Ref{
+		refFrom("b.go", 7, 2, gorefpb.Type_Call),
+		refFrom("a.go", 3, 9, gorefpb.Type_Call),
+		refFrom("b.go", 4, 1, gorefpb.Type_Instantiation),
+		refFrom("a_test.go", 1, 1, gorefpb.Type_Call),
+		refFrom("a.go", 3, 4, gorefpb.Type_Reference),
+		refFrom("a.go", 1, 20, gorefpb.Type_Call),
+	}
+
+	// Refs are grouped by file, with files sorted by name and Refs
+	// by position.
+	res := rpc.NewFindReferencesResponse(&pb.FindReferencesRequest{Package: "p", Ident: "I"}, refs)
+	assert.Equal(t, "p", res.Package)
+	assert.Equal(t, "I", res.Ident)
+	names := make([]string, 0)
+	for _, f := range res.File {
+		names = append(names, f.Filename)
+	}
+	assert.Equal(t, []string{"a.go", "a_test.go", "b.go"}, names)
+	assert.Equal(t, map[string][][2]int32{
+		"a.go":      {{1, 20}, {3, 4}, {3, 9}},
+		"a_test.go": {{1, 1}},
+		"b.go":      {{4, 1}, {7, 2}},
+	}, files(res))
+
+	res = rpc.NewFindReferencesResponse(&pb.FindReferencesRequest{
+		RefTypes: []gorefpb.Type{gorefpb.Type_Call, gorefpb.Type_Reference},
+	}, refs)
+	assert.Equal(t, map[string][][2]int32{
+		"a.go":      {{1, 20}, {3, 4}, {3, 9}},
+		"a_test.go": {{1, 1}},
+		"b.go":      {{7, 2}},
+	}, files(res))
+
+	res = rpc.NewFindReferencesResponse(&pb.FindReferencesRequest{
+		RefTypes:     []gorefpb.Type{gorefpb.Type_Call},
+		ExcludeTests: true,
+	}, refs)
+	assert.Equal(t, map[string][][2]int32{
+		"a.go": {{1, 20}, {3, 9}},
+		"b.go": {{7, 2}},
+	}, files(res))
+
+	assert.Empty(t, rpc.NewFindReferencesResponse(&pb.FindReferencesRequest{}, nil).File)
+}
+
+func TestFindReferences(t *testing.T) {
+	graph, st, cleanup := servers(t, false)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, s := range []pb.GorefServer{graph, st} {
+		res, err := s.FindReferences(ctx, &pb.FindReferencesRequest{Package: libpath, Ident: "IfaceLibA"})
+		assert.NoError(t, err)
+		// IfaceA, IfaceAB and IfaceLibAB extend IfaceLibA, and A,
+		// AB, LibA and LibAB implement it.
+		assert.Equal(t, map[string][][2]int32{
+			pkgpath + "/main.go": {{18, 6}, {28, 6}, {44, 6}, {50, 6}},
+			libpath + "/lib.go":  {{18, 6}, {24, 6}, {34, 6}},
+		}, files(res))
+
+		res, err = s.FindReferences(ctx, &pb.FindReferencesRequest{
+			Package:  libpath,
+			Ident:    "IfaceLibA",
+			RefTypes: []gorefpb.Type{gorefpb.Type_Extension},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][][2]int32{
+			pkgpath + "/main.go": {{18, 6}, {28, 6}},
+			libpath + "/lib.go":  {{18, 6}},
+		}, files(res))
+
+		// Without an identifier, Refs to any identifier of the
+		// package are returned.
+		res, err = s.FindReferences(ctx, &pb.FindReferencesRequest{Package: libpath})
+		assert.NoError(t, err)
+		assert.Contains(t, files(res)[pkgpath+"/main.go"], [2]int32{13, 10})
+
+		_, err = s.FindReferences(ctx, &pb.FindReferencesRequest{Package: "does/not/exist", Ident: "IfaceLibA"})
+		assert.Equal(t, rpc.ErrUnknownPackage, err)
+		// Packages are known by their whole load path.
+		_, err = s.FindReferences(ctx, &pb.FindReferencesRequest{Package: pkgpath + "/li"})
+		assert.Equal(t, rpc.ErrUnknownPackage, err)
+	}
+}
@@ -0,0 +1,47 @@
+// Package rpc implements the Goref gRPC service defined in
+// cmd/serve/proto, which cmd/serve and cmd/daemon serve.
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/net/context"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/store"
+)
+
+// ErrUnknownPackage is returned for requests about a package that
+// isn't in the graph or in the store.
+var ErrUnknownPackage = errors.New("Unknown package")
+
+// findCorpus returns the corpus among corpora that contains a file.
+func findCorpus(corpora []goref.Corpus, fpath string) (goref.Corpus, error) {
+	if filepath.Ext(fpath) != ".go" {
+		return goref.Corpus(""), fmt.Errorf("Not found: invalid extension")
+	}
+	var corpus goref.Corpus
+	for _, c := range corpora {
+		if c.ContainsRel(fpath) {
+			corpus = c
+			break
+		}
+	}
+	if corpus == "" {
+		return goref.Corpus(""), fmt.Errorf("Not found under any corpus")
+	}
+	return corpus, nil
+}
+
+// packageExists returns whether a Store has any version of a package.
+// Packages are listed in order, so a package comes first among the
+// packages that it's a prefix of.
+func packageExists(ctx context.Context, st store.Store, loadpath string) (bool, error) {
+	packages, _, err := st.ListPackages(ctx, loadpath, store.Page{Size: 1})
+	if err != nil {
+		return false, err
+	}
+	return len(packages) > 0 && packages[0] == loadpath, nil
+}
@@ -0,0 +1,40 @@
+package rpc_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/cmd/serve/rpc"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+	libpath = pkgpath + "/lib"
+)
+
+// servers loads testprograms/interfaces, and returns a GraphServer of
+// its graph and a StoreServer of a SQLite store it's stored into. The
+// returned function removes the store.
+func servers(t *testing.T, localRefs bool) (*rpc.GraphServer, *rpc.StoreServer, func()) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetLocalRefs(localRefs)
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+
+	dir, err := ioutil.TempDir("", "goref-rpc")
+	assert.NoError(t, err)
+	st, err := sqlite.Open(filepath.Join(dir, "goref.db"))
+	assert.NoError(t, err)
+	assert.NoError(t, store.LoadGraph(context.Background(), st, *pg))
+	return rpc.NewGraphServer(*pg), rpc.NewStoreServer(pg.Corpora, st), func() {
+		st.Close()
+		os.RemoveAll(dir)
+	}
+}
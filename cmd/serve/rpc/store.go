@@ -0,0 +1,198 @@
+package rpc
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	gorefpb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+)
+
+// A StoreServer implements pb.GorefServer over a store.Store. The
+// contents of files are read from the corpora they belong to.
+type StoreServer struct {
+	corpora []goref.Corpus
+	store   store.Store
+}
+
+// NewStoreServer returns a StoreServer that serves the references of
+// st, and the files of corpora.
+func NewStoreServer(corpora []goref.Corpus, st store.Store) *StoreServer {
+	return &StoreServer{
+		corpora: corpora,
+		store:   st,
+	}
+}
+
+func (s *StoreServer) GetAnnotations(ctx context.Context, req *pb.GetAnnotationsRequest) (*pb.GetAnnotationsResponse, error) {
+	fpath := req.Path
+	_, err := findCorpus(s.corpora, fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := store.NewPage(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	refs, next, err := s.store.RefsToFile(ctx, fpath, p)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetAnnotationsResponse{
+		Path:          fpath,
+		Annotation:    refs,
+		NextPageToken: next,
+	}, nil
+}
+
+func (s *StoreServer) GetFiles(ctx context.Context, req *pb.GetFilesRequest) (*pb.GetFilesResponse, error) {
+	p, err := store.NewPage(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	files, next, err := s.store.PackageFiles(ctx, req.Package, p)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetFilesResponse{
+		Package:       req.Package,
+		Filename:      files,
+		NextPageToken: next,
+	}, nil
+}
+
+func (s *StoreServer) GetPackages(ctx context.Context, req *pb.GetPackagesRequest) (*pb.GetPackagesResponse, error) {
+	p, err := store.NewPage(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	packages, next, err := s.store.ListPackages(ctx, req.Prefix, p)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetPackagesResponse{
+		Package:       packages,
+		NextPageToken: next,
+	}, nil
+}
+
+func (s *StoreServer) FindReferences(ctx context.Context, req *pb.FindReferencesRequest) (*pb.FindReferencesResponse, error) {
+	exists, err := packageExists(ctx, s.store, req.Package)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUnknownPackage
+	}
+
+	var refs []*gorefpb.Ref
+	if req.Ident == "" {
+		refs, err = s.store.RefsToPackage(ctx, req.Package)
+	} else {
+		refs, err = s.store.RefsToIdent(ctx, req.Package, req.Ident)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewFindReferencesResponse(req, refs), nil
+}
+
+func (s *StoreServer) GetDefinition(ctx context.Context, req *pb.GetDefinitionRequest) (*pb.GetDefinitionResponse, error) {
+	if _, err := findCorpus(s.corpora, req.Path); err != nil {
+		return nil, err
+	}
+
+	refs, err := s.store.RefsFromFile(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if def := DefinitionFrom(refs, req.Path, req.Line, req.Col); def != nil {
+		return &pb.GetDefinitionResponse{Definition: def}, nil
+	}
+
+	// Stores only have Refs between packages, so the only
+	// definitions within the file's package that can be resolved
+	// are declarations that other packages refer to.
+	p := store.Page{Size: store.MaxPageSize}
+	for {
+		refs, next, err := s.store.RefsToFile(ctx, req.Path, p)
+		if err != nil {
+			return nil, err
+		}
+		if def := DefinitionTo(refs, req.Path, req.Line, req.Col); def != nil {
+			return &pb.GetDefinitionResponse{Definition: def}, nil
+		}
+		if next == "" {
+			return &pb.GetDefinitionResponse{}, nil
+		}
+		p.Token = next
+	}
+}
+
+func (s *StoreServer) GetImplementations(ctx context.Context, req *pb.GetImplementationsRequest) (*pb.GetImplementationsResponse, error) {
+	exists, err := packageExists(ctx, s.store, req.Package)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUnknownPackage
+	}
+	refsTo := func(loadpath, ident string) ([]*gorefpb.Ref, error) {
+		return s.store.RefsToIdent(ctx, loadpath, ident)
+	}
+	impls, err := FindImplementations(req.Package, req.Iface, req.Transitive, refsTo)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetImplementationsResponse{Implementation: impls}, nil
+}
+
+func (s *StoreServer) GetInterfaces(ctx context.Context, req *pb.GetInterfacesRequest) (*pb.GetInterfacesResponse, error) {
+	exists, err := packageExists(ctx, s.store, req.Package)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUnknownPackage
+	}
+	refsFrom := func(loadpath, ident string) ([]*gorefpb.Ref, error) {
+		return s.store.RefsFromIdent(ctx, loadpath, ident)
+	}
+	ifaces, err := FindInterfaces(req.Package, req.Type, req.Transitive, refsFrom)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetInterfacesResponse{Interface: ifaces}, nil
+}
+
+func (s *StoreServer) SearchSymbols(ctx context.Context, req *pb.SearchSymbolsRequest) (*pb.SearchSymbolsResponse, error) {
+	q, err := SymbolsQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.store.SearchSymbols(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return NewSearchSymbolsResponse(results), nil
+}
+
+func (s *StoreServer) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFileResponse, error) {
+	fpath := req.Path
+	corpus, err := findCorpus(s.corpora, fpath)
+	if err != nil {
+		return nil, err
+	}
+	if f, err := ioutil.ReadFile(corpus.Abs(fpath)); err == nil {
+		return &pb.GetFileResponse{
+			Path:     fpath,
+			Contents: string(f),
+		}, nil
+	}
+	return nil, fmt.Errorf("Internal server error")
+}
@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/symbols"
+)
+
+// SymbolsQuery returns the symbols.Query of a SearchSymbolsRequest. It
+// returns an error if the request's limit is negative or if one of
+// its kinds isn't a kind of declaration.
+func SymbolsQuery(req *pb.SearchSymbolsRequest) (symbols.Query, error) {
+	if req.Limit < 0 {
+		return symbols.Query{}, fmt.Errorf("Invalid limit %d", req.Limit)
+	}
+	for _, k := range req.Kinds {
+		if _, err := goref.ParseDeclKind(k); err != nil {
+			return symbols.Query{}, err
+		}
+	}
+	return symbols.Query{
+		Text:          req.Query,
+		Kinds:         req.Kinds,
+		PackagePrefix: req.PackagePrefix,
+		Limit:         int(req.Limit),
+	}, nil
+}
+
+// NewSearchSymbolsResponse returns the response to a
+// SearchSymbolsRequest from its ranked results.
+func NewSearchSymbolsResponse(results []*symbols.Result) *pb.SearchSymbolsResponse {
+	res := &pb.SearchSymbolsResponse{}
+	for _, r := range results {
+		res.Symbol = append(res.Symbol, &pb.Symbol{
+			Location: r.Location,
+			Kind:     r.Kind,
+			RefCount: int64(r.Refs),
+			Match:    r.Match.String(),
+		})
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/cmd/serve/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolsQuery(t *testing.T) {
+	q, err := rpc.SymbolsQuery(&pb.SearchSymbolsRequest{Query: "npg", Kinds: []string{"func", "type"}, PackagePrefix: "p", Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "npg", q.Text)
+	assert.Equal(t, []string{"func", "type"}, q.Kinds)
+	assert.Equal(t, "p", q.PackagePrefix)
+	assert.Equal(t, 3, q.Limit)
+
+	_, err = rpc.SymbolsQuery(&pb.SearchSymbolsRequest{Limit: -1})
+	assert.Error(t, err)
+	_, err = rpc.SymbolsQuery(&pb.SearchSymbolsRequest{Kinds: []string{"function"}})
+	assert.Error(t, err)
+}
+
+func TestSearchSymbols(t *testing.T) {
+	graph, st, cleanup := servers(t, false)
+	defer cleanup()
+
+	for _, s := range []pb.GorefServer{graph, st} {
+		res, err := s.SearchSymbols(context.Background(), &pb.SearchSymbolsRequest{
+			Query:         "IfaceLib",
+			Kinds:         []string{"type"},
+			PackagePrefix: libpath,
+		})
+		assert.NoError(t, err)
+		// IfaceLibA has the most Refs, from the types that
+		// implement it and the interfaces that extend it.
+		idents := make([]string, 0)
+		for _, sym := range res.Symbol {
+			assert.Equal(t, "prefix", sym.Match)
+			assert.Equal(t, libpath, sym.Location.Package)
+			idents = append(idents, sym.Location.Ident)
+		}
+		assert.Equal(t, []string{"IfaceLibA", "IfaceLibB", "IfaceLibAB"}, idents)
+		assert.EqualValues(t, 7, res.Symbol[0].RefCount)
+
+		_, err = s.SearchSymbols(context.Background(), &pb.SearchSymbolsRequest{Kinds: []string{"function"}})
+		assert.Error(t, err)
+	}
+}
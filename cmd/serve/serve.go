@@ -1,22 +1,22 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
-	"fmt"
-	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
-	"path/filepath"
+	"strings"
 
 	"golang.org/x/net/context"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/korfuri/goref"
 	pb "github.com/korfuri/goref/cmd/serve/proto"
+	"github.com/korfuri/goref/cmd/serve/rpc"
 	gorefelastic "github.com/korfuri/goref/elasticsearch"
-	gorefpb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/bolt"
+	"github.com/korfuri/goref/store/sqlite"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 	elastic "gopkg.in/olivere/elastic.v5"
@@ -28,136 +28,23 @@ const (
 )
 
 var (
+	storeSpec = flag.String("store", "elastic",
+		"Where references are read from: elastic, for the ElasticSearch cluster configured by the elastic_* flags, "+
+			"sqlite:<path> for a SQLite database or bolt:<path> for a bbolt database, as built by index -store.")
 	elasticURL = flag.String("elastic_url", "http://localhost:9200",
 		"URL of the ElasticSearch cluster.")
 	elasticUsername = flag.String("elastic_user", "elastic",
 		"Username to authenticate with ElasticSearch.")
 	elasticPassword = flag.String("elastic_password", "changeme",
 		"Password to authenticate with ElasticSearch.")
+	elasticVersion = flag.Int("elastic_version", 5,
+		"Major version of the ElasticSearch cluster, as used by index -elastic_version.")
 	elasticIndex = flag.String("elastic_index", "goref",
-		"Name of the index to use in ElasticSearch.")
+		"Name of the index to use in ElasticSearch. This may be the alias maintained by index -reindex, "+
+			"so that reindexing is never visible half-done.")
 )
 
-// server implements pb.GorefServer
-type server struct {
-	corpora []goref.Corpus
-	client  *elastic.Client
-}
-
-func (s server) GetAnnotations(ctx context.Context, req *pb.GetAnnotationsRequest) (*pb.GetAnnotationsResponse, error) {
-	fpath := req.Path
-	_, err := s.findCorpus(fpath)
-	if err != nil {
-		return nil, err
-	}
-
-	termQuery := elastic.NewTermQuery("to.position.filename.keyword", fpath)
-	action := s.client.Search().
-		Index(*elasticIndex).
-		Query(termQuery).
-		Type(gorefelastic.RefType).
-		From(0).Size(1000).
-		Pretty(false)
-	searchResult, err := action.Do(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	res := &pb.GetAnnotationsResponse{
-		Path: fpath,
-	}
-	for _, hit := range searchResult.Hits.Hits {
-		var r gorefpb.Ref
-		json.Unmarshal(*hit.Source, &r)
-		res.Annotation = append(res.Annotation, &r)
-	}
-
-	return res, nil
-}
-
-func (s server) GetFiles(ctx context.Context, req *pb.GetFilesRequest) (*pb.GetFilesResponse, error) {
-	termQuery := elastic.NewTermQuery("package.keyword", req.Package)
-	action := s.client.Search().
-		Index(*elasticIndex).
-		Query(termQuery).
-		Type(gorefelastic.FileType).
-		From(0).Size(1000).
-		Pretty(false)
-	searchResult, err := action.Do(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	res := &pb.GetFilesResponse{
-		Package: req.Package,
-	}
-	for _, hit := range searchResult.Hits.Hits {
-		var f gorefelastic.File
-		json.Unmarshal(*hit.Source, &f)
-		res.Filename = append(res.Filename, f.Filename)
-	}
-
-	return res, nil
-}
-
-func (s server) GetPackages(ctx context.Context, req *pb.GetPackagesRequest) (*pb.GetPackagesResponse, error) {
-	action := s.client.Search().
-		Index(*elasticIndex).
-		Type(gorefelastic.PackageType).
-		From(0).Size(1000).
-		Pretty(false)
-	if req.Prefix != "" {
-		query := elastic.NewPrefixQuery("loadpath.keyword", req.Prefix)
-		action = action.Query(query)
-	}
-	searchResult, err := action.Do(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	res := &pb.GetPackagesResponse{}
-	for _, hit := range searchResult.Hits.Hits {
-		var p goref.Package
-		json.Unmarshal(*hit.Source, &p)
-		res.Package = append(res.Package, p.Path)
-	}
-
-	return res, nil
-}
-
-func (s server) findCorpus(fpath string) (goref.Corpus, error) {
-	if filepath.Ext(fpath) != ".go" {
-		return goref.Corpus(""), fmt.Errorf("Not found: invalid extension")
-	}
-	var corpus goref.Corpus
-	for _, c := range s.corpora {
-		if c.ContainsRel(fpath) {
-			corpus = c
-			break
-		}
-	}
-	if corpus == "" {
-		return goref.Corpus(""), fmt.Errorf("Not found under any corpus")
-	}
-	return corpus, nil
-}
-
-func (s server) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFileResponse, error) {
-	fpath := req.Path
-	corpus, err := s.findCorpus(fpath)
-	if err != nil {
-		return nil, err
-	}
-	if f, err := ioutil.ReadFile(corpus.Abs(fpath)); err == nil {
-		return &pb.GetFileResponse{
-			Path:     fpath,
-			Contents: string(f),
-		}, nil
-	}
-	return nil, fmt.Errorf("Internal server error")
-}
-
-func runGRPC(s *server, grpcReady chan struct{}) error {
+func runGRPC(s pb.GorefServer, grpcReady chan struct{}) error {
 	lis, err := net.Listen("tcp", grpcListenAddr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
@@ -190,19 +77,51 @@ func runGateway(grpcReady <-chan struct{}) error {
 	return http.ListenAndServe(gatewayListenAddr, mux)
 }
 
+// openStore opens the store selected by -store.
+func openStore() store.Store {
+	if strings.HasPrefix(*storeSpec, "sqlite:") {
+		path := strings.TrimPrefix(*storeSpec, "sqlite:")
+		st, err := sqlite.Open(path)
+		if err != nil {
+			log.Fatalf("Couldn't open %s: %s", path, err)
+		}
+		return st
+	}
+	if strings.HasPrefix(*storeSpec, "bolt:") {
+		// The database is memory-mapped, so it's served without
+		// loading it first.
+		path := strings.TrimPrefix(*storeSpec, "bolt:")
+		st, err := bolt.OpenReadOnly(path)
+		if err != nil {
+			log.Fatalf("Couldn't open %s: %s", path, err)
+		}
+		return st
+	}
+	if *storeSpec != "elastic" {
+		log.Fatalf("Unsupported store %q", *storeSpec)
+	}
+	var client gorefelastic.Client
+	switch {
+	case *elasticVersion == 5:
+		ec, err := elastic.NewClient(
+			elastic.SetURL(*elasticURL),
+			elastic.SetBasicAuth(*elasticUsername, *elasticPassword))
+		if err != nil {
+			panic(err)
+		}
+		client = gorefelastic.NewClient(ec, *elasticIndex)
+	case *elasticVersion >= 7:
+		client = gorefelastic.NewTypelessClient(*elasticURL, *elasticUsername, *elasticPassword, *elasticIndex)
+	default:
+		log.Fatalf("Unsupported ElasticSearch version %d", *elasticVersion)
+	}
+	return gorefelastic.NewStore(client, gorefelastic.DefaultBulkConfig)
+}
+
 func main() {
 	flag.Parse()
 	grpcReady := make(chan struct{})
-	ec, err := elastic.NewClient(
-		elastic.SetURL(*elasticURL),
-		elastic.SetBasicAuth(*elasticUsername, *elasticPassword))
-	if err != nil {
-		panic(err)
-	}
+	s := rpc.NewStoreServer(goref.DefaultCorpora(), openStore())
 	go runGateway(grpcReady)
-	s := &server{
-		corpora: goref.DefaultCorpora(),
-		client:  ec,
-	}
 	runGRPC(s, grpcReady)
 }
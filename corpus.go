@@ -76,7 +76,13 @@ func (c Corpus) Rel(fpath string) string {
 // DefaultCorpora returns the set of default corpora based on GOROOT
 // and GOPATH.
 func DefaultCorpora() []Corpus {
-	srcdirs := build.Default.SrcDirs()
+	return corporaForContext(&build.Default)
+}
+
+// corporaForContext returns the set of corpora based on the GOROOT
+// and GOPATH of a go/build context.
+func corporaForContext(ctxt *build.Context) []Corpus {
+	srcdirs := ctxt.SrcDirs()
 	corpora := make([]Corpus, len(srcdirs))
 	for n, s := range srcdirs {
 		corpora[n] = Corpus(s)
@@ -0,0 +1,298 @@
+package goref
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// DeclKind is an enum of the kinds of package-level declarations
+// (and their methods) that goref knows about.
+type DeclKind int
+
+// These are the possible kinds of declarations.
+const (
+	// FuncDecl is a package-level function.
+	FuncDecl DeclKind = iota
+
+	// MethodDecl is a method declared on a named type.
+	MethodDecl
+
+	// TypeDecl is a named type.
+	TypeDecl
+
+	// VarDecl is a package-level variable.
+	VarDecl
+
+	// ConstDecl is a package-level constant.
+	ConstDecl
+
+	// FieldDecl is a field of a named struct type.
+	FieldDecl
+)
+
+func (dk DeclKind) String() string {
+	switch dk {
+	case FuncDecl:
+		return "func"
+	case MethodDecl:
+		return "method"
+	case TypeDecl:
+		return "type"
+	case VarDecl:
+		return "var"
+	case ConstDecl:
+		return "const"
+	case FieldDecl:
+		return "field"
+	}
+	panic("Unknown DeclKind used")
+}
+
+// MarshalJSON implements encoding/json.Marshaler interface
+func (dk DeclKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dk.String())
+}
+
+// ParseDeclKind returns the DeclKind whose name is s, e.g. "func".
+func ParseDeclKind(s string) (DeclKind, error) {
+	for dk := FuncDecl; dk <= FieldDecl; dk++ {
+		if dk.String() == s {
+			return dk, nil
+		}
+	}
+	return FuncDecl, fmt.Errorf("Unknown declaration kind %s", s)
+}
+
+// A Decl is an identifier declared in a Package: a package-level
+// func, type, var or const, or a method or field of a named type.
+// Methods of a named interface are MethodDecls of that interface.
+type Decl struct {
+	// Kind of declaration
+	Kind DeclKind
+
+	// Name of the declared identifier
+	Name string
+
+	// Recv is the name of the receiver's type for methods, or of
+	// the struct type for fields. It is empty for other kinds of
+	// declarations.
+	Recv string
+
+	// Package that contains this declaration
+	Package *Package
+
+	// Position of the declared identifier. This is the same
+	// Position that Refs to this declaration use as their
+	// ToPosition.
+	Position Position
+
+	// Extent is the Position of the whole declaration, from its
+	// keyword (or its name for fields and interface methods) to
+	// its end. Doc comments are not part of the extent. It is
+	// empty if the package's files were not loaded, e.g. for
+	// packages excluded by the graph's filterF.
+	Extent Position
+
+	// Object is the types.Object for this declaration. It is nil
+	// for declarations set with Package.SetDecls.
+	Object types.Object
+}
+
+// Ident returns the identifier for this declaration, qualified by its
+// receiver type for methods and fields (e.g. "Type.Method").
+func (d *Decl) Ident() string {
+	if d.Recv != "" {
+		return d.Recv + "." + d.Name
+	}
+	return d.Name
+}
+
+// Exported returns whether this declaration is exported.
+func (d *Decl) Exported() bool {
+	return ast.IsExported(d.Name)
+}
+
+func (d *Decl) String() string {
+	return fmt.Sprintf("%s %s.%s", d.Kind, d.Package.Path, d.Ident())
+}
+
+// Decls returns all declarations in this package, sorted by position.
+// For packages without type information, it returns the declarations
+// set with SetDecls, if any.
+func (p *Package) Decls() []*Decl {
+	if p.Types == nil || p.Fset == nil {
+		return p.decls
+	}
+	decls := make([]*Decl, 0)
+	newDecl := func(kind DeclKind, recv string, obj types.Object) *Decl {
+		return &Decl{
+			Kind:     kind,
+			Name:     obj.Name(),
+			Recv:     recv,
+			Package:  p,
+			Position: NewPosition(p.Corpus, p.Fset, obj.Pos(), NoPos),
+			Extent:   p.extents[obj.Pos()],
+			Object:   obj,
+		}
+	}
+	// Struct types may share their underlying struct with other
+	// named types (e.g. `type A B`). fields maps each field to
+	// the struct type declared closest before it, which is the
+	// type whose declaration contains that field.
+	fields := make(map[*types.Var]*types.TypeName)
+	scope := p.Types.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.Func:
+			decls = append(decls, newDecl(FuncDecl, "", obj))
+		case *types.Var:
+			decls = append(decls, newDecl(VarDecl, "", obj))
+		case *types.Const:
+			decls = append(decls, newDecl(ConstDecl, "", obj))
+		case *types.TypeName:
+			decls = append(decls, newDecl(TypeDecl, "", obj))
+			if named, ok := obj.Type().(*types.Named); ok && !obj.IsAlias() {
+				for i := 0; i < named.NumMethods(); i++ {
+					decls = append(decls, newDecl(MethodDecl, obj.Name(), named.Method(i)))
+				}
+				if iface, ok := named.Underlying().(*types.Interface); ok {
+					for i := 0; i < iface.NumExplicitMethods(); i++ {
+						decls = append(decls, newDecl(MethodDecl, obj.Name(), iface.ExplicitMethod(i)))
+					}
+				}
+				if st, ok := named.Underlying().(*types.Struct); ok {
+					for i := 0; i < st.NumFields(); i++ {
+						f := st.Field(i)
+						if f.Pkg() != p.Types || f.Pos() < obj.Pos() {
+							continue
+						}
+						if t, in := fields[f]; !in || t.Pos() < obj.Pos() {
+							fields[f] = obj
+						}
+					}
+				}
+			}
+		}
+	}
+	for f, t := range fields {
+		decls = append(decls, newDecl(FieldDecl, t.Name(), f))
+	}
+	sortDecls(decls)
+	return decls
+}
+
+// SetDecls sets the declarations of a package that has no type
+// information, e.g. a package read from an export, so that Decls
+// returns them.
+func (p *Package) SetDecls(decls []*Decl) {
+	sortDecls(decls)
+	p.decls = decls
+}
+
+// sortDecls sorts declarations by package load path and position.
+func sortDecls(decls []*Decl) {
+	sort.Slice(decls, func(i, j int) bool {
+		if decls[i].Package.Path != decls[j].Package.Path {
+			return decls[i].Package.Path < decls[j].Package.Path
+		}
+		a, b := decls[i].Position, decls[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.PosL != b.PosL {
+			return a.PosL < b.PosL
+		}
+		return a.PosC < b.PosC
+	})
+}
+
+// InRefsTo returns the Refs in this package's InRefs that point to
+// the provided declaration.
+func (p *Package) InRefsTo(d *Decl) []*Ref {
+	return refsTo(p.InRefs, d)
+}
+
+// LocalRefsTo returns the Refs in this package's LocalRefs that point
+// to the provided declaration.
+func (p *Package) LocalRefsTo(d *Decl) []*Ref {
+	return refsTo(p.LocalRefs, d)
+}
+
+// refsTo returns the Refs among refs that point to the provided
+// declaration.
+func refsTo(in []*Ref, d *Decl) []*Ref {
+	refs := make([]*Ref, 0)
+	for _, r := range in {
+		if r.ToPosition == d.Position && r.ToIdent == d.Name {
+			refs = append(refs, r)
+		}
+	}
+	return refs
+}
+
+// EnclosingDecl returns the innermost declaration of this package
+// whose Extent contains the provided Position, or nil.
+func (p *Package) EnclosingDecl(pos Position) *Decl {
+	return enclosingDecl(p.Decls(), pos)
+}
+
+// enclosingDecl returns the innermost declaration among decls whose
+// Extent contains the provided Position, or nil.
+func enclosingDecl(decls []*Decl, pos Position) *Decl {
+	var enclosing *Decl
+	for _, d := range decls {
+		if d.Extent.Contains(pos) && (enclosing == nil || enclosing.Extent.Contains(d.Extent)) {
+			enclosing = d
+		}
+	}
+	return enclosing
+}
+
+// addExtents records the extent of all declarations of a file in
+// extents, keyed by the Pos of the declared identifiers.
+func addExtents(extents map[token.Pos]Position, corpus Corpus, fset *token.FileSet, f *ast.File) {
+	add := func(names []*ast.Ident, pos, end token.Pos) {
+		for _, n := range names {
+			extents[n.Pos()] = NewPosition(corpus, fset, pos, end)
+		}
+	}
+	addFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			add(field.Names, field.Pos(), field.End())
+		}
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			add([]*ast.Ident{decl.Name}, decl.Pos(), decl.End())
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// Without parentheses, the declaration
+				// starts at its keyword.
+				pos, end := spec.Pos(), spec.End()
+				if !decl.Lparen.IsValid() {
+					pos, end = decl.Pos(), decl.End()
+				}
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					add(spec.Names, pos, end)
+				case *ast.TypeSpec:
+					add([]*ast.Ident{spec.Name}, pos, end)
+					switch t := spec.Type.(type) {
+					case *ast.StructType:
+						addFields(t.Fields)
+					case *ast.InterfaceType:
+						addFields(t.Methods)
+					}
+				}
+			}
+		}
+	}
+}
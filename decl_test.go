@@ -0,0 +1,18 @@
+package goref_test
+
+import (
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeclKind(t *testing.T) {
+	for dk := goref.FuncDecl; dk <= goref.FieldDecl; dk++ {
+		parsed, err := goref.ParseDeclKind(dk.String())
+		assert.NoError(t, err)
+		assert.Equal(t, dk, parsed)
+	}
+	_, err := goref.ParseDeclKind("interface")
+	assert.Error(t, err)
+}
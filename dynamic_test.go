@@ -0,0 +1,88 @@
+package goref_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+// dynamicCalls returns the DynamicCall Refs from a package, as
+// "from.pkg:line -> to.pkg.Recv.Method" strings.
+func dynamicCalls(p *goref.Package) []string {
+	calls := make([]string, 0)
+	for _, r := range p.OutRefs {
+		if r.RefType != goref.DynamicCall {
+			continue
+		}
+		for _, d := range r.ToPackage.Decls() {
+			if d.Position == r.ToPosition {
+				calls = append(calls, fmt.Sprintf("%s:%d -> %s.%s", p.Name, r.FromPosition.PosL, r.ToPackage.Name, d.Ident()))
+			}
+		}
+	}
+	return calls
+}
+
+func TestDynamicCalls(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/dynamic"
+		libpath = pkgpath + "/lib"
+	)
+
+	// main.go:19 calls Area on a lib.Square stored in a lib.Shape,
+	// and lib.go:27 calls Area on a lib.Square and a main.triangle.
+	for _, tc := range []struct {
+		algo goref.CallGraphAlgorithm
+		main []string
+		lib  []string
+	}{
+		{
+			// CHA considers all implementations of lib.Shape.
+			algo: goref.CHA,
+			main: []string{"main:19 -> main.triangle.Area", "main:19 -> lib.Square.Area", "main:19 -> lib.Circle.Area"},
+			lib:  []string{"lib:27 -> main.triangle.Area", "lib:27 -> lib.Square.Area", "lib:27 -> lib.Circle.Area"},
+		},
+		{
+			// RTA excludes lib.Circle, which is never
+			// instantiated.
+			algo: goref.RTA,
+			main: []string{"main:19 -> main.triangle.Area", "main:19 -> lib.Square.Area"},
+			lib:  []string{"lib:27 -> main.triangle.Area", "lib:27 -> lib.Square.Area"},
+		},
+		{
+			// VTA knows that only a lib.Square flows to
+			// main.go:19.
+			algo: goref.VTA,
+			main: []string{"main:19 -> lib.Square.Area"},
+			lib:  []string{"lib:27 -> main.triangle.Area", "lib:27 -> lib.Square.Area"},
+		},
+	} {
+		pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+		pg.SetCallGraph(tc.algo)
+		assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+		assert.ElementsMatch(t, tc.main, dynamicCalls(pg.Packages[pkgpath]), tc.algo.String())
+		assert.ElementsMatch(t, tc.lib, dynamicCalls(pg.Packages[libpath]), tc.algo.String())
+	}
+}
+
+func TestDynamicCalls_disabled(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/dynamic"
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	for _, p := range pg.Packages {
+		assert.Empty(t, dynamicCalls(p))
+	}
+}
+
+func TestParseCallGraphAlgorithm(t *testing.T) {
+	for _, algo := range []goref.CallGraphAlgorithm{goref.NoCallGraph, goref.CHA, goref.RTA, goref.VTA} {
+		parsed, err := goref.ParseCallGraphAlgorithm(algo.String())
+		assert.NoError(t, err)
+		assert.Equal(t, algo, parsed)
+	}
+	_, err := goref.ParseCallGraphAlgorithm("pointer")
+	assert.Error(t, err)
+}
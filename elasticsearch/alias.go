@@ -0,0 +1,97 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	// Format of the timestamps in the names of indices created
+	// for an alias. Timestamps in this format sort like the times
+	// they represent.
+	indexTimestampFormat = "20060102150405"
+)
+
+// TimestampedIndex returns the name of a fresh index to be built for
+// the provided alias at time t, e.g. "goref-20170102150405".
+func TimestampedIndex(alias string, t time.Time) string {
+	return alias + "-" + t.UTC().Format(indexTimestampFormat)
+}
+
+// timestampedIndices returns the indices that were created for an
+// alias with TimestampedIndex, sorted from the oldest to the most
+// recent.
+func timestampedIndices(ctx context.Context, client Client, alias string) ([]string, error) {
+	indices, err := client.ListIndices(ctx, alias+"-")
+	if err != nil {
+		return nil, err
+	}
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(alias) + `-\d{14}$`)
+	timestamped := make([]string, 0, len(indices))
+	for _, index := range indices {
+		if re.MatchString(index) {
+			timestamped = append(timestamped, index)
+		}
+	}
+	sort.Strings(timestamped)
+	return timestamped, nil
+}
+
+// SwapAlias atomically points an alias to target, so that readers of
+// the alias go from seeing the previous index to seeing target in
+// full. The client's index must be the alias. It returns the index
+// that the alias designated before, or "" if the alias is new.
+//
+// The previous index is not deleted, so that RollbackAlias can point
+// the alias back to it.
+func SwapAlias(ctx context.Context, client Client, alias, target string) (string, error) {
+	previous, err := client.AliasTarget(ctx)
+	if err != nil {
+		return "", err
+	}
+	if previous == "" {
+		// The alias can't be created if there's an index with
+		// the same name.
+		exists, err := client.IndexExists(ctx)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return "", fmt.Errorf("%s is an index, not an alias", alias)
+		}
+	}
+	if err := client.SetAliasTarget(ctx, target); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+// RollbackAlias points an alias back to the index that was created
+// for it before the one it currently designates, and returns that
+// index. The client's index must be the alias. Calling it again rolls
+// back further.
+func RollbackAlias(ctx context.Context, client Client, alias string) (string, error) {
+	current, err := client.AliasTarget(ctx)
+	if err != nil {
+		return "", err
+	}
+	if current == "" {
+		return "", fmt.Errorf("%s is not an alias", alias)
+	}
+	indices, err := timestampedIndices(ctx, client, alias)
+	if err != nil {
+		return "", err
+	}
+	i := sort.SearchStrings(indices, current)
+	if i == 0 {
+		return "", fmt.Errorf("No index older than %s to roll back to", current)
+	}
+	previous := indices[i-1]
+	if err := client.SetAliasTarget(ctx, previous); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
@@ -0,0 +1,88 @@
+package elasticsearch_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/elasticsearch"
+	"github.com/korfuri/goref/elasticsearch/estest"
+	"github.com/korfuri/goref/elasticsearch/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTimestampedIndex(t *testing.T) {
+	ts := time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, "goref-20170102150405", elasticsearch.TimestampedIndex("goref", ts))
+	assert.Equal(t, "goref-20170102150405", elasticsearch.TimestampedIndex("goref", ts.In(time.FixedZone("X", 3600))))
+}
+
+func TestSwapAlias_typeless(t *testing.T) {
+	s := estest.NewServer()
+	defer s.Close()
+	ctx := context.Background()
+	alias := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+
+	// Builds an index with a single package at the provided
+	// version.
+	build := func(name string, version int64) {
+		client := elasticsearch.NewTypelessClient(s.URL, "", "", name)
+		assert.NoError(t, elasticsearch.EnsureIndex(ctx, client))
+		assert.NoError(t, client.CreatePackage(ctx, &goref.Package{Path: "a", Version: version}))
+	}
+	visible := func() []string {
+		return s.Documents("goref-package")
+	}
+
+	first := "goref-20170101000000"
+	build(first, 1)
+	previous, err := elasticsearch.SwapAlias(ctx, alias, "goref", first)
+	assert.NoError(t, err)
+	assert.Equal(t, "", previous)
+	assert.Equal(t, []string{"v1@1@a"}, visible())
+
+	// The alias keeps pointing to the first index while the
+	// second one is built.
+	second := "goref-20170102000000"
+	build(second, 2)
+	assert.Equal(t, []string{"v1@1@a"}, visible())
+	previous, err = elasticsearch.SwapAlias(ctx, alias, "goref", second)
+	assert.NoError(t, err)
+	assert.Equal(t, first, previous)
+	assert.Equal(t, []string{"v1@2@a"}, visible())
+	target, err := alias.AliasTarget(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, second, target)
+
+	// Documents can be read and written through the alias.
+	exists, err := elasticsearch.PackageExists("a", 2, alias)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, elasticsearch.EnsureIndex(ctx, alias))
+
+	// Indices that weren't built for the alias are ignored by
+	// rollbacks.
+	build("goref-other", 3)
+	target, err = elasticsearch.RollbackAlias(ctx, alias, "goref")
+	assert.NoError(t, err)
+	assert.Equal(t, first, target)
+	assert.Equal(t, []string{"v1@1@a"}, visible())
+	_, err = elasticsearch.RollbackAlias(ctx, alias, "goref")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"v1@1@a"}, visible())
+}
+
+func TestSwapAlias_notAnAlias(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("AliasTarget", mock.Anything).Return("", nil)
+	client.On("IndexExists", mock.Anything).Return(true, nil)
+
+	_, err := elasticsearch.SwapAlias(context.Background(), client, "goref", "goref-20170101000000")
+	assert.EqualError(t, err, "goref is an index, not an alias")
+	client.AssertNotCalled(t, "SetAliasTarget", mock.Anything, mock.Anything)
+
+	_, err = elasticsearch.RollbackAlias(context.Background(), client, "goref")
+	assert.EqualError(t, err, "goref is not an alias")
+}
@@ -2,8 +2,15 @@ package elasticsearch
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/symbols"
 	elastic "gopkg.in/olivere/elastic.v5"
 )
 
@@ -12,6 +19,7 @@ const (
 	PackageType = "package"
 	RefType     = "ref"
 	FileType    = "file"
+	SymbolType  = "symbol"
 )
 
 // Client is an abstraction over the underlying elastic.Client that
@@ -29,12 +37,154 @@ type Client interface {
 
 	// CreateRef creates a goref.Ref entry in the index.
 	CreateRef(ctx context.Context, r *goref.Ref) (*elastic.IndexResponse, error)
+
+	// Search returns the documents of the provided type that
+	// match query, or all of them if query is nil. The first
+	// `from` matching documents are skipped, and at most `size`
+	// are returned.
+	Search(ctx context.Context, typ string, query elastic.Query, from, size int) (*elastic.SearchHits, error)
+
+	// SearchAfter returns at most size documents of the provided
+	// type that match query, or all of them if query is nil,
+	// sorted in ascending order by the provided fields and then by
+	// a tiebreaker that makes the order total. If after is set,
+	// only the documents that sort after these sort values are
+	// returned. The sort values of each document are in the Sort
+	// of its SearchHit, and numbers may be json.Numbers so that
+	// large integers are exact.
+	SearchAfter(ctx context.Context, typ string, query elastic.Query, sort []string, after []interface{}, size int) (*elastic.SearchHits, error)
+
+	// NewBulk starts a Bulk to index Files and goref.Refs in
+	// batches.
+	NewBulk(ctx context.Context, config BulkConfig) (Bulk, error)
+
+	// IndexExists returns whether the index exists.
+	IndexExists(ctx context.Context) (bool, error)
+
+	// CreateIndex creates the index with the provided settings
+	// and mappings.
+	CreateIndex(ctx context.Context, body map[string]interface{}) error
+
+	// GetMapping returns the index's mappings, by type.
+	GetMapping(ctx context.Context) (map[string]interface{}, error)
+
+	// PackageVersions returns the versions of each package in the
+	// index, by load path.
+	PackageVersions(ctx context.Context) (map[string][]int64, error)
+
+	// DeletePackageVersions deletes the provided versions of a
+	// package, with their Files and goref.Refs, and returns the
+	// number of deleted documents.
+	DeletePackageVersions(ctx context.Context, loadpath string, versions []int64) (int64, error)
+
+	// AliasTarget returns the name of the index that the client's
+	// index designates if it's an alias, or "" if it isn't one.
+	AliasTarget(ctx context.Context) (string, error)
+
+	// SetAliasTarget atomically points the alias named after the
+	// client's index to the target index, and removes it from the
+	// index it designated before, if any.
+	SetAliasTarget(ctx context.Context, target string) error
+
+	// ListIndices returns the names of the indices that start
+	// with prefix.
+	ListIndices(ctx context.Context, prefix string) ([]string, error)
 }
 
-// File represents a mapping of a file in a package
+// BulkConfig configures how a Bulk batches documents.
+type BulkConfig struct {
+	// BatchSize is the number of documents sent in each bulk
+	// request. DefaultBulkConfig's is used if it's less than 1.
+	BatchSize int
+
+	// FlushInterval is how long documents may wait before being
+	// sent, even if the batch isn't full. Zero disables periodic
+	// flushing.
+	FlushInterval time.Duration
+
+	// Workers is the number of bulk requests that may be in
+	// flight at the same time.
+	Workers int
+}
+
+// DefaultBulkConfig is a BulkConfig suitable for most clusters.
+var DefaultBulkConfig = BulkConfig{
+	BatchSize:     1000,
+	FlushInterval: 5 * time.Second,
+	Workers:       2,
+}
+
+// A BulkFailure is a document that a Bulk failed to index.
+type BulkFailure struct {
+	// Doc is the File or *goref.Ref that wasn't indexed.
+	Doc interface{}
+
+	// Err is the reason why the document wasn't indexed.
+	Err error
+}
+
+// Bulk indexes documents asynchronously, in batches.
+type Bulk interface {
+	// AddFile queues a File entry to be indexed.
+	AddFile(f File)
+
+	// AddRef queues a goref.Ref entry to be indexed.
+	AddRef(r *goref.Ref)
+
+	// AddSymbol queues a Symbol entry to be indexed.
+	AddSymbol(s Symbol)
+
+	// Close sends all queued documents, waits for all bulk
+	// requests to complete and returns the documents that
+	// couldn't be indexed.
+	Close() ([]BulkFailure, error)
+}
+
+// File represents a mapping of a file in a package. Package is the
+// package's load path.
 type File struct {
 	Filename string `json:"filename"`
 	Package  string `json:"package"`
+	Version  int64  `json:"version"`
+}
+
+// DocumentID returns a consistent id for this file at this version,
+// so that indexing it again overwrites the same document.
+func (f File) DocumentID() string {
+	// "v1" is a prefix to recognize this DocumentID format, in
+	// case the format changes in the future.
+	return fmt.Sprintf("v1@%d@%s", f.Version, f.Filename)
+}
+
+// Symbol represents an identifier declared in a package. Package is
+// the package's load path, and Ident is qualified by its receiver
+// type for methods and fields. Initials are the symbols.Initials of
+// Ident, which camelCase queries are matched against.
+type Symbol struct {
+	Package  string       `json:"package"`
+	Version  int64        `json:"version"`
+	Ident    string       `json:"ident"`
+	Kind     string       `json:"kind"`
+	Initials string       `json:"initials"`
+	Position *pb.Position `json:"position"`
+}
+
+// NewSymbol returns the Symbol of a declaration.
+func NewSymbol(d *goref.Decl) Symbol {
+	return Symbol{
+		Package:  d.Package.Path,
+		Version:  d.Package.Version,
+		Ident:    d.Ident(),
+		Kind:     d.Kind.String(),
+		Initials: symbols.Initials(d.Ident()),
+		Position: d.Position.ToProto(),
+	}
+}
+
+// DocumentID returns a consistent id for this symbol at this version,
+// so that indexing it again overwrites the same document.
+func (s Symbol) DocumentID() string {
+	return fmt.Sprintf("v1@%d@%s@%s", s.Version, s.Package, s.Ident)
 }
 
 // clientImpl implements Client
@@ -76,6 +226,7 @@ func (c clientImpl) CreateFile(ctx context.Context, entry File) (*elastic.IndexR
 	return c.client.Index().
 		Index(c.index).
 		Type(FileType).
+		Id(entry.DocumentID()).
 		BodyJson(entry).
 		Do(ctx)
 }
@@ -85,6 +236,279 @@ func (c clientImpl) CreateRef(ctx context.Context, r *goref.Ref) (*elastic.Index
 	return c.client.Index().
 		Index(c.index).
 		Type(RefType).
+		Id(r.DocumentID()).
 		BodyJson(r).
 		Do(ctx)
 }
+
+// Search implements Client for clientImpl
+func (c clientImpl) Search(ctx context.Context, typ string, query elastic.Query, from, size int) (*elastic.SearchHits, error) {
+	action := c.client.Search().
+		Index(c.index).
+		Type(typ).
+		From(from).Size(size).
+		Pretty(false)
+	if query != nil {
+		action = action.Query(query)
+	}
+	res, err := action.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Hits, nil
+}
+
+// SearchAfter implements Client for clientImpl. Documents with the
+// same sort values are sorted by their _uid.
+func (c clientImpl) SearchAfter(ctx context.Context, typ string, query elastic.Query, sort []string, after []interface{}, size int) (*elastic.SearchHits, error) {
+	sorters := make([]elastic.Sorter, 0, len(sort)+1)
+	for _, field := range sort {
+		sorters = append(sorters, elastic.SortInfo{Field: field, Ascending: true})
+	}
+	sorters = append(sorters, elastic.SortInfo{Field: "_uid", Ascending: true})
+	action := c.client.Search().
+		Index(c.index).
+		Type(typ).
+		Size(size).
+		SortBy(sorters...).
+		Pretty(false)
+	if query != nil {
+		action = action.Query(query)
+	}
+	if after != nil {
+		action = action.SearchAfter(after...)
+	}
+	res, err := action.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Hits, nil
+}
+
+// IndexExists implements Client for clientImpl
+func (c clientImpl) IndexExists(ctx context.Context) (bool, error) {
+	return c.client.IndexExists(c.index).Do(ctx)
+}
+
+// CreateIndex implements Client for clientImpl
+func (c clientImpl) CreateIndex(ctx context.Context, body map[string]interface{}) error {
+	_, err := c.client.CreateIndex(c.index).
+		BodyJson(body).
+		Do(ctx)
+	return err
+}
+
+// GetMapping implements Client for clientImpl
+func (c clientImpl) GetMapping(ctx context.Context) (map[string]interface{}, error) {
+	res, err := c.client.GetMapping().
+		Index(c.index).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The response is keyed by the name of the index, which
+	// differs from c.index if c.index is an alias.
+	for _, index := range res {
+		index, _ := index.(map[string]interface{})
+		mappings, _ := index["mappings"].(map[string]interface{})
+		return mappings, nil
+	}
+	return nil, fmt.Errorf("No mapping found for index %s", c.index)
+}
+
+// PackageVersions implements Client for clientImpl
+func (c clientImpl) PackageVersions(ctx context.Context) (map[string][]int64, error) {
+	versions := make(map[string][]int64)
+	scroll := c.client.Scroll(c.index).
+		Type(PackageType).
+		Size(scrollSize)
+	defer scroll.Clear(ctx)
+	for {
+		res, err := scroll.Do(ctx)
+		if err == io.EOF {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range res.Hits.Hits {
+			var p goref.Package
+			if err := json.Unmarshal(*hit.Source, &p); err != nil {
+				return nil, err
+			}
+			versions[p.Path] = append(versions[p.Path], p.Version)
+		}
+	}
+}
+
+// DeletePackageVersions implements Client for clientImpl
+func (c clientImpl) DeletePackageVersions(ctx context.Context, loadpath string, versions []int64) (int64, error) {
+	var deleted int64
+	for _, typ := range deletionOrder {
+		res, err := c.client.DeleteByQuery(c.index).
+			Type(typ).
+			Query(versionsQuery(typ, loadpath, versions)).
+			ProceedOnVersionConflict().
+			Do(ctx)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += res.Deleted
+	}
+	return deleted, nil
+}
+
+// AliasTarget implements Client for clientImpl
+func (c clientImpl) AliasTarget(ctx context.Context) (string, error) {
+	res, err := c.client.Aliases().
+		Index(c.index).
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, index := range res.IndicesByAlias(c.index) {
+		return index, nil
+	}
+	return "", nil
+}
+
+// SetAliasTarget implements Client for clientImpl
+func (c clientImpl) SetAliasTarget(ctx context.Context, target string) error {
+	previous, err := c.AliasTarget(ctx)
+	if err != nil {
+		return err
+	}
+	action := c.client.Alias()
+	if previous != "" {
+		action = action.Remove(previous, c.index)
+	}
+	_, err = action.
+		Add(target, c.index).
+		Do(ctx)
+	return err
+}
+
+// ListIndices implements Client for clientImpl
+func (c clientImpl) ListIndices(ctx context.Context, prefix string) ([]string, error) {
+	res, err := c.client.IndexGetSettings(prefix + "*").
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]string, 0, len(res))
+	for index := range res {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// NewBulk implements Client for clientImpl
+func (c clientImpl) NewBulk(ctx context.Context, config BulkConfig) (Bulk, error) {
+	b := &bulkImpl{
+		index:    c.index,
+		docs:     make(map[elastic.BulkableRequest]interface{}),
+		failures: make([]BulkFailure, 0),
+	}
+	batchSize := config.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultBulkConfig.BatchSize
+	}
+	p, err := c.client.BulkProcessor().
+		Name("goref").
+		BulkActions(batchSize).
+		FlushInterval(config.FlushInterval).
+		Workers(config.Workers).
+		After(b.after).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.processor = p
+	return b, nil
+}
+
+// bulkImpl implements Bulk on top of an elastic.BulkProcessor
+type bulkImpl struct {
+	processor *elastic.BulkProcessor
+	index     string
+
+	// mu protects docs and failures, which are updated by the
+	// processor's workers.
+	mu sync.Mutex
+
+	// docs maps each pending request to the document it
+	// indexes, so that failed requests can be reported.
+	docs     map[elastic.BulkableRequest]interface{}
+	failures []BulkFailure
+}
+
+// add queues a document of the provided type and ID.
+func (b *bulkImpl) add(typ, id string, doc interface{}) {
+	req := elastic.NewBulkIndexRequest().
+		Index(b.index).
+		Type(typ).
+		Id(id).
+		Doc(doc)
+	b.mu.Lock()
+	b.docs[req] = doc
+	b.mu.Unlock()
+	b.processor.Add(req)
+}
+
+// AddFile implements Bulk for bulkImpl
+func (b *bulkImpl) AddFile(f File) {
+	b.add(FileType, f.DocumentID(), f)
+}
+
+// AddRef implements Bulk for bulkImpl
+func (b *bulkImpl) AddRef(r *goref.Ref) {
+	b.add(RefType, r.DocumentID(), r)
+}
+
+// AddSymbol implements Bulk for bulkImpl
+func (b *bulkImpl) AddSymbol(s Symbol) {
+	b.add(SymbolType, s.DocumentID(), s)
+}
+
+// after is called by the processor after each bulk request. Items
+// of the response are in the same order as the requests.
+func (b *bulkImpl) after(executionID int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, req := range requests {
+		itemErr := err
+		if itemErr == nil && response != nil && i < len(response.Items) {
+			for _, item := range response.Items[i] {
+				if item.Error != nil {
+					itemErr = fmt.Errorf("%s: %s", item.Error.Type, item.Error.Reason)
+				}
+			}
+		}
+		if itemErr != nil {
+			b.failures = append(b.failures, BulkFailure{
+				Doc: b.docs[req],
+				Err: itemErr,
+			})
+		}
+		delete(b.docs, req)
+	}
+}
+
+// Close implements Bulk for bulkImpl. Documents that were never
+// sent are reported as failures.
+func (b *bulkImpl) Close() ([]BulkFailure, error) {
+	err := b.processor.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, doc := range b.docs {
+		b.failures = append(b.failures, BulkFailure{
+			Doc: doc,
+			Err: fmt.Errorf("Document was not sent: %v", err),
+		})
+	}
+	b.docs = make(map[elastic.BulkableRequest]interface{})
+	return b.failures, err
+}
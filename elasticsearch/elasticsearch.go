@@ -2,86 +2,101 @@ package elasticsearch
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/store"
 	log "github.com/sirupsen/logrus"
+	elastic "gopkg.in/olivere/elastic.v5"
 )
 
 const (
-	// Max number of errors reported in one call to
-	// LoadGraphToElastic
+	// Max number of errors listed in the error returned by
+	// PutPackages, which counts all of them
 	maxErrorsReported = 20
 )
 
-// PackageExists returns whether the provided loadpath + version tuple
-// exists in this index.
-func PackageExists(loadpath string, version int64, client Client) bool {
-	ctx := context.Background()
-	docID := fmt.Sprintf("v1@%d@%s", version, loadpath)
-	pkgDoc, _ := client.GetPackage(ctx, docID)
-	// TODO: handle errors better. Right now we assume that any
-	// error is a 404 and can be ignored safely.
-	return pkgDoc != nil
+// RetryConfig configures how requests that fail with a transient
+// error are retried.
+type RetryConfig struct {
+	// Attempts is the maximum number of times a request is sent.
+	Attempts int
+
+	// InitialBackoff is how long to wait before the first retry.
+	// The wait is doubled after each retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
 }
 
-// LoadGraphToElastic loads all Packages and Refs from a PackageGraph
-// to the provided ES index.
-func LoadGraphToElastic(pg goref.PackageGraph, client Client) error {
-	ctx := context.Background()
-	missedRefs := make([]*goref.Ref, 0)
-	missedFiles := make([]string, 0)
-	errs := make([]error, 0)
+// Retry configures how PackageExists retries transient errors.
+var Retry = RetryConfig{
+	Attempts:       5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
 
-	for _, p := range pg.Packages {
-		if PackageExists(p.Path, p.Version, client) {
-			log.Infof("Package %s already exists in this index.", p)
-			continue
-		}
+// isTransient returns whether an error may go away if the request is
+// sent again: network errors, and the errors ElasticSearch returns
+// when it's overloaded or failing.
+func isTransient(err error) bool {
+	switch e := err.(type) {
+	case *elastic.Error:
+		return e.Status == http.StatusTooManyRequests || e.Status >= 500
+	case *url.Error, net.Error:
+		return true
+	}
+	return false
+}
 
-		log.Debugf("Creating Package %s in the index", p)
-		if err := client.CreatePackage(ctx, p); err != nil {
-			return err
-		}
+// PackageExists returns whether the provided loadpath + version tuple
+// exists in this index. A package that isn't found isn't an error.
+// Transient errors are retried with exponential backoff as configured
+// by Retry, and returned if all attempts fail.
+func PackageExists(loadpath string, version int64, client Client) (bool, error) {
+	return packageExists(context.Background(), loadpath, version, client)
+}
 
-		for _, f := range p.Files {
-			entry := File{
-				Filename: f,
-				Package:  p.Name,
-			}
-			refDoc, err := client.CreateFile(ctx, entry)
-			if err != nil {
-				missedFiles = append(missedFiles, f)
-				errs = append(errs, err)
-				log.Debugf("Create file document failed with err:[%s] for file:[%s]", err, f)
-			} else {
-				log.Debugf("Created file document with docID:[%s] for file:[%s]", refDoc.Id, f)
-			}
+// packageExists is PackageExists with a context. Requests aren't
+// retried once ctx is done.
+func packageExists(ctx context.Context, loadpath string, version int64, client Client) (bool, error) {
+	docID := fmt.Sprintf("v1@%d@%s", version, loadpath)
+	backoff := Retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		pkgDoc, err := client.GetPackage(ctx, docID)
+		if err == nil {
+			return pkgDoc != nil, nil
 		}
-
-		for _, r := range p.OutRefs {
-			refDoc, err := client.CreateRef(ctx, r)
-			if err != nil {
-				missedRefs = append(missedRefs, r)
-				errs = append(errs, err)
-				log.Debugf("Create Ref document failed with err:[%s] for Ref:[%s]", err, r)
-			} else {
-				log.Debugf("Created Ref document with docID:[%s] for Ref:[%s]", refDoc.Id, r)
-			}
+		if elastic.IsNotFound(err) {
+			return false, nil
 		}
-	}
-	if len(errs) > 0 {
-		errStr := fmt.Sprintf("%d entries couldn't be imported. Errors were:\n", len(errs))
-		c := 0
-		for _, e := range errs {
-			errStr = errStr + e.Error() + "\n"
-			c = c + 1
-			if c >= maxErrorsReported {
-				break
-			}
+		if ctx.Err() != nil || !isTransient(err) || attempt >= Retry.Attempts {
+			return false, fmt.Errorf("Couldn't check whether %s@%d is indexed after %d attempts: %s", loadpath, version, attempt, err)
+		}
+		log.Debugf("Retrying to check whether %s@%d is indexed in %s: %s", loadpath, version, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return false, fmt.Errorf("Couldn't check whether %s@%d is indexed after %d attempts: %s", loadpath, version, attempt, ctx.Err())
+		}
+		if backoff *= 2; backoff > Retry.MaxBackoff {
+			backoff = Retry.MaxBackoff
 		}
-		return errors.New(errStr)
 	}
-	return nil
+}
+
+// LoadGraphToElastic loads all Packages and Refs from a PackageGraph
+// to the provided ES index. Files and Refs are sent in batches as
+// configured by config.
+//
+// All documents have deterministic IDs, and a Package's document is
+// only created once all its Files and Refs were indexed. This makes
+// it safe to run LoadGraphToElastic again after a partial failure or
+// a crash: packages that weren't fully indexed are indexed again,
+// overwriting the documents that did get in.
+func LoadGraphToElastic(pg goref.PackageGraph, client Client, config BulkConfig) error {
+	return store.LoadGraph(context.Background(), NewStore(client, config), pg)
 }
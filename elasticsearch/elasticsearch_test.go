@@ -1,10 +1,15 @@
 package elasticsearch_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/korfuri/goref"
 	"github.com/korfuri/goref/elasticsearch"
@@ -14,20 +19,118 @@ import (
 	elastic "gopkg.in/olivere/elastic.v5"
 )
 
+// notFound is the error returned by GetPackage for packages that
+// don't exist.
+var notFound = &elastic.Error{Status: http.StatusNotFound}
+
+// refused is the error returned by requests to a cluster that can't be
+// reached.
+var refused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+func init() {
+	// Retries are fast in tests.
+	elasticsearch.Retry.InitialBackoff = time.Millisecond
+	elasticsearch.Retry.MaxBackoff = 2 * time.Millisecond
+}
+
 func TestPackageExists(t *testing.T) {
 	client := &mocks.Client{}
 	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(
 		&elastic.GetResult{}, nil)
-	assert.True(t, elasticsearch.PackageExists("fmt", 1, client))
+	exists, err := elasticsearch.PackageExists("fmt", 1, client)
+	assert.NoError(t, err)
+	assert.True(t, exists)
 	client.On("GetPackage", mock.Anything, "v1@2@log").Return(
-		nil, errors.New("not found"))
-	assert.False(t, elasticsearch.PackageExists("log", 2, client))
+		nil, notFound)
+	exists, err = elasticsearch.PackageExists("log", 2, client)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestPackageExists_retries(t *testing.T) {
+	client := &mocks.Client{}
+	// Transient errors are retried until they go away...
+	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(
+		nil, refused).Once()
+	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(
+		nil, &url.Error{Op: "Get", URL: "http://localhost:9200", Err: refused}).Once()
+	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(
+		nil, &elastic.Error{Status: http.StatusServiceUnavailable}).Once()
+	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(
+		&elastic.GetResult{}, nil).Once()
+	exists, err := elasticsearch.PackageExists("fmt", 1, client)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	client.AssertNumberOfCalls(t, "GetPackage", 4)
+
+	// ... or until all attempts failed.
+	client = &mocks.Client{}
+	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(
+		nil, refused)
+	_, err = elasticsearch.PackageExists("fmt", 1, client)
+	assert.EqualError(t, err, "Couldn't check whether fmt@1 is indexed after 5 attempts: dial tcp: connection refused")
+	client.AssertNumberOfCalls(t, "GetPackage", elasticsearch.Retry.Attempts)
+
+	// Other errors are not retried.
+	for _, e := range []error{&elastic.Error{Status: http.StatusForbidden}, errors.New("invalid character")} {
+		client = &mocks.Client{}
+		client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(nil, e)
+		_, err = elasticsearch.PackageExists("fmt", 1, client)
+		assert.Error(t, err)
+		client.AssertNumberOfCalls(t, "GetPackage", 1)
+	}
+}
+
+func TestPackageExists_canceled(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("GetPackage", mock.Anything, "v1@1@fmt").Return(nil, refused)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := elasticsearch.NewStore(client, elasticsearch.DefaultBulkConfig).PackageExists(ctx, "fmt", 1)
+	assert.Error(t, err)
+	client.AssertNumberOfCalls(t, "GetPackage", 1)
+}
+
+func TestLoadGraphToElastic_unreachable(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/simple"
+
+	client := &mocks.Client{}
+	client.On("GetPackage", mock.Anything, mock.Anything).Return(nil, refused)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+
+	assert.Error(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
+	client.AssertNotCalled(t, "NewBulk", mock.Anything, mock.Anything)
+	client.AssertNotCalled(t, "CreatePackage", mock.Anything, mock.Anything)
+}
+
+// newBulk returns a mock Bulk that reports the documents for which
+// fail returns an error as failures when closed.
+func newBulk(fail func(doc interface{}) error) *mocks.Bulk {
+	bulk := &mocks.Bulk{}
+	failures := make([]elasticsearch.BulkFailure, 0)
+	record := func(args mock.Arguments) {
+		if err := fail(args.Get(0)); err != nil {
+			failures = append(failures, elasticsearch.BulkFailure{Doc: args.Get(0), Err: err})
+		}
+	}
+	bulk.On("AddFile", mock.Anything).Run(record)
+	bulk.On("AddRef", mock.Anything).Run(record)
+	bulk.On("AddSymbol", mock.Anything).Run(record)
+	bulk.On("Close").Return(func() []elasticsearch.BulkFailure { return failures }, nil)
+	return bulk
+}
+
+// succeed is a failure predicate for newBulk that never fails.
+func succeed(interface{}) error {
+	return nil
 }
 
 func TestLoadGraphToElastic_emptyGraph(t *testing.T) {
 	pg := goref.PackageGraph{}
 	client := &mocks.Client{}
-	assert.NoError(t, elasticsearch.LoadGraphToElastic(pg, client))
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(pg, client, elasticsearch.DefaultBulkConfig))
 }
 
 func TestLoadGraphToElastic_allPkgsExist(t *testing.T) {
@@ -40,7 +143,7 @@ func TestLoadGraphToElastic_allPkgsExist(t *testing.T) {
 	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
 	pg.LoadPackages([]string{pkgpath}, false)
 
-	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client))
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
 }
 
 func TestLoadGraphToElastic_somePkgsDontExist(t *testing.T) {
@@ -56,18 +159,24 @@ func TestLoadGraphToElastic_somePkgsDontExist(t *testing.T) {
 	// fmt. Let's say they already exist.
 	client.On("GetPackage", mock.Anything, mock.MatchedBy(func(x string) bool { return (x != simple && x != fmt) })).Return(&elastic.GetResult{}, nil)
 	// fmt and simple don't exist for this test.
-	client.On("GetPackage", mock.Anything, simple).Return(nil, errors.New("not found"))
-	client.On("GetPackage", mock.Anything, fmt).Return(nil, errors.New("not found"))
+	client.On("GetPackage", mock.Anything, simple).Return(nil, notFound)
+	client.On("GetPackage", mock.Anything, fmt).Return(nil, notFound)
 
 	// Creating packages, files and refs always works
 	client.On("CreatePackage", mock.Anything, mock.Anything).Times(2).Return(nil)
-	client.On("CreateFile", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
-	client.On("CreateRef", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
+	bulk := newBulk(succeed)
+	client.On("NewBulk", mock.Anything, elasticsearch.DefaultBulkConfig).Return(bulk, nil)
 
 	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
 	pg.LoadPackages([]string{pkgpath}, false)
 
-	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client))
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
+	bulk.AssertCalled(t, "AddFile", elasticsearch.File{
+		Filename: "github.com/korfuri/goref/testprograms/simple/main.go",
+		Package:  pkgpath,
+	})
+	bulk.AssertNumberOfCalls(t, "AddRef", len(pg.Packages[pkgpath].OutRefs)+len(pg.Packages["fmt"].OutRefs))
+	bulk.AssertNumberOfCalls(t, "Close", 1)
 }
 
 func TestLoadGraphToElastic_pkgFailsToInsert(t *testing.T) {
@@ -80,8 +189,8 @@ func TestLoadGraphToElastic_pkgFailsToInsert(t *testing.T) {
 	client := &mocks.Client{}
 
 	// fmt and simple don't exist for this test.
-	client.On("GetPackage", mock.Anything, main).Return(nil, errors.New("not found"))
-	client.On("GetPackage", mock.Anything, lib).Return(nil, errors.New("not found"))
+	client.On("GetPackage", mock.Anything, main).Return(nil, notFound)
+	client.On("GetPackage", mock.Anything, lib).Return(nil, notFound)
 
 	// Creating packages, files and refs always works, except to
 	// create lib.
@@ -96,13 +205,12 @@ func TestLoadGraphToElastic_pkgFailsToInsert(t *testing.T) {
 	client.On("CreatePackage", mock.Anything, mock.MatchedBy(matchMain)).Return(nil)
 
 	// Files and refs are created without issues
-	client.On("CreateFile", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
-	client.On("CreateRef", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
+	client.On("NewBulk", mock.Anything, mock.Anything).Return(newBulk(succeed), nil)
 
 	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
 	pg.LoadPackages([]string{pkgpath}, false)
 
-	assert.Error(t, elasticsearch.LoadGraphToElastic(*pg, client))
+	assert.Error(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
 }
 
 func TestLoadGraphToElastic_fileFailsToInsert(t *testing.T) {
@@ -115,8 +223,8 @@ func TestLoadGraphToElastic_fileFailsToInsert(t *testing.T) {
 	client := &mocks.Client{}
 
 	// fmt and simple don't exist for this test.
-	client.On("GetPackage", mock.Anything, main).Return(nil, errors.New("not found"))
-	client.On("GetPackage", mock.Anything, lib).Return(nil, errors.New("not found"))
+	client.On("GetPackage", mock.Anything, main).Return(nil, notFound)
+	client.On("GetPackage", mock.Anything, lib).Return(nil, notFound)
 
 	// Creating packages, files and refs always works, except to
 	// create lib's file.
@@ -124,20 +232,28 @@ func TestLoadGraphToElastic_fileFailsToInsert(t *testing.T) {
 	matchLibGo := func(f elasticsearch.File) bool {
 		return f.Filename == "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go"
 	}
-	matchNotLibGo := func(f elasticsearch.File) bool {
-		return !matchLibGo(f)
-	}
 
-	client.On("CreateFile", mock.Anything, mock.MatchedBy(matchNotLibGo)).Return(&elastic.IndexResponse{}, nil)
-	client.On("CreateFile", mock.Anything, mock.MatchedBy(matchLibGo)).Return(nil, errors.New("cannot create file lib.go"))
-	client.On("CreateRef", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
+	client.On("NewBulk", mock.Anything, mock.Anything).Return(newBulk(func(doc interface{}) error {
+		if f, ok := doc.(elasticsearch.File); ok && matchLibGo(f) {
+			return errors.New("cannot create file lib.go")
+		}
+		return nil
+	}), nil)
 
 	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
 	pg.LoadPackages([]string{pkgpath}, false)
 
-	err := elasticsearch.LoadGraphToElastic(*pg, client)
+	err := elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig)
 	assert.Error(t, err)
 	assert.Equal(t, "1 entries couldn't be imported. Errors were:\ncannot create file lib.go\n", err.Error())
+
+	// lib isn't marked as indexed, so that the next run indexes
+	// it again.
+	isPkg := func(docID string) interface{} {
+		return mock.MatchedBy(func(p *goref.Package) bool { return p.DocumentID() == docID })
+	}
+	client.AssertCalled(t, "CreatePackage", mock.Anything, isPkg(main))
+	client.AssertNotCalled(t, "CreatePackage", mock.Anything, isPkg(lib))
 }
 
 func TestLoadGraphToElastic_refFailsToInsert(t *testing.T) {
@@ -150,27 +266,27 @@ func TestLoadGraphToElastic_refFailsToInsert(t *testing.T) {
 	client := &mocks.Client{}
 
 	// fmt and simple don't exist for this test.
-	client.On("GetPackage", mock.Anything, main).Return(nil, errors.New("not found"))
-	client.On("GetPackage", mock.Anything, lib).Return(nil, errors.New("not found"))
+	client.On("GetPackage", mock.Anything, main).Return(nil, notFound)
+	client.On("GetPackage", mock.Anything, lib).Return(nil, notFound)
 
 	// Creating packages, files and refs always works, except to
 	// create main's outrefs to lib.
 	client.On("CreatePackage", mock.Anything, mock.Anything).Return(nil)
-	client.On("CreateFile", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
 	matchLibToMain := func(r *goref.Ref) bool {
 		return (r.FromPackage.DocumentID() == main &&
 			r.ToPackage.DocumentID() == lib)
 	}
-	matchNotLibToMain := func(r *goref.Ref) bool {
-		return !matchLibToMain(r)
-	}
-	client.On("CreateRef", mock.Anything, mock.MatchedBy(matchNotLibToMain)).Return(&elastic.IndexResponse{}, nil)
-	client.On("CreateRef", mock.Anything, mock.MatchedBy(matchLibToMain)).Return(nil, errors.New("cannot create ref"))
+	client.On("NewBulk", mock.Anything, mock.Anything).Return(newBulk(func(doc interface{}) error {
+		if r, ok := doc.(*goref.Ref); ok && matchLibToMain(r) {
+			return errors.New("cannot create ref")
+		}
+		return nil
+	}), nil)
 
 	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
 	pg.LoadPackages([]string{pkgpath}, false)
 
-	err := elasticsearch.LoadGraphToElastic(*pg, client)
+	err := elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig)
 	assert.Error(t, err)
 	assert.Equal(t, "2 entries couldn't be imported. Errors were:\ncannot create ref\ncannot create ref\n", err.Error())
 }
@@ -182,15 +298,19 @@ func TestLoadGraphToElastic_allRefsFailToInsert(t *testing.T) {
 
 	client := &mocks.Client{}
 
-	client.On("GetPackage", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+	client.On("GetPackage", mock.Anything, mock.Anything).Return(nil, notFound)
 	client.On("CreatePackage", mock.Anything, mock.Anything).Return(nil)
-	client.On("CreateFile", mock.Anything, mock.Anything).Return(&elastic.IndexResponse{}, nil)
-	client.On("CreateRef", mock.Anything, mock.Anything).Return(nil, errors.New("cannot create ref"))
+	client.On("NewBulk", mock.Anything, mock.Anything).Return(newBulk(func(doc interface{}) error {
+		if _, ok := doc.(*goref.Ref); ok {
+			return errors.New("cannot create ref")
+		}
+		return nil
+	}), nil)
 
 	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
 	pg.LoadPackages([]string{pkgpath}, false)
 
-	err := elasticsearch.LoadGraphToElastic(*pg, client)
+	err := elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig)
 	assert.Error(t, err)
 	// Errors are capped at 20 reported in the error message
 	assert.Contains(t, err.Error(), "entries couldn't be imported. Errors were:")
@@ -201,3 +321,27 @@ func TestLoadGraphToElastic_allRefsFailToInsert(t *testing.T) {
 	// There's an extra \n due to the leading message.
 	assert.Equal(t, 21, len(r.FindAllStringIndex(err.Error(), -1)))
 }
+
+func TestLoadGraphToElastic_bulkFailsToStart(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/simple"
+
+	client := &mocks.Client{}
+	client.On("GetPackage", mock.Anything, mock.Anything).Return(nil, notFound)
+	client.On("NewBulk", mock.Anything, mock.Anything).Return(nil, errors.New("cannot start bulk"))
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+
+	err := elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig)
+	assert.EqualError(t, err, "cannot start bulk")
+	client.AssertNotCalled(t, "CreatePackage", mock.Anything, mock.Anything)
+}
+
+func TestFileDocumentID(t *testing.T) {
+	f := elasticsearch.File{
+		Filename: "github.com/korfuri/goref/ref.go",
+		Package:  "github.com/korfuri/goref",
+		Version:  42,
+	}
+	assert.Equal(t, "v1@42@github.com/korfuri/goref/ref.go", f.DocumentID())
+}
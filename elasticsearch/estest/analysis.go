@@ -0,0 +1,179 @@
+package estest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/korfuri/goref/symbols"
+)
+
+// analyzers maps the names of the analyzers that goref defines to
+// functions that split text into the same terms. The analyzers are
+// emulated rather than built from the index settings, as their
+// patterns aren't valid Go regular expressions.
+var analyzers = map[string]func(string) []string{
+	"goref_ident": func(s string) []string {
+		words := symbols.Words(s)
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		return words
+	},
+	"goref_trigram": func(s string) []string {
+		return symbols.Trigrams(strings.ToLower(s))
+	},
+	// Prefixes of at most 50 characters of the identifier and of
+	// its suffixes after each dot.
+	"goref_prefix": func(s string) []string {
+		prefixes := make([]string, 0)
+		rs := []rune(strings.ToLower(s))
+		for start := 0; start < len(rs); start++ {
+			if start > 0 && rs[start-1] != '.' {
+				continue
+			}
+			for end := start + 1; end <= len(rs) && end-start <= 50; end++ {
+				prefixes = append(prefixes, string(rs[start:end]))
+			}
+		}
+		return prefixes
+	},
+	"goref_lowercase": func(s string) []string {
+		return []string{strings.ToLower(s)}
+	},
+}
+
+// analyzers returns the names of the analyzers of a dotted field of
+// the index for documents and for queries, or "" if the field isn't
+// analyzed with a custom analyzer.
+func (idx *index) analyzers(name string) (string, string) {
+	mapping, _ := idx.mappings.(map[string]interface{})
+	for _, key := range strings.Split(name, ".") {
+		properties, ok := mapping["properties"].(map[string]interface{})
+		if !ok {
+			properties, _ = mapping["fields"].(map[string]interface{})
+		}
+		mapping, _ = properties[key].(map[string]interface{})
+	}
+	a, _ := mapping["analyzer"].(string)
+	if search, ok := mapping["search_analyzer"].(string); ok {
+		return a, search
+	}
+	return a, a
+}
+
+// matchesText returns whether a decoded document of the index
+// matches a match query. Each term of the query matches if the field
+// has a term within the query's fuzziness, and the document matches
+// if at least minimum_should_match terms match, or one by default.
+func (idx *index) matchesText(doc interface{}, body map[string]interface{}) (bool, error) {
+	for name, param := range body {
+		a, search := idx.analyzers(name)
+		analyze, ok := analyzers[a]
+		analyzeQuery, qok := analyzers[search]
+		if !ok || !qok {
+			return false, fmt.Errorf("estest doesn't support match queries on %s", name)
+		}
+		options, ok := param.(map[string]interface{})
+		if !ok {
+			options = map[string]interface{}{"query": param}
+		}
+		v, ok := field(doc, name)
+		if !ok {
+			return false, nil
+		}
+		required := 1
+		if m, in := options["minimum_should_match"]; in {
+			n, err := strconv.Atoi(fmt.Sprint(m))
+			if err != nil {
+				return false, fmt.Errorf("estest doesn't support minimum_should_match %v", m)
+			}
+			required = n
+		}
+		terms := analyze(fmt.Sprint(v))
+		matched := 0
+		for _, q := range analyzeQuery(fmt.Sprint(options["query"])) {
+			edits, err := fuzziness(options["fuzziness"], q)
+			if err != nil {
+				return false, err
+			}
+			for _, t := range terms {
+				if distance(q, t) <= edits {
+					matched++
+					break
+				}
+			}
+		}
+		return matched > 0 && matched >= required, nil
+	}
+	return false, nil
+}
+
+// fuzziness returns the number of edits allowed for a term of a match
+// query, which is a number or "AUTO".
+func fuzziness(f interface{}, term string) (int, error) {
+	switch s := fmt.Sprint(f); {
+	case f == nil:
+		return 0, nil
+	case s == "AUTO":
+		return symbols.MaxEdits(term), nil
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("estest doesn't support fuzziness %v", f)
+		}
+		return n, nil
+	}
+}
+
+// distance returns the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters that turn a into b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = d[i-1][j-1] + cost
+			if d[i-1][j]+1 < d[i][j] {
+				d[i][j] = d[i-1][j] + 1
+			}
+			if d[i][j-1]+1 < d[i][j] {
+				d[i][j] = d[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// wildcard returns a regular expression equivalent to the pattern of
+// a wildcard query, in which * matches any sequence of characters and
+// ? any single character.
+func wildcard(pattern string) *regexp.Regexp {
+	var re []string
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			re = append(re, ".*")
+		case '?':
+			re = append(re, ".")
+		default:
+			re = append(re, regexp.QuoteMeta(string(r)))
+		}
+	}
+	return regexp.MustCompile("(?s)^" + strings.Join(re, "") + "$")
+}
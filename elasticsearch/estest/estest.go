@@ -0,0 +1,368 @@
+// Package estest provides an in-process stand-in for the subset of the
+// ElasticSearch 7 REST API that goref uses, for tests. Documents are
+// kept in memory and served over an httptest.Server.
+package estest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// index is an index of the Server.
+type index struct {
+	settings interface{}
+	mappings interface{}
+
+	// docs maps document IDs to their source.
+	docs map[string]json.RawMessage
+}
+
+// A Server is an in-memory ElasticSearch stand-in. Its URL can be
+// used to create clients, e.g. with elasticsearch.NewTypelessClient.
+type Server struct {
+	*httptest.Server
+
+	// mu protects indices and aliases.
+	mu      sync.Mutex
+	indices map[string]*index
+
+	// aliases maps aliases to the index they designate.
+	aliases map[string]string
+}
+
+// NewServer starts a Server with no indices. It should be closed with
+// Close when done.
+func NewServer() *Server {
+	s := &Server{
+		indices: make(map[string]*index),
+		aliases: make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// resolve returns the name of the index designated by name, which
+// may be an alias.
+func (s *Server) resolve(name string) string {
+	if target, in := s.aliases[name]; in {
+		return target
+	}
+	return name
+}
+
+// Documents returns the IDs of the documents of an index, sorted.
+func (s *Server) Documents(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0)
+	if idx, in := s.indices[s.resolve(name)]; in {
+		for id := range idx.docs {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Document returns the source of a document, or nil if it doesn't
+// exist.
+func (s *Server) Document(name, id string) json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if idx, in := s.indices[s.resolve(name)]; in {
+		return idx.docs[id]
+	}
+	return nil
+}
+
+// writeJSON writes a JSON response with the provided status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an ElasticSearch error response.
+func writeError(w http.ResponseWriter, status int, typ, reason string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"type":   typ,
+			"reason": reason,
+		},
+		"status": status,
+	})
+}
+
+// splitPath splits the escaped path of a request into its unescaped
+// components, so that document IDs may contain slashes.
+func splitPath(r *http.Request) []string {
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	for i, p := range parts {
+		if u, err := url.PathUnescape(p); err == nil {
+			parts[i] = u
+		}
+	}
+	return parts
+}
+
+// serveHTTP dispatches requests to the supported APIs.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := splitPath(r)
+	if len(parts) > 0 && !strings.HasPrefix(parts[0], "_") {
+		parts[0] = s.resolve(parts[0])
+	}
+	switch {
+	case len(parts) == 1 && parts[0] == "_bulk" && r.Method == "POST":
+		s.bulk(w, r)
+	case len(parts) == 1 && parts[0] == "_aliases" && r.Method == "POST":
+		s.updateAliases(w, r)
+	case len(parts) == 2 && parts[0] == "_alias" && r.Method == "GET":
+		s.getAlias(w, parts[1])
+	case len(parts) == 1 && r.Method == "HEAD":
+		if _, in := s.indices[parts[0]]; in {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case len(parts) == 1 && r.Method == "PUT":
+		s.createIndex(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "_mapping" && r.Method == "GET":
+		idx, in := s.indices[parts[0]]
+		if !in {
+			writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+parts[0]+"]")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			parts[0]: map[string]interface{}{"mappings": idx.mappings},
+		})
+	case len(parts) == 2 && parts[1] == "_settings" && r.Method == "GET":
+		s.getSettings(w, parts[0])
+	case len(parts) == 2 && parts[1] == "_search" && (r.Method == "GET" || r.Method == "POST"):
+		s.search(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "_delete_by_query" && r.Method == "POST":
+		s.deleteByQuery(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "_doc" && r.Method == "GET":
+		s.getDocument(w, parts[0], parts[2])
+	case len(parts) == 3 && parts[1] == "_doc" && (r.Method == "PUT" || r.Method == "POST"):
+		var doc json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+			return
+		}
+		result := s.putDocument(parts[0], parts[2], doc)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"_index": parts[0],
+			"_id":    parts[2],
+			"result": result,
+		})
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_operation_exception",
+			fmt.Sprintf("estest doesn't support %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// createIndex creates an index from a body with settings and
+// mappings.
+func (s *Server) createIndex(w http.ResponseWriter, r *http.Request, name string) {
+	if _, in := s.indices[name]; in {
+		writeError(w, http.StatusBadRequest, "resource_already_exists_exception", "index ["+name+"] already exists")
+		return
+	}
+	var body struct {
+		Settings interface{} `json:"settings"`
+		Mappings interface{} `json:"mappings"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+		return
+	}
+	s.indices[name] = &index{
+		settings: body.Settings,
+		mappings: body.Mappings,
+		docs:     make(map[string]json.RawMessage),
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"acknowledged": true,
+		"index":        name,
+	})
+}
+
+// getDocument writes a document, or a 404 if it doesn't exist.
+func (s *Server) getDocument(w http.ResponseWriter, name, id string) {
+	idx, in := s.indices[name]
+	if !in {
+		writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+name+"]")
+		return
+	}
+	doc, in := idx.docs[id]
+	if !in {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"_index": name,
+			"_id":    id,
+			"found":  false,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"_index":  name,
+		"_id":     id,
+		"found":   true,
+		"_source": doc,
+	})
+}
+
+// putDocument stores a document, creating its index with a dynamic
+// mapping if needed as ElasticSearch does. It returns "created" or
+// "updated".
+func (s *Server) putDocument(name, id string, doc json.RawMessage) string {
+	idx, in := s.indices[name]
+	if !in {
+		idx = &index{
+			mappings: map[string]interface{}{},
+			docs:     make(map[string]json.RawMessage),
+		}
+		s.indices[name] = idx
+	}
+	_, exists := idx.docs[id]
+	idx.docs[id] = doc
+	if exists {
+		return "updated"
+	}
+	return "created"
+}
+
+// bulk handles a _bulk request. Only "index" actions are supported.
+func (s *Server) bulk(w http.ResponseWriter, r *http.Request) {
+	items := make([]map[string]interface{}, 0)
+	hasErrors := false
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+			return
+		}
+		meta, ok := action["index"]
+		if !ok || !scanner.Scan() {
+			writeError(w, http.StatusBadRequest, "action_request_validation_exception", "only index actions with a source are supported")
+			return
+		}
+		doc := json.RawMessage(append([]byte(nil), scanner.Bytes()...))
+		name := s.resolve(meta.Index)
+		item := map[string]interface{}{
+			"_index": name,
+			"_id":    meta.ID,
+		}
+		if json.Valid(doc) {
+			item["result"] = s.putDocument(name, meta.ID, doc)
+			item["status"] = http.StatusOK
+		} else {
+			hasErrors = true
+			item["status"] = http.StatusBadRequest
+			item["error"] = map[string]interface{}{
+				"type":   "mapper_parsing_exception",
+				"reason": "failed to parse",
+			}
+		}
+		items = append(items, map[string]interface{}{"index": item})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"errors": hasErrors,
+		"items":  items,
+	})
+}
+
+// getSettings writes the settings of the indices whose name matches a
+// pattern, which may contain wildcards.
+func (s *Server) getSettings(w http.ResponseWriter, pattern string) {
+	res := make(map[string]interface{})
+	for name, idx := range s.indices {
+		if ok, _ := path.Match(pattern, name); ok {
+			res[name] = map[string]interface{}{"settings": idx.settings}
+		}
+	}
+	if len(res) == 0 && !strings.Contains(pattern, "*") {
+		writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+pattern+"]")
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// getAlias writes the index designated by an alias.
+func (s *Server) getAlias(w http.ResponseWriter, alias string) {
+	target, in := s.aliases[alias]
+	if !in {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error":  "alias [" + alias + "] missing",
+			"status": http.StatusNotFound,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		target: map[string]interface{}{
+			"aliases": map[string]interface{}{alias: map[string]interface{}{}},
+		},
+	})
+}
+
+// updateAliases applies a list of alias actions. All actions are
+// validated before any is applied, so that they're atomic.
+func (s *Server) updateAliases(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Actions []map[string]struct {
+			Index string `json:"index"`
+			Alias string `json:"alias"`
+		} `json:"actions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+		return
+	}
+	aliases := make(map[string]string)
+	for alias, target := range s.aliases {
+		aliases[alias] = target
+	}
+	for _, action := range body.Actions {
+		for typ, a := range action {
+			if _, in := s.indices[a.Index]; !in {
+				writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+a.Index+"]")
+				return
+			}
+			switch typ {
+			case "add":
+				if _, in := s.indices[a.Alias]; in {
+					writeError(w, http.StatusBadRequest, "invalid_alias_name_exception",
+						"an index exists with the same name as the alias ["+a.Alias+"]")
+					return
+				}
+				aliases[a.Alias] = a.Index
+			case "remove":
+				if aliases[a.Alias] != a.Index {
+					writeError(w, http.StatusNotFound, "aliases_not_found_exception", "aliases ["+a.Alias+"] missing")
+					return
+				}
+				delete(aliases, a.Alias)
+			default:
+				writeError(w, http.StatusBadRequest, "unsupported_operation_exception", "estest doesn't support "+typ+" alias actions")
+				return
+			}
+		}
+	}
+	s.aliases = aliases
+	writeJSON(w, http.StatusOK, map[string]interface{}{"acknowledged": true})
+}
@@ -0,0 +1,427 @@
+package estest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decode decodes JSON, keeping numbers as json.Number so that large
+// integers such as package versions don't lose precision.
+func decode(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// field returns the value of a dotted field (e.g. "to.package") in a
+// decoded document. The subfields of a string, such as "ident.words",
+// have the value of the string.
+func field(doc interface{}, name string) (interface{}, bool) {
+	for _, key := range strings.Split(name, ".") {
+		if s, ok := doc.(string); ok {
+			return s, true
+		}
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// compare compares two field values. Numbers are compared as
+// numbers, anything else as strings.
+func compare(a, b interface{}) int {
+	if na, ok := number(a); ok {
+		if nb, ok := number(b); ok {
+			switch {
+			case na < nb:
+				return -1
+			case na > nb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// number returns a field value as a number. Integers are parsed as
+// int64 first so that large versions compare exactly.
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return float64(i), true
+		}
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// equal returns whether two field values are equal. Integers are
+// compared exactly.
+func equal(a, b interface{}) bool {
+	ia, erra := strconv.ParseInt(fmt.Sprint(a), 10, 64)
+	ib, errb := strconv.ParseInt(fmt.Sprint(b), 10, 64)
+	if erra == nil && errb == nil {
+		return ia == ib
+	}
+	return compare(a, b) == 0
+}
+
+// queries returns the clauses of a bool query occurrence, which may
+// be a single query or a list of queries.
+func queries(v interface{}) []interface{} {
+	if l, ok := v.([]interface{}); ok {
+		return l
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+// fieldParam returns the field name and parameter of a leaf query
+// such as {"field": value} or {"field": {"value": value}}.
+func fieldParam(q interface{}, key string) (string, interface{}) {
+	for name, param := range q.(map[string]interface{}) {
+		if m, ok := param.(map[string]interface{}); ok {
+			if v, ok := m[key]; ok {
+				return name, v
+			}
+			if v, ok := m["value"]; ok {
+				return name, v
+			}
+		}
+		return name, param
+	}
+	return "", nil
+}
+
+// matches returns whether a decoded document of the index matches a
+// query. Only the match_all, term, terms, prefix, wildcard, range,
+// match and bool queries are supported.
+func (idx *index) matches(doc interface{}, query interface{}) (bool, error) {
+	if query == nil {
+		return true, nil
+	}
+	q, ok := query.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("invalid query %v", query)
+	}
+	for typ, body := range q {
+		switch typ {
+		case "match_all":
+			return true, nil
+		case "term":
+			name, value := fieldParam(body, "value")
+			v, ok := field(doc, name)
+			return ok && equal(v, value), nil
+		case "terms":
+			name, values := fieldParam(body, "")
+			v, ok := field(doc, name)
+			if !ok {
+				return false, nil
+			}
+			for _, value := range queries(values) {
+				if equal(v, value) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case "prefix":
+			name, prefix := fieldParam(body, "prefix")
+			v, ok := field(doc, name)
+			return ok && strings.HasPrefix(fmt.Sprint(v), fmt.Sprint(prefix)), nil
+		case "wildcard":
+			name, pattern := fieldParam(body, "wildcard")
+			v, ok := field(doc, name)
+			return ok && wildcard(fmt.Sprint(pattern)).MatchString(fmt.Sprint(v)), nil
+		case "range":
+			for name, bounds := range body.(map[string]interface{}) {
+				v, ok := field(doc, name)
+				if !ok {
+					return false, nil
+				}
+				if !inRange(v, bounds.(map[string]interface{})) {
+					return false, nil
+				}
+			}
+			return true, nil
+		case "match":
+			return idx.matchesText(doc, body.(map[string]interface{}))
+		case "bool":
+			return idx.matchesBool(doc, body.(map[string]interface{}))
+		default:
+			return false, fmt.Errorf("estest doesn't support %s queries", typ)
+		}
+	}
+	return true, nil
+}
+
+// inRange returns whether a field value is within the bounds of a
+// range query, expressed either with gt/gte/lt/lte or with
+// from/to/include_lower/include_upper.
+func inRange(v interface{}, bounds map[string]interface{}) bool {
+	for op, bound := range bounds {
+		if bound == nil {
+			continue
+		}
+		switch op {
+		case "from":
+			if bounds["include_lower"] == false {
+				op = "gt"
+			} else {
+				op = "gte"
+			}
+		case "to":
+			if bounds["include_upper"] == false {
+				op = "lt"
+			} else {
+				op = "lte"
+			}
+		}
+		c := compare(v, bound)
+		if (op == "gt" && c <= 0) || (op == "gte" && c < 0) || (op == "lt" && c >= 0) || (op == "lte" && c > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesBool returns whether a decoded document of the index
+// matches a bool query.
+func (idx *index) matchesBool(doc interface{}, b map[string]interface{}) (bool, error) {
+	for _, occur := range []string{"must", "filter"} {
+		for _, q := range queries(b[occur]) {
+			if ok, err := idx.matches(doc, q); !ok || err != nil {
+				return false, err
+			}
+		}
+	}
+	for _, q := range queries(b["must_not"]) {
+		if ok, err := idx.matches(doc, q); ok || err != nil {
+			return false, err
+		}
+	}
+	// Should clauses are optional if there are other clauses,
+	// unless a minimum_should_match says otherwise.
+	required := 1
+	if b["must"] != nil || b["filter"] != nil {
+		required = 0
+	}
+	if m, in := b["minimum_should_match"]; in {
+		n, err := strconv.Atoi(fmt.Sprint(m))
+		if err != nil {
+			return false, fmt.Errorf("estest doesn't support minimum_should_match %v", m)
+		}
+		required = n
+	}
+	should := queries(b["should"])
+	if len(should) == 0 {
+		return true, nil
+	}
+	matched := 0
+	for _, q := range should {
+		ok, err := idx.matches(doc, q)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched++
+		}
+	}
+	return matched >= required, nil
+}
+
+// A hit is a document that matched a search.
+type hit struct {
+	id     string
+	source json.RawMessage
+	doc    interface{}
+	sort   []interface{}
+}
+
+// sortField is a field to sort hits on.
+type sortField struct {
+	name string
+	desc bool
+}
+
+// sortFields parses the "sort" of a search request.
+func sortFields(v interface{}) []sortField {
+	fields := make([]sortField, 0)
+	for _, s := range queries(v) {
+		switch s := s.(type) {
+		case string:
+			fields = append(fields, sortField{name: s})
+		case map[string]interface{}:
+			for name, order := range s {
+				if m, ok := order.(map[string]interface{}); ok {
+					order = m["order"]
+				}
+				fields = append(fields, sortField{name: name, desc: order == "desc"})
+			}
+		}
+	}
+	return fields
+}
+
+// compareSort compares the sort values of two hits.
+func compareSort(fields []sortField, a, b []interface{}) int {
+	for i, f := range fields {
+		c := compare(a[i], b[i])
+		if f.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// find returns the documents of an existing index that match a
+// query, sorted by the provided fields, or by ID.
+func (s *Server) find(name string, query interface{}, fields []sortField) ([]*hit, error) {
+	idx := s.indices[name]
+	hits := make([]*hit, 0)
+	for id, source := range idx.docs {
+		var doc interface{}
+		if err := decode(source, &doc); err != nil {
+			return nil, err
+		}
+		ok, err := idx.matches(doc, query)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		h := &hit{id: id, source: source, doc: doc}
+		for _, f := range fields {
+			v, _ := field(doc, f.name)
+			if f.name == "_id" {
+				v = id
+			}
+			h.sort = append(h.sort, v)
+		}
+		hits = append(hits, h)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if c := compareSort(fields, hits[i].sort, hits[j].sort); c != 0 {
+			return c < 0
+		}
+		return hits[i].id < hits[j].id
+	})
+	return hits, nil
+}
+
+// search handles a _search request, with support for from/size and
+// search_after pagination.
+func (s *Server) search(w http.ResponseWriter, r *http.Request, name string) {
+	if _, in := s.indices[name]; !in {
+		writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+name+"]")
+		return
+	}
+	var body struct {
+		Query       interface{}   `json:"query"`
+		Sort        interface{}   `json:"sort"`
+		SearchAfter []interface{} `json:"search_after"`
+		From        int           `json:"from"`
+		Size        *int          `json:"size"`
+	}
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(r.Body)
+	if buf.Len() > 0 {
+		if err := decode(buf.Bytes(), &body); err != nil {
+			writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+			return
+		}
+	}
+	fields := sortFields(body.Sort)
+	hits, err := s.find(name, body.Query, fields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "search_phase_execution_exception", err.Error())
+		return
+	}
+	total := len(hits)
+	if body.SearchAfter != nil {
+		i := sort.Search(len(hits), func(i int) bool {
+			return compareSort(fields, hits[i].sort, body.SearchAfter) > 0
+		})
+		hits = hits[i:]
+	}
+	if body.From < len(hits) {
+		hits = hits[body.From:]
+	} else {
+		hits = nil
+	}
+	size := 10
+	if body.Size != nil {
+		size = *body.Size
+	}
+	if size < len(hits) {
+		hits = hits[:size]
+	}
+
+	results := make([]map[string]interface{}, 0, len(hits))
+	for _, h := range hits {
+		result := map[string]interface{}{
+			"_index":  name,
+			"_id":     h.id,
+			"_source": h.source,
+		}
+		if len(fields) > 0 {
+			result["sort"] = h.sort
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"hits": map[string]interface{}{
+			"total": map[string]interface{}{"value": total, "relation": "eq"},
+			"hits":  results,
+		},
+	})
+}
+
+// deleteByQuery handles a _delete_by_query request.
+func (s *Server) deleteByQuery(w http.ResponseWriter, r *http.Request, name string) {
+	if _, in := s.indices[name]; !in {
+		writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+name+"]")
+		return
+	}
+	var body struct {
+		Query interface{} `json:"query"`
+	}
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(r.Body)
+	if err := decode(buf.Bytes(), &body); err != nil {
+		writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+		return
+	}
+	hits, err := s.find(name, body.Query, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "search_phase_execution_exception", err.Error())
+		return
+	}
+	for _, h := range hits {
+		delete(s.indices[name].docs, h.id)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total":   len(hits),
+		"deleted": len(hits),
+	})
+}
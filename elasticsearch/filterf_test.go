@@ -1,22 +1,26 @@
 package elasticsearch_test
 
 import (
-	"errors"
 	"testing"
 
+	"github.com/korfuri/goref"
 	"github.com/korfuri/goref/elasticsearch"
 	"github.com/korfuri/goref/elasticsearch/mocks"
+	"github.com/korfuri/goref/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	elastic "gopkg.in/olivere/elastic.v5"
 )
 
-func TestFilterF(t *testing.T) {
+func TestFilterF_loadPackages(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/simple"
+
 	client := &mocks.Client{}
-	client.On("GetPackage", mock.Anything, "v1@0@a").Return(&elastic.GetResult{}, nil)
-	client.On("GetPackage", mock.Anything, "v1@0@b").Return(nil, errors.New("404"))
+	client.On("GetPackage", mock.Anything, mock.Anything).Return(nil, refused)
 
-	f := elasticsearch.FilterF(client)
-	assert.False(t, f("a", 0))
-	assert.True(t, f("b", 0))
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetFilterF(store.FilterF(elasticsearch.NewStore(client, elasticsearch.DefaultBulkConfig)))
+	err := pg.LoadPackages([]string{pkgpath}, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Empty(t, pg.Packages)
 }
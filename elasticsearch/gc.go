@@ -0,0 +1,110 @@
+package elasticsearch
+
+import (
+	"context"
+	"sort"
+
+	"github.com/korfuri/goref"
+	log "github.com/sirupsen/logrus"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+const (
+	// Number of documents fetched per request when listing
+	// packages.
+	scrollSize = 1000
+)
+
+// deletionOrder is the order in which the documents of a package
+// version are deleted. The package document goes last, so that a
+// version that was only partially deleted is still listed and gets
+// deleted by the next garbage collection.
+var deletionOrder = []string{RefType, SymbolType, FileType, PackageType}
+
+// packageFields is the field that holds a document's package load
+// path, by type.
+var packageFields = map[string]string{
+	PackageType: "loadpath",
+	FileType:    "package",
+	RefType:     "from.package",
+	SymbolType:  "package",
+}
+
+// versionsQuery returns a query that matches the documents of the
+// provided type that belong to some versions of a package.
+func versionsQuery(typ, loadpath string, versions []int64) elastic.Query {
+	terms := make([]interface{}, len(versions))
+	for i, v := range versions {
+		terms[i] = v
+	}
+	return elastic.NewBoolQuery().Filter(
+		elastic.NewTermQuery(packageFields[typ], loadpath),
+		elastic.NewTermsQuery("version", terms...))
+}
+
+// GCPolicy selects the versions of each package that GarbageCollect
+// keeps. The latest version of a package is always kept.
+type GCPolicy struct {
+	// KeepLatest is the number of most recent versions of each
+	// package to keep.
+	KeepLatest int
+
+	// KeepNewerThan is a version after which all versions are
+	// kept. Zero disables this.
+	KeepNewerThan int64
+}
+
+// collectable returns the versions that the policy doesn't keep.
+func (policy GCPolicy) collectable(versions []int64) []int64 {
+	sorted := append([]int64(nil), versions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	removed := make([]int64, 0)
+	for i, v := range sorted {
+		if i == 0 || i < policy.KeepLatest || (policy.KeepNewerThan != 0 && v > policy.KeepNewerThan) {
+			continue
+		}
+		removed = append(removed, v)
+	}
+	return removed
+}
+
+// GarbageCollect deletes the versions of packages that the policy
+// doesn't keep from the index, along with their Files and Refs, and
+// returns them sorted by load path and version. If dryRun is true,
+// nothing is deleted and GarbageCollect only returns what would be
+// deleted.
+//
+// It's safe to run GarbageCollect again after a failure: versions
+// that were partially deleted are deleted by the next run.
+func GarbageCollect(ctx context.Context, client Client, policy GCPolicy, dryRun bool) ([]*goref.Package, error) {
+	versions, err := client.PackageVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loadpaths := make([]string, 0, len(versions))
+	for loadpath := range versions {
+		loadpaths = append(loadpaths, loadpath)
+	}
+	sort.Strings(loadpaths)
+
+	removed := make([]*goref.Package, 0)
+	for _, loadpath := range loadpaths {
+		collected := policy.collectable(versions[loadpath])
+		if len(collected) == 0 {
+			continue
+		}
+		sort.Slice(collected, func(i, j int) bool { return collected[i] < collected[j] })
+		if !dryRun {
+			deleted, err := client.DeletePackageVersions(ctx, loadpath, collected)
+			if err != nil {
+				return removed, err
+			}
+			log.Debugf("Deleted %d documents for %d versions of %s", deleted, len(collected), loadpath)
+		}
+		for _, v := range collected {
+			removed = append(removed, &goref.Package{Path: loadpath, Version: v})
+		}
+	}
+	log.Infof("Garbage collected %d package versions out of %d packages.", len(removed), len(loadpaths))
+	return removed, nil
+}
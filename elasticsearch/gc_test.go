@@ -0,0 +1,138 @@
+package elasticsearch_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/elasticsearch"
+	"github.com/korfuri/goref/elasticsearch/estest"
+	"github.com/korfuri/goref/elasticsearch/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func versionsOf(removed []*goref.Package) map[string][]int64 {
+	versions := make(map[string][]int64)
+	for _, p := range removed {
+		versions[p.Path] = append(versions[p.Path], p.Version)
+	}
+	return versions
+}
+
+func TestGarbageCollect_policy(t *testing.T) {
+	versions := map[string][]int64{
+		"a": {1, 5, 3, 4, 2},
+		"b": {2},
+		"c": {10, 20},
+	}
+	testCases := []struct {
+		policy   elasticsearch.GCPolicy
+		expected map[string][]int64
+	}{
+		{
+			elasticsearch.GCPolicy{},
+			map[string][]int64{"a": {1, 2, 3, 4}, "c": {10}},
+		},
+		{
+			elasticsearch.GCPolicy{KeepLatest: 2},
+			map[string][]int64{"a": {1, 2, 3}},
+		},
+		{
+			elasticsearch.GCPolicy{KeepLatest: 1, KeepNewerThan: 2},
+			map[string][]int64{"a": {1, 2}},
+		},
+		{
+			elasticsearch.GCPolicy{KeepNewerThan: 30},
+			map[string][]int64{"a": {1, 2, 3, 4}, "c": {10}},
+		},
+	}
+	for _, tc := range testCases {
+		client := &mocks.Client{}
+		client.On("PackageVersions", mock.Anything).Return(versions, nil)
+		client.On("DeletePackageVersions", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
+
+		removed, err := elasticsearch.GarbageCollect(context.Background(), client, tc.policy, false)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, versionsOf(removed), "%+v", tc.policy)
+		for loadpath, v := range tc.expected {
+			client.AssertCalled(t, "DeletePackageVersions", mock.Anything, loadpath, v)
+		}
+		client.AssertNumberOfCalls(t, "DeletePackageVersions", len(tc.expected))
+	}
+}
+
+func TestGarbageCollect_dryRun(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("PackageVersions", mock.Anything).Return(map[string][]int64{"a": {1, 2}}, nil)
+
+	removed, err := elasticsearch.GarbageCollect(context.Background(), client, elasticsearch.GCPolicy{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]int64{"a": {1}}, versionsOf(removed))
+	client.AssertNotCalled(t, "DeletePackageVersions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGarbageCollect_errors(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("PackageVersions", mock.Anything).Return(nil, errors.New("unreachable"))
+	_, err := elasticsearch.GarbageCollect(context.Background(), client, elasticsearch.GCPolicy{}, false)
+	assert.EqualError(t, err, "unreachable")
+
+	client = &mocks.Client{}
+	client.On("PackageVersions", mock.Anything).Return(map[string][]int64{"a": {1, 2}, "b": {1, 2}}, nil)
+	client.On("DeletePackageVersions", mock.Anything, "a", mock.Anything).Return(int64(3), nil)
+	client.On("DeletePackageVersions", mock.Anything, "b", mock.Anything).Return(int64(0), errors.New("unreachable"))
+	removed, err := elasticsearch.GarbageCollect(context.Background(), client, elasticsearch.GCPolicy{}, false)
+	assert.EqualError(t, err, "unreachable")
+	assert.Equal(t, map[string][]int64{"a": {1}}, versionsOf(removed))
+}
+
+func TestGarbageCollect_typeless(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	ctx := context.Background()
+	assert.NoError(t, elasticsearch.EnsureIndex(ctx, client))
+
+	// Versions like FileMTimeVersion's, which don't fit in a
+	// float64.
+	base := time.Date(2024, 1, 1, 0, 0, 0, 1, time.UTC).UnixNano()
+	var pg *goref.PackageGraph
+	for i := int64(0); i < 3; i++ {
+		pg = goref.NewPackageGraph(goref.ConstantVersion(base + i))
+		pg.LoadPackages([]string{pkgpath}, false)
+		assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
+	}
+	files := len(s.Documents("goref-file"))
+	refs := len(s.Documents("goref-ref"))
+
+	versions, err := client.PackageVersions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, versions, len(pg.Packages))
+	assert.Equal(t, []int64{base, base + 1, base + 2}, versions[pkgpath])
+
+	policy := elasticsearch.GCPolicy{KeepLatest: 2}
+	removed, err := elasticsearch.GarbageCollect(ctx, client, policy, true)
+	assert.NoError(t, err)
+	assert.Len(t, removed, len(pg.Packages))
+	assert.Equal(t, []int64{base}, versionsOf(removed)[pkgpath])
+	assert.Len(t, s.Documents("goref-package"), 3*len(pg.Packages))
+
+	removed, err = elasticsearch.GarbageCollect(ctx, client, policy, false)
+	assert.NoError(t, err)
+	assert.Len(t, removed, len(pg.Packages))
+	assert.Len(t, s.Documents("goref-package"), 2*len(pg.Packages))
+	assert.Len(t, s.Documents("goref-file"), files*2/3)
+	assert.Len(t, s.Documents("goref-ref"), refs*2/3)
+	assert.Nil(t, s.Document("goref-package", (&goref.Package{Path: pkgpath, Version: base}).DocumentID()))
+	assert.NotNil(t, s.Document("goref-package", (&goref.Package{Path: pkgpath, Version: base + 1}).DocumentID()))
+
+	// Everything left is kept.
+	removed, err = elasticsearch.GarbageCollect(ctx, client, policy, false)
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,252 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+)
+
+// MappingVersion is the version of the mapping installed by
+// EnsureIndex. It is stored in the `_meta` of each mapping type, and
+// must be incremented whenever the mapping changes in a way that
+// isn't compatible with existing indices.
+//
+// Version 2 added symbols. Version 3 marks that the "package" of file
+// documents is the load path of their package rather than its name:
+// older file documents aren't found by GetFiles or garbage collected.
+// Version 4 added the docIDField, on which searches are sorted.
+// Version 5 added the prefixes of the identifiers of symbols.
+const MappingVersion = 5
+
+// mappingVersionKey is the `_meta` key that holds the MappingVersion.
+const mappingVersionKey = "goref_mapping_version"
+
+// docIDField is the field that holds the ID of each document. Unlike
+// _id, it can be sorted on to page through search results.
+const docIDField = "doc_id"
+
+// identAnalyzer is the name of the analyzer that splits identifiers
+// into lowercase words, e.g. "NewHTTPClient" into "new", "http" and
+// "client".
+const identAnalyzer = "goref_ident"
+
+// trigramAnalyzer is the name of the analyzer that splits identifiers
+// into their lowercase trigrams, e.g. "Graph" into "gra", "rap" and
+// "aph", so that they can be matched by substring.
+const trigramAnalyzer = "goref_trigram"
+
+// prefixAnalyzer is the name of the analyzer that splits identifiers
+// into the lowercase prefixes of the identifier and of its name, e.g.
+// "T.Method" into "t", "t.", ..., "t.method" and "m", ..., "method",
+// so that typos in a prefix can be matched.
+const prefixAnalyzer = "goref_prefix"
+
+// maxPrefix is the length of the longest prefix indexed by the
+// prefixAnalyzer.
+const maxPrefix = 50
+
+// lowercaseAnalyzer is the name of the analyzer that lowercases text
+// without splitting it.
+const lowercaseAnalyzer = "goref_lowercase"
+
+// indexSettings returns the settings of goref indices, which define
+// the identAnalyzer, the trigramAnalyzer, the prefixAnalyzer and the
+// lowercaseAnalyzer.
+func indexSettings() map[string]interface{} {
+	return map[string]interface{}{
+		"analysis": map[string]interface{}{
+			"analyzer": map[string]interface{}{
+				identAnalyzer: map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "goref_camelcase",
+					"filter":    []string{"lowercase"},
+				},
+				trigramAnalyzer: map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "goref_trigram",
+					"filter":    []string{"lowercase"},
+				},
+				prefixAnalyzer: map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "goref_names",
+					"filter":    []string{"lowercase", "goref_prefix"},
+				},
+				lowercaseAnalyzer: map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "keyword",
+					"filter":    []string{"lowercase"},
+				},
+			},
+			"filter": map[string]interface{}{
+				"goref_prefix": map[string]interface{}{
+					"type":     "edge_ngram",
+					"min_gram": 1,
+					"max_gram": maxPrefix,
+				},
+			},
+			"tokenizer": map[string]interface{}{
+				// Splits on non-alphanumeric characters,
+				// letter-digit transitions and case changes.
+				"goref_camelcase": map[string]interface{}{
+					"type":    "pattern",
+					"pattern": `([^\p{L}\d]+)|(?<=\D)(?=\d)|(?<=\d)(?=\D)|(?<=[\p{L}&&[^\p{Lu}]])(?=\p{Lu})|(?<=\p{Lu})(?=\p{Lu}[\p{L}&&[^\p{Lu}]])`,
+				},
+				"goref_trigram": map[string]interface{}{
+					"type":     "ngram",
+					"min_gram": 3,
+					"max_gram": 3,
+				},
+				// Emits the identifier, and its suffixes
+				// after each dot.
+				"goref_names": map[string]interface{}{
+					"type":      "path_hierarchy",
+					"delimiter": ".",
+					"reverse":   true,
+				},
+			},
+		},
+	}
+}
+
+var (
+	keywordField = map[string]interface{}{"type": "keyword"}
+	integerField = map[string]interface{}{"type": "integer"}
+	longField    = map[string]interface{}{"type": "long"}
+
+	// identField is an identifier, which is matched exactly or by
+	// the words it's made of in its "words" subfield.
+	identField = map[string]interface{}{
+		"type": "keyword",
+		"fields": map[string]interface{}{
+			"words": map[string]interface{}{
+				"type":     "text",
+				"analyzer": identAnalyzer,
+			},
+		},
+	}
+
+	// symbolIdentField is the identifier of a symbol, which is
+	// also matched by substring in its "trigrams" subfield, and
+	// with typos in its "prefixes" subfield.
+	symbolIdentField = map[string]interface{}{
+		"type": "keyword",
+		"fields": map[string]interface{}{
+			"words": map[string]interface{}{
+				"type":     "text",
+				"analyzer": identAnalyzer,
+			},
+			"trigrams": map[string]interface{}{
+				"type":     "text",
+				"analyzer": trigramAnalyzer,
+			},
+			"prefixes": map[string]interface{}{
+				"type":            "text",
+				"analyzer":        prefixAnalyzer,
+				"search_analyzer": lowercaseAnalyzer,
+			},
+		},
+	}
+
+	// positionField is a pb.Position.
+	positionField = map[string]interface{}{
+		"properties": map[string]interface{}{
+			"filename":   keywordField,
+			"start_line": integerField,
+			"start_col":  integerField,
+			"end_line":   integerField,
+			"end_col":    integerField,
+		},
+	}
+
+	// locationField is one end of a Ref, as marshalled from a
+	// pb.Location.
+	locationField = map[string]interface{}{
+		"properties": map[string]interface{}{
+			"position": positionField,
+			"package":  keywordField,
+			"ident":    identField,
+		},
+	}
+)
+
+// typeMappings returns the mapping of each type of document in goref
+// indices.
+func typeMappings() map[string]interface{} {
+	mapping := func(properties map[string]interface{}) map[string]interface{} {
+		properties[docIDField] = keywordField
+		return map[string]interface{}{
+			"_meta":      map[string]interface{}{mappingVersionKey: MappingVersion},
+			"dynamic":    false,
+			"properties": properties,
+		}
+	}
+	return map[string]interface{}{
+		PackageType: mapping(map[string]interface{}{
+			"loadpath": keywordField,
+			"version":  longField,
+		}),
+		FileType: mapping(map[string]interface{}{
+			"filename": keywordField,
+			"package":  keywordField,
+			"version":  longField,
+		}),
+		RefType: mapping(map[string]interface{}{
+			"version": longField,
+			"type":    integerField,
+			"from":    locationField,
+			"to":      locationField,
+		}),
+		SymbolType: mapping(map[string]interface{}{
+			"package":  keywordField,
+			"version":  longField,
+			"ident":    symbolIdentField,
+			"kind":     keywordField,
+			"initials": keywordField,
+			"position": positionField,
+		}),
+	}
+}
+
+// IndexBody returns the body used to create a goref index: its
+// settings and mappings.
+func IndexBody() map[string]interface{} {
+	return map[string]interface{}{
+		"settings": indexSettings(),
+		"mappings": typeMappings(),
+	}
+}
+
+// mappingVersion returns the MappingVersion recorded in a type's
+// mapping, or 0 if there is none, e.g. if the mapping was created
+// dynamically.
+func mappingVersion(mapping interface{}) int {
+	m, _ := mapping.(map[string]interface{})
+	meta, _ := m["_meta"].(map[string]interface{})
+	// Numbers are decoded from JSON as float64.
+	v, _ := meta[mappingVersionKey].(float64)
+	return int(v)
+}
+
+// EnsureIndex creates the client's index with goref's mapping if it
+// doesn't exist. If it exists, EnsureIndex checks that its mapping is
+// the one goref expects, and returns an error otherwise. Such an index
+// should be deleted, or replaced by a new index, and reindexed.
+func EnsureIndex(ctx context.Context, client Client) error {
+	exists, err := client.IndexExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return client.CreateIndex(ctx, IndexBody())
+	}
+
+	mappings, err := client.GetMapping(ctx)
+	if err != nil {
+		return err
+	}
+	for typ := range typeMappings() {
+		if v := mappingVersion(mappings[typ]); v != MappingVersion {
+			return fmt.Errorf("Index has an incompatible mapping for type %s: found mapping version %d, expected %d", typ, v, MappingVersion)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package elasticsearch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/korfuri/goref/elasticsearch"
+	"github.com/korfuri/goref/elasticsearch/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// indexMappings returns the mappings of IndexBody as they would be
+// returned by ElasticSearch, i.e. decoded from JSON.
+func indexMappings(t *testing.T) map[string]interface{} {
+	j, err := json.Marshal(elasticsearch.IndexBody())
+	assert.NoError(t, err)
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(j, &body))
+	return body["mappings"].(map[string]interface{})
+}
+
+func TestIndexBody(t *testing.T) {
+	mappings := indexMappings(t)
+	assert.Contains(t, mappings, elasticsearch.PackageType)
+	assert.Contains(t, mappings, elasticsearch.FileType)
+	assert.Contains(t, mappings, elasticsearch.RefType)
+
+	ref := mappings[elasticsearch.RefType].(map[string]interface{})
+	to := ref["properties"].(map[string]interface{})["to"].(map[string]interface{})["properties"].(map[string]interface{})
+	position := to["position"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, "keyword", position["filename"].(map[string]interface{})["type"])
+	assert.Equal(t, "integer", position["start_line"].(map[string]interface{})["type"])
+	assert.Equal(t, "keyword", to["ident"].(map[string]interface{})["type"])
+
+	for typ := range mappings {
+		properties := mappings[typ].(map[string]interface{})["properties"].(map[string]interface{})
+		assert.Equal(t, "keyword", properties["doc_id"].(map[string]interface{})["type"], typ)
+	}
+}
+
+func TestEnsureIndex_create(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("IndexExists", mock.Anything).Return(false, nil)
+	client.On("CreateIndex", mock.Anything, elasticsearch.IndexBody()).Return(nil)
+
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+	client.AssertCalled(t, "CreateIndex", mock.Anything, elasticsearch.IndexBody())
+}
+
+func TestEnsureIndex_compatible(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("IndexExists", mock.Anything).Return(true, nil)
+	client.On("GetMapping", mock.Anything).Return(indexMappings(t), nil)
+
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+	client.AssertNotCalled(t, "CreateIndex", mock.Anything, mock.Anything)
+}
+
+func TestEnsureIndex_incompatible(t *testing.T) {
+	// A mapping created dynamically by ElasticSearch has no
+	// version marker.
+	client := &mocks.Client{}
+	client.On("IndexExists", mock.Anything).Return(true, nil)
+	client.On("GetMapping", mock.Anything).Return(map[string]interface{}{
+		elasticsearch.PackageType: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"loadpath": map[string]interface{}{"type": "text"},
+			},
+		},
+	}, nil)
+
+	assert.Error(t, elasticsearch.EnsureIndex(context.Background(), client))
+	client.AssertNotCalled(t, "CreateIndex", mock.Anything, mock.Anything)
+}
+
+func TestEnsureIndex_outdated(t *testing.T) {
+	// Mappings installed by an older version of goref have an older
+	// version marker.
+	mappings := indexMappings(t)
+	for _, m := range mappings {
+		m.(map[string]interface{})["_meta"] = map[string]interface{}{
+			"goref_mapping_version": elasticsearch.MappingVersion - 1,
+		}
+	}
+	client := &mocks.Client{}
+	client.On("IndexExists", mock.Anything).Return(true, nil)
+	client.On("GetMapping", mock.Anything).Return(mappings, nil)
+
+	assert.Error(t, elasticsearch.EnsureIndex(context.Background(), client))
+	client.AssertNotCalled(t, "CreateIndex", mock.Anything, mock.Anything)
+}
+
+func TestEnsureIndex_unreachable(t *testing.T) {
+	client := &mocks.Client{}
+	client.On("IndexExists", mock.Anything).Return(false, errors.New("connection refused"))
+
+	assert.EqualError(t, elasticsearch.EnsureIndex(context.Background(), client), "connection refused")
+}
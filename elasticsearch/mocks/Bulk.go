@@ -0,0 +1,49 @@
+// Code generated by mockery v1.0.0
+package mocks
+
+import elasticsearch "github.com/korfuri/goref/elasticsearch"
+import goref "github.com/korfuri/goref"
+import mock "github.com/stretchr/testify/mock"
+
+// Bulk is an autogenerated mock type for the Bulk type
+type Bulk struct {
+	mock.Mock
+}
+
+// AddFile provides a mock function with given fields: f
+func (_m *Bulk) AddFile(f elasticsearch.File) {
+	_m.Called(f)
+}
+
+// AddRef provides a mock function with given fields: r
+func (_m *Bulk) AddRef(r *goref.Ref) {
+	_m.Called(r)
+}
+
+// AddSymbol provides a mock function with given fields: s
+func (_m *Bulk) AddSymbol(s elasticsearch.Symbol) {
+	_m.Called(s)
+}
+
+// Close provides a mock function with given fields:
+func (_m *Bulk) Close() ([]elasticsearch.BulkFailure, error) {
+	ret := _m.Called()
+
+	var r0 []elasticsearch.BulkFailure
+	if rf, ok := ret.Get(0).(func() []elasticsearch.BulkFailure); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]elasticsearch.BulkFailure)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
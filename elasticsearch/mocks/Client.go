@@ -12,6 +12,27 @@ type Client struct {
 	mock.Mock
 }
 
+// AliasTarget provides a mock function with given fields: ctx
+func (_m *Client) AliasTarget(ctx context.Context) (string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateFile provides a mock function with given fields: ctx, f
 func (_m *Client) CreateFile(ctx context.Context, f elasticsearch.File) (*elastic.IndexResponse, error) {
 	ret := _m.Called(ctx, f)
@@ -35,6 +56,20 @@ func (_m *Client) CreateFile(ctx context.Context, f elasticsearch.File) (*elasti
 	return r0, r1
 }
 
+// CreateIndex provides a mock function with given fields: ctx, body
+func (_m *Client) CreateIndex(ctx context.Context, body map[string]interface{}) error {
+	ret := _m.Called(ctx, body)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]interface{}) error); ok {
+		r0 = rf(ctx, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreatePackage provides a mock function with given fields: ctx, p
 func (_m *Client) CreatePackage(ctx context.Context, p *goref.Package) error {
 	ret := _m.Called(ctx, p)
@@ -72,6 +107,50 @@ func (_m *Client) CreateRef(ctx context.Context, r *goref.Ref) (*elastic.IndexRe
 	return r0, r1
 }
 
+// DeletePackageVersions provides a mock function with given fields: ctx, loadpath, versions
+func (_m *Client) DeletePackageVersions(ctx context.Context, loadpath string, versions []int64) (int64, error) {
+	ret := _m.Called(ctx, loadpath, versions)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, []int64) int64); ok {
+		r0 = rf(ctx, loadpath, versions)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []int64) error); ok {
+		r1 = rf(ctx, loadpath, versions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMapping provides a mock function with given fields: ctx
+func (_m *Client) GetMapping(ctx context.Context) (map[string]interface{}, error) {
+	ret := _m.Called(ctx)
+
+	var r0 map[string]interface{}
+	if rf, ok := ret.Get(0).(func(context.Context) map[string]interface{}); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPackage provides a mock function with given fields: ctx, docID
 func (_m *Client) GetPackage(ctx context.Context, docID string) (*elastic.GetResult, error) {
 	ret := _m.Called(ctx, docID)
@@ -94,3 +173,153 @@ func (_m *Client) GetPackage(ctx context.Context, docID string) (*elastic.GetRes
 
 	return r0, r1
 }
+
+// IndexExists provides a mock function with given fields: ctx
+func (_m *Client) IndexExists(ctx context.Context) (bool, error) {
+	ret := _m.Called(ctx)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context) bool); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIndices provides a mock function with given fields: ctx, prefix
+func (_m *Client) ListIndices(ctx context.Context, prefix string) ([]string, error) {
+	ret := _m.Called(ctx, prefix)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewBulk provides a mock function with given fields: ctx, config
+func (_m *Client) NewBulk(ctx context.Context, config elasticsearch.BulkConfig) (elasticsearch.Bulk, error) {
+	ret := _m.Called(ctx, config)
+
+	var r0 elasticsearch.Bulk
+	if rf, ok := ret.Get(0).(func(context.Context, elasticsearch.BulkConfig) elasticsearch.Bulk); ok {
+		r0 = rf(ctx, config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(elasticsearch.Bulk)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, elasticsearch.BulkConfig) error); ok {
+		r1 = rf(ctx, config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PackageVersions provides a mock function with given fields: ctx
+func (_m *Client) PackageVersions(ctx context.Context) (map[string][]int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 map[string][]int64
+	if rf, ok := ret.Get(0).(func(context.Context) map[string][]int64); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]int64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, typ, query, from, size
+func (_m *Client) Search(ctx context.Context, typ string, query elastic.Query, from int, size int) (*elastic.SearchHits, error) {
+	ret := _m.Called(ctx, typ, query, from, size)
+
+	var r0 *elastic.SearchHits
+	if rf, ok := ret.Get(0).(func(context.Context, string, elastic.Query, int, int) *elastic.SearchHits); ok {
+		r0 = rf(ctx, typ, query, from, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*elastic.SearchHits)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, elastic.Query, int, int) error); ok {
+		r1 = rf(ctx, typ, query, from, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchAfter provides a mock function with given fields: ctx, typ, query, sort, after, size
+func (_m *Client) SearchAfter(ctx context.Context, typ string, query elastic.Query, sort []string, after []interface{}, size int) (*elastic.SearchHits, error) {
+	ret := _m.Called(ctx, typ, query, sort, after, size)
+
+	var r0 *elastic.SearchHits
+	if rf, ok := ret.Get(0).(func(context.Context, string, elastic.Query, []string, []interface{}, int) *elastic.SearchHits); ok {
+		r0 = rf(ctx, typ, query, sort, after, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*elastic.SearchHits)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, elastic.Query, []string, []interface{}, int) error); ok {
+		r1 = rf(ctx, typ, query, sort, after, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetAliasTarget provides a mock function with given fields: ctx, target
+func (_m *Client) SetAliasTarget(ctx context.Context, target string) error {
+	ret := _m.Called(ctx, target)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, target)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
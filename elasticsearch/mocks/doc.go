@@ -1,3 +1,4 @@
 //go:generate mockery -name Client -dir .. -output ./
+//go:generate mockery -name Bulk -dir .. -output ./
 
 package mocks
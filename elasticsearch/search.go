@@ -0,0 +1,275 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/symbols"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// refsSort are the fields that Refs are sorted on. Along with the
+// version of a Ref, they identify it.
+var refsSort = []string{
+	"to.position.filename", "to.position.start_line", "to.position.start_col",
+	"from.position.filename", "from.position.start_line", "from.position.start_col",
+	"type", "to.package", "to.ident",
+}
+
+// searchAll calls f with the source of each document of the provided
+// type that matches query, paging through results sorted by the
+// provided fields with search_after.
+func searchAll(ctx context.Context, client Client, typ string, query elastic.Query, sort []string, f func(source json.RawMessage) error) error {
+	var after []interface{}
+	for {
+		hits, err := client.SearchAfter(ctx, typ, query, sort, after, scrollSize)
+		if err != nil {
+			return err
+		}
+		for _, hit := range hits.Hits {
+			after = hit.Sort
+			if hit.Source == nil {
+				continue
+			}
+			if err := f(*hit.Source); err != nil {
+				return err
+			}
+		}
+		if len(hits.Hits) < scrollSize {
+			return nil
+		}
+	}
+}
+
+// searchPage returns a page of the documents of the provided type that
+// match query, sorted by the provided fields, and the token of the
+// next page. Tokens hold the sort values of the last document of a
+// page.
+func searchPage(ctx context.Context, client Client, typ string, query elastic.Query, sort []string, page store.Page) ([]*elastic.SearchHit, string, error) {
+	var after []interface{}
+	if page.Token != "" {
+		if err := store.DecodeToken(page.Token, &after); err != nil {
+			return nil, "", err
+		}
+	}
+	limit := page.Limit()
+	// One more document is searched to find out if there's a next
+	// page.
+	hits, err := client.SearchAfter(ctx, typ, query, sort, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(hits.Hits) <= limit {
+		return hits.Hits, "", nil
+	}
+	return hits.Hits[:limit], store.EncodeToken(hits.Hits[limit-1].Sort), nil
+}
+
+// distinctPage returns a page of the distinct values of a keyword
+// field of the documents of the provided type that match query, in
+// order, and the token of the next page. Several documents may have
+// the same value, e.g. the files of different versions of a package,
+// so documents are paged through until the page is full. Tokens hold
+// the sort values of the last document of a page, and the value of
+// that document isn't returned again.
+func distinctPage(ctx context.Context, client Client, typ string, query elastic.Query, field string, page store.Page) ([]string, string, error) {
+	var after []interface{}
+	last := ""
+	if page.Token != "" {
+		if err := store.DecodeToken(page.Token, &after); err != nil {
+			return nil, "", err
+		}
+		if len(after) == 0 {
+			return nil, "", store.ErrInvalidPageToken
+		}
+		last = fmt.Sprint(after[0])
+	}
+	limit := page.Limit()
+	values := make([]string, 0)
+	for {
+		hits, err := client.SearchAfter(ctx, typ, query, []string{field}, after, scrollSize)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, hit := range hits.Hits {
+			v := fmt.Sprint(hit.Sort[0])
+			if v != last {
+				if len(values) == limit {
+					return values, store.EncodeToken(after), nil
+				}
+				values = append(values, v)
+				last = v
+			}
+			after = hit.Sort
+		}
+		if len(hits.Hits) < scrollSize {
+			return values, "", nil
+		}
+	}
+}
+
+// searchRefs returns the Refs that match query.
+func (s *esStore) searchRefs(ctx context.Context, query elastic.Query) ([]*pb.Ref, error) {
+	refs := make([]*pb.Ref, 0)
+	err := searchAll(ctx, s.client, RefType, query, refsSort, func(source json.RawMessage) error {
+		var r pb.Ref
+		if err := json.Unmarshal(source, &r); err != nil {
+			return err
+		}
+		refs = append(refs, &r)
+		return nil
+	})
+	return refs, err
+}
+
+func (s *esStore) RefsToFile(ctx context.Context, filename string, page store.Page) ([]*pb.Ref, string, error) {
+	hits, next, err := searchPage(ctx, s.client, RefType, elastic.NewTermQuery("to.position.filename", filename), refsSort, page)
+	if err != nil {
+		return nil, "", err
+	}
+	refs := make([]*pb.Ref, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Source == nil {
+			continue
+		}
+		var r pb.Ref
+		if err := json.Unmarshal(*hit.Source, &r); err != nil {
+			return nil, "", err
+		}
+		refs = append(refs, &r)
+	}
+	return refs, next, nil
+}
+
+func (s *esStore) RefsFromFile(ctx context.Context, filename string) ([]*pb.Ref, error) {
+	return s.searchRefs(ctx, elastic.NewTermQuery("from.position.filename", filename))
+}
+
+func (s *esStore) RefsFromIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error) {
+	return s.searchRefs(ctx, elastic.NewBoolQuery().Filter(
+		elastic.NewTermQuery("from.package", loadpath),
+		elastic.NewTermQuery("from.ident", ident)))
+}
+
+func (s *esStore) RefsToIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error) {
+	return s.searchRefs(ctx, elastic.NewBoolQuery().Filter(
+		elastic.NewTermQuery("to.package", loadpath),
+		elastic.NewTermQuery("to.ident", ident)))
+}
+
+func (s *esStore) RefsToPackage(ctx context.Context, loadpath string) ([]*pb.Ref, error) {
+	return s.searchRefs(ctx, elastic.NewTermQuery("to.package", loadpath))
+}
+
+func (s *esStore) PackageFiles(ctx context.Context, loadpath string, page store.Page) ([]string, string, error) {
+	return distinctPage(ctx, s.client, FileType, elastic.NewTermQuery("package", loadpath), "filename", page)
+}
+
+func (s *esStore) ListPackages(ctx context.Context, prefix string, page store.Page) ([]string, string, error) {
+	var query elastic.Query
+	if prefix != "" {
+		query = elastic.NewPrefixQuery("loadpath", prefix)
+	}
+	return distinctPage(ctx, s.client, PackageType, query, "loadpath", page)
+}
+
+// symbolsQuery returns a query for the Symbols that may match a
+// symbols.Query, so that only these are matched with Query.Match.
+// Prefixes, camelCase words and typos are found by the words of
+// identifiers, initialisms by their initials, typos in the start of
+// identifiers by their prefixes, and substrings and longer typos by
+// their trigrams, of which each typo changes at most 4.
+func symbolsQuery(q symbols.Query) elastic.Query {
+	query := elastic.NewBoolQuery()
+	if q.PackagePrefix != "" {
+		query = query.Filter(elastic.NewPrefixQuery("package", q.PackagePrefix))
+	}
+	if len(q.Kinds) > 0 {
+		kinds := make([]interface{}, len(q.Kinds))
+		for i, k := range q.Kinds {
+			kinds[i] = k
+		}
+		query = query.Filter(elastic.NewTermsQuery("kind", kinds...))
+	}
+	text := strings.TrimSpace(q.Text)
+	if text == "" {
+		return query
+	}
+	lower := strings.ToLower(text)
+	should := []elastic.Query{
+		elastic.NewMatchQuery("ident.words", text).Fuzziness("AUTO"),
+		elastic.NewPrefixQuery("ident.words", lower),
+		elastic.NewWildcardQuery("initials", "*"+lower+"*"),
+		elastic.NewMatchQuery("ident.prefixes", lower).Fuzziness("AUTO"),
+	}
+	if n := len(symbols.Trigrams(lower)) - 4*symbols.MaxEdits(lower); n > 0 {
+		should = append(should, elastic.NewMatchQuery("ident.trigrams", lower).MinimumShouldMatch(strconv.Itoa(n)))
+	}
+	return query.Should(should...).MinimumNumberShouldMatch(1)
+}
+
+// countRefsTo returns the number of Refs to a symbol.
+func (s *esStore) countRefsTo(ctx context.Context, sym *symbols.Symbol) (int, error) {
+	p := sym.Location.Position
+	hits, err := s.client.Search(ctx, RefType, elastic.NewBoolQuery().Filter(
+		elastic.NewTermQuery("to.package", sym.Location.Package),
+		elastic.NewTermQuery("to.ident", sym.Name()),
+		elastic.NewTermQuery("to.position.filename", p.GetFilename()),
+		elastic.NewTermQuery("to.position.start_line", p.GetStartLine()),
+		elastic.NewTermQuery("to.position.start_col", p.GetStartCol())), 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int(hits.TotalHits), nil
+}
+
+func (s *esStore) SearchSymbols(ctx context.Context, q symbols.Query) ([]*symbols.Result, error) {
+	// Symbols are sorted by version last, so each identifier ends
+	// up with its latest version.
+	latest := make(map[string]*symbols.Symbol)
+	idents := make([]string, 0)
+	err := searchAll(ctx, s.client, SymbolType, symbolsQuery(q), []string{"package", "ident", "version"}, func(source json.RawMessage) error {
+		var doc Symbol
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return err
+		}
+		key := doc.Package + "." + doc.Ident
+		if _, in := latest[key]; !in {
+			idents = append(idents, key)
+		}
+		latest[key] = &symbols.Symbol{
+			Location: &pb.Location{
+				Position: doc.Position,
+				Package:  doc.Package,
+				Ident:    doc.Ident,
+			},
+			Kind: doc.Kind,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*symbols.Result, 0)
+	for _, key := range idents {
+		sym := latest[key]
+		if m := q.Match(sym); m != symbols.NoMatch {
+			results = append(results, &symbols.Result{Symbol: sym, Match: m})
+		}
+	}
+	// Refs are counted with a search per symbol, so only for
+	// the matches that may be returned.
+	results = symbols.Survivors(q, results)
+	for _, r := range results {
+		if r.Refs, err = s.countRefsTo(ctx, r.Symbol); err != nil {
+			return nil, err
+		}
+	}
+	return symbols.Rank(q, results), nil
+}
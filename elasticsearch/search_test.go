@@ -0,0 +1,248 @@
+package elasticsearch_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/elasticsearch"
+	"github.com/korfuri/goref/elasticsearch/estest"
+	"github.com/korfuri/goref/elasticsearch/mocks"
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/storetest"
+	"github.com/korfuri/goref/symbols"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// indexPackages loads packages into a PackageGraph and indexes it
+// into a new estest.Server.
+func indexPackages(t *testing.T, packages ...string) (*estest.Server, elasticsearch.Client, *goref.PackageGraph) {
+	s := estest.NewServer()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	assert.NoError(t, pg.LoadPackages(packages, false))
+	pg.ComputeInterfaceImplementationMatrix()
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
+	return s, client, pg
+}
+
+// newStore returns a Store backed by an empty index of a new
+// estest.Server.
+func newStore(t *testing.T) (*estest.Server, store.Store) {
+	s := estest.NewServer()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+	return s, elasticsearch.NewStore(client, elasticsearch.DefaultBulkConfig)
+}
+
+func TestStore_roundTrip(t *testing.T) {
+	s, st := newStore(t)
+	defer s.Close()
+	storetest.RoundTrip(t, st)
+}
+
+func TestStore_pages(t *testing.T) {
+	s, st := newStore(t)
+	defer s.Close()
+	storetest.Pages(t, st)
+}
+
+func TestSearch_pagination(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+	s, client, pg := indexPackages(t, pkgpath)
+	defer s.Close()
+	ctx := context.Background()
+
+	// Pages of a search don't overlap and cover all results.
+	seen := make(map[string]bool)
+	query := elastic.NewPrefixQuery("loadpath", "")
+	for from := 0; from < len(pg.Packages); from += 2 {
+		hits, err := client.Search(ctx, elasticsearch.PackageType, query, from, 2)
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(pg.Packages), hits.TotalHits)
+		for _, hit := range hits.Hits {
+			assert.False(t, seen[hit.Id])
+			seen[hit.Id] = true
+		}
+	}
+	assert.Len(t, seen, len(pg.Packages))
+
+	hits, err := client.Search(ctx, elasticsearch.PackageType, query, len(pg.Packages), 2)
+	assert.NoError(t, err)
+	assert.Empty(t, hits.Hits)
+}
+
+func TestSearch_pages(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+	s, client, pg := indexPackages(t, pkgpath)
+	defer s.Close()
+	ctx := context.Background()
+	st := elasticsearch.NewStore(client, elasticsearch.DefaultBulkConfig)
+
+	// Pages of one Ref don't overlap and cover all Refs to the
+	// file.
+	filename := pg.Packages[pkgpath+"/lib"].Files[0]
+	all, next, err := st.RefsToFile(ctx, filename, store.Page{})
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+	assert.True(t, len(all) > 1)
+	paged := make([]*pb.Ref, 0)
+	page := store.Page{Size: 1}
+	for {
+		refs, next, err := st.RefsToFile(ctx, filename, page)
+		assert.NoError(t, err)
+		assert.Len(t, refs, 1)
+		paged = append(paged, refs...)
+		if next == "" {
+			break
+		}
+		page.Token = next
+	}
+	assert.Equal(t, all, paged)
+
+	// Packages have one document per version, but are only listed
+	// once.
+	p := &goref.Package{Path: pkgpath, Version: 1}
+	assert.NoError(t, client.CreatePackage(ctx, p))
+	packages, next, err := st.ListPackages(ctx, "github.com/korfuri/goref/testprograms/", store.Page{Size: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pkgpath}, packages)
+	assert.NotEmpty(t, next)
+	packages, next, err = st.ListPackages(ctx, "github.com/korfuri/goref/testprograms/", store.Page{Size: 1, Token: next})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pkgpath + "/lib"}, packages)
+	assert.Empty(t, next)
+
+	_, _, err = st.ListPackages(ctx, "", store.Page{Token: "invalid"})
+	assert.Equal(t, store.ErrInvalidPageToken, err)
+}
+
+func TestSearch_allPages(t *testing.T) {
+	// Returns a page of n packages, sorted by load path.
+	page := func(start, n int) *elastic.SearchHits {
+		hits := &elastic.SearchHits{}
+		for i := start; i < start+n; i++ {
+			loadpath := fmt.Sprintf("p%04d", i)
+			source := json.RawMessage(fmt.Sprintf(`{"loadpath": %q, "version": 0}`, loadpath))
+			hits.Hits = append(hits.Hits, &elastic.SearchHit{Source: &source, Sort: []interface{}{loadpath, 0}})
+		}
+		return hits
+	}
+	sort := []string{"loadpath"}
+	client := &mocks.Client{}
+	client.On("SearchAfter", mock.Anything, elasticsearch.PackageType, mock.Anything, sort, []interface{}(nil), 1000).Return(page(0, 1000), nil)
+	client.On("SearchAfter", mock.Anything, elasticsearch.PackageType, mock.Anything, sort, []interface{}{"p0999", 0}, 1000).Return(page(1000, 1000), nil)
+	client.On("SearchAfter", mock.Anything, elasticsearch.PackageType, mock.Anything, sort, []interface{}{"p1999", 0}, 1000).Return(page(2000, 500), nil)
+
+	// Results aren't truncated to ElasticSearch's result window.
+	st := elasticsearch.NewStore(client, elasticsearch.DefaultBulkConfig)
+	packages, next, err := st.ListPackages(context.Background(), "p", store.Page{Size: store.MaxPageSize})
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Len(t, packages, 2500)
+	assert.Equal(t, "p2499", packages[2499])
+	client.AssertNumberOfCalls(t, "SearchAfter", 3)
+}
+
+func TestSearch_symbols(t *testing.T) {
+	s, st := newStore(t)
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	ctx := context.Background()
+
+	symbol := func(loadpath string, version int64, ident, kind string, line int32) elasticsearch.Symbol {
+		return elasticsearch.Symbol{
+			Package:  loadpath,
+			Version:  version,
+			Ident:    ident,
+			Kind:     kind,
+			Initials: symbols.Initials(ident),
+			Position: &pb.Position{Filename: "goref.go", StartLine: line, StartCol: 6},
+		}
+	}
+	bulk, err := client.NewBulk(ctx, elasticsearch.DefaultBulkConfig)
+	assert.NoError(t, err)
+	for _, sym := range []elasticsearch.Symbol{
+		symbol("github.com/korfuri/goref", 1, "NewPackageGraph", "func", 10),
+		symbol("github.com/korfuri/goref", 2, "NewPackageGraph", "func", 12),
+		symbol("github.com/korfuri/goref", 2, "NewPage", "func", 20),
+		symbol("github.com/korfuri/goref", 2, "PackageGraph", "type", 30),
+		symbol("github.com/korfuri/goref", 2, "Corpus", "type", 40),
+		symbol("example.com/other", 2, "PackageGraph", "type", 30),
+	} {
+		bulk.AddSymbol(sym)
+	}
+	// Refs to a symbol are counted by its position.
+	pkg := &goref.Package{Path: "github.com/korfuri/goref", Version: 2}
+	refs := map[string]int{"NewPackageGraph": 2, "PackageGraph": 3}
+	lines := map[string]int{"NewPackageGraph": 12, "PackageGraph": 30}
+	for ident, n := range refs {
+		for i := 0; i < n; i++ {
+			bulk.AddRef(&goref.Ref{
+				ToIdent:      ident,
+				ToPackage:    pkg,
+				ToPosition:   goref.Position{File: "goref.go", PosL: lines[ident], PosC: 6},
+				FromPackage:  pkg,
+				FromPosition: goref.Position{File: "main.go", PosL: i + 1, PosC: 2},
+			})
+		}
+	}
+	failures, err := bulk.Close()
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+
+	// Each symbol is found at its latest version, in its package.
+	results, err := st.SearchSymbols(ctx, symbols.Query{Text: "PG", PackagePrefix: "github.com/korfuri/"})
+	assert.NoError(t, err)
+	actual := make([]string, 0)
+	for _, r := range results {
+		actual = append(actual, fmt.Sprintf("%s:%s:%d:%d", r.Location.Ident, r.Match, r.Refs, r.Location.Position.StartLine))
+	}
+	assert.Equal(t, []string{"PackageGraph:camelcase:3:30", "NewPackageGraph:camelcase:2:12"}, actual)
+
+	// Typos are found in the start of identifiers.
+	results, err = st.SearchSymbols(ctx, symbols.Query{Text: "Copr"})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Corpus", results[0].Location.Ident)
+		assert.Equal(t, symbols.FuzzyMatch, results[0].Match)
+	}
+}
+
+func TestSearch_symbolsLimit(t *testing.T) {
+	symbol := func(version int64, ident, kind string, line int64) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"package": "github.com/korfuri/goref", "version": %d, "ident": %q, "kind": %q, "initials": %q, "position": {"filename": "goref.go", "start_line": %d, "start_col": 6}}`,
+			version, ident, kind, symbols.Initials(ident), line))
+	}
+	hits := &elastic.SearchHits{}
+	for _, source := range []json.RawMessage{
+		symbol(2, "NewPackageGraph", "func", 12),
+		symbol(2, "NewPage", "func", 20),
+		symbol(2, "PackageGraph", "type", 30),
+	} {
+		source := source
+		hits.Hits = append(hits.Hits, &elastic.SearchHit{Source: &source})
+	}
+
+	// With a limit, Refs are only counted for the matches that
+	// may be returned. PackageGraph is the only prefix match of
+	// "Pa", and ranks above the camelCase matches.
+	client := &mocks.Client{}
+	client.On("SearchAfter", mock.Anything, elasticsearch.SymbolType, mock.Anything, []string{"package", "ident", "version"}, []interface{}(nil), 1000).Return(hits, nil)
+	client.On("Search", mock.Anything, elasticsearch.RefType, mock.Anything, 0, 0).Return(&elastic.SearchHits{TotalHits: 3}, nil)
+	st := elasticsearch.NewStore(client, elasticsearch.DefaultBulkConfig)
+	results, err := st.SearchSymbols(context.Background(), symbols.Query{Text: "Pa", Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "PackageGraph", results[0].Location.Ident)
+	client.AssertNumberOfCalls(t, "Search", 1)
+}
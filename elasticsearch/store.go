@@ -0,0 +1,117 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// esStore implements store.Store on top of an ElasticSearch index.
+type esStore struct {
+	client Client
+	config BulkConfig
+}
+
+// NewStore returns a store.Store that keeps packages in the index
+// of the provided Client. Files and Refs are sent in batches as
+// configured by config.
+func NewStore(client Client, config BulkConfig) store.Store {
+	return &esStore{
+		client: client,
+		config: config,
+	}
+}
+
+func (s *esStore) PackageExists(ctx context.Context, loadpath string, version int64) (bool, error) {
+	return packageExists(ctx, loadpath, version, s.client)
+}
+
+// PutPackages indexes the Files, Refs and Symbols of packages, then
+// creates the documents of the packages that were fully indexed.
+func (s *esStore) PutPackages(ctx context.Context, packages []*goref.Package) error {
+	missedRefs := make([]*goref.Ref, 0)
+	missedFiles := make([]string, 0)
+	errs := make([]error, 0)
+
+	bulk, err := s.client.NewBulk(ctx, s.config)
+	if err != nil {
+		return err
+	}
+	filePackages := make(map[string]*goref.Package)
+	pathPackages := make(map[string]*goref.Package)
+	for _, p := range packages {
+		pathPackages[p.Path] = p
+		for _, f := range p.Files {
+			filePackages[f] = p
+			bulk.AddFile(File{
+				Filename: f,
+				Package:  p.Path,
+				Version:  p.Version,
+			})
+		}
+
+		for _, r := range p.OutRefs {
+			bulk.AddRef(r)
+		}
+
+		for _, d := range p.Decls() {
+			bulk.AddSymbol(NewSymbol(d))
+		}
+	}
+
+	failures, bulkErr := bulk.Close()
+	if bulkErr != nil {
+		errs = append(errs, bulkErr)
+	}
+	failed := make(map[*goref.Package]bool)
+	for _, f := range failures {
+		switch doc := f.Doc.(type) {
+		case File:
+			missedFiles = append(missedFiles, doc.Filename)
+			failed[filePackages[doc.Filename]] = true
+			log.Debugf("Create file document failed with err:[%s] for file:[%s]", f.Err, doc.Filename)
+		case *goref.Ref:
+			missedRefs = append(missedRefs, doc)
+			failed[doc.FromPackage] = true
+			log.Debugf("Create Ref document failed with err:[%s] for Ref:[%s]", f.Err, doc)
+		case Symbol:
+			failed[pathPackages[doc.Package]] = true
+			log.Debugf("Create Symbol document failed with err:[%s] for Symbol:[%s.%s]", f.Err, doc.Package, doc.Ident)
+		}
+		errs = append(errs, f.Err)
+	}
+
+	for _, p := range packages {
+		if bulkErr != nil || failed[p] {
+			log.Infof("Package %s was not fully indexed and will be indexed again by the next run.", p)
+			continue
+		}
+		log.Debugf("Creating Package %s in the index", p)
+		if err := s.client.CreatePackage(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		errStr := fmt.Sprintf("%d entries couldn't be imported. Errors were:\n", len(errs))
+		c := 0
+		for _, e := range errs {
+			errStr = errStr + e.Error() + "\n"
+			c = c + 1
+			if c >= maxErrorsReported {
+				break
+			}
+		}
+		return errors.New(errStr)
+	}
+	return nil
+}
+
+// Close is a no-op: the Client has no resources to release.
+func (s *esStore) Close() error {
+	return nil
+}
@@ -0,0 +1,611 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korfuri/goref"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// typelessClient implements Client for ElasticSearch 7 and later,
+// which don't support mapping types. Each type of document is stored
+// in its own index, named after the client's index and the type
+// (e.g. "goref-ref"). It talks to ElasticSearch's REST API directly,
+// as the elastic.v5 client only knows about typed APIs.
+type typelessClient struct {
+	url      string
+	username string
+	password string
+	index    string
+	client   *http.Client
+}
+
+// NewTypelessClient initializes a Client for ElasticSearch 7 and
+// later, at the provided URL. If username is empty, requests are not
+// authenticated.
+func NewTypelessClient(url, username, password, index string) Client {
+	return typelessClient{
+		url:      strings.TrimSuffix(url, "/"),
+		username: username,
+		password: password,
+		index:    index,
+		client:   http.DefaultClient,
+	}
+}
+
+// TypeIndex returns the name of the index that holds documents of the
+// provided type in a typeless layout.
+func TypeIndex(index, typ string) string {
+	return index + "-" + typ
+}
+
+// do sends a request to ElasticSearch and decodes the JSON response
+// into out, if out isn't nil. Error responses are returned as
+// *elastic.Error, so that they can be inspected with e.g.
+// elastic.IsNotFound.
+func (c typelessClient) do(ctx context.Context, method, path string, body io.Reader, contentType string, out interface{}) error {
+	req, err := http.NewRequest(method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		e := &elastic.Error{Status: res.StatusCode}
+		// The body isn't always an error document (e.g. for
+		// documents that aren't found), so decoding errors
+		// are ignored.
+		json.NewDecoder(res.Body).Decode(e)
+		return e
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// doJSON is like do, with a JSON-encoded body.
+func (c typelessClient) doJSON(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	if body == nil {
+		return c.do(ctx, method, path, nil, "", out)
+	}
+	j, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, path, bytes.NewReader(j), "application/json", out)
+}
+
+// docPath returns the path of a document of the provided type.
+func (c typelessClient) docPath(typ, docID string) string {
+	return "/" + TypeIndex(c.index, typ) + "/_doc/" + url.PathEscape(docID)
+}
+
+// withDocID returns the JSON encoding of a document, with its ID
+// added in the docIDField so that searches can be sorted on it.
+func withDocID(doc interface{}, docID string) (json.RawMessage, error) {
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(j, &fields); err != nil {
+		return nil, err
+	}
+	if fields[docIDField], err = json.Marshal(docID); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// putDoc creates or replaces a document of the provided type.
+func (c typelessClient) putDoc(ctx context.Context, typ, docID string, doc interface{}, out interface{}) error {
+	j, err := withDocID(doc, docID)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(ctx, "PUT", c.docPath(typ, docID), j, out)
+}
+
+// GetPackage implements Client for typelessClient
+func (c typelessClient) GetPackage(ctx context.Context, docID string) (*elastic.GetResult, error) {
+	var res elastic.GetResult
+	if err := c.doJSON(ctx, "GET", c.docPath(PackageType, docID), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CreatePackage implements Client for typelessClient
+func (c typelessClient) CreatePackage(ctx context.Context, p *goref.Package) error {
+	return c.putDoc(ctx, PackageType, p.DocumentID(), p, nil)
+}
+
+// CreateFile implements Client for typelessClient
+func (c typelessClient) CreateFile(ctx context.Context, entry File) (*elastic.IndexResponse, error) {
+	var res elastic.IndexResponse
+	if err := c.putDoc(ctx, FileType, entry.DocumentID(), entry, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CreateRef implements Client for typelessClient
+func (c typelessClient) CreateRef(ctx context.Context, r *goref.Ref) (*elastic.IndexResponse, error) {
+	var res elastic.IndexResponse
+	if err := c.putDoc(ctx, RefType, r.DocumentID(), r, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Search implements Client for typelessClient
+func (c typelessClient) Search(ctx context.Context, typ string, query elastic.Query, from, size int) (*elastic.SearchHits, error) {
+	body := map[string]interface{}{
+		"from": from,
+		"size": size,
+		// Totals are only exact up to 10000 by default.
+		"track_total_hits": true,
+	}
+	if query != nil {
+		q, err := query.Source()
+		if err != nil {
+			return nil, err
+		}
+		body["query"] = q
+	}
+	var res struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []*elastic.SearchHit `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.doJSON(ctx, "POST", "/"+TypeIndex(c.index, typ)+"/_search", body, &res); err != nil {
+		return nil, err
+	}
+	return &elastic.SearchHits{
+		TotalHits: res.Hits.Total.Value,
+		Hits:      res.Hits.Hits,
+	}, nil
+}
+
+// SearchAfter implements Client for typelessClient. Documents with
+// the same sort values are sorted by their docIDField, which is unique:
+// _id can't be sorted on in recent versions of ElasticSearch. Sort
+// values are decoded as json.Numbers, as versions don't fit in a
+// float64.
+func (c typelessClient) SearchAfter(ctx context.Context, typ string, query elastic.Query, sort []string, after []interface{}, size int) (*elastic.SearchHits, error) {
+	sorts := make([]interface{}, 0, len(sort)+1)
+	for _, field := range sort {
+		sorts = append(sorts, map[string]string{field: "asc"})
+	}
+	sorts = append(sorts, map[string]string{docIDField: "asc"})
+	body := map[string]interface{}{
+		"size":             size,
+		"sort":             sorts,
+		"track_total_hits": true,
+	}
+	if query != nil {
+		q, err := query.Source()
+		if err != nil {
+			return nil, err
+		}
+		body["query"] = q
+	}
+	if after != nil {
+		body["search_after"] = after
+	}
+	var res struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID     string           `json:"_id"`
+				Source *json.RawMessage `json:"_source"`
+				Sort   json.RawMessage  `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.doJSON(ctx, "POST", "/"+TypeIndex(c.index, typ)+"/_search", body, &res); err != nil {
+		return nil, err
+	}
+	hits := &elastic.SearchHits{TotalHits: res.Hits.Total.Value}
+	for _, h := range res.Hits.Hits {
+		hit := &elastic.SearchHit{Id: h.ID, Source: h.Source}
+		d := json.NewDecoder(bytes.NewReader(h.Sort))
+		d.UseNumber()
+		if err := d.Decode(&hit.Sort); err != nil {
+			return nil, err
+		}
+		hits.Hits = append(hits.Hits, hit)
+	}
+	return hits, nil
+}
+
+// IndexExists implements Client for typelessClient. It returns true
+// if the index of any type exists.
+func (c typelessClient) IndexExists(ctx context.Context) (bool, error) {
+	for typ := range typeMappings() {
+		err := c.doJSON(ctx, "HEAD", "/"+TypeIndex(c.index, typ), nil, nil)
+		if err == nil {
+			return true, nil
+		}
+		if !elastic.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// CreateIndex implements Client for typelessClient. It creates the
+// index of each type in the provided mappings, with the same
+// settings.
+func (c typelessClient) CreateIndex(ctx context.Context, body map[string]interface{}) error {
+	mappings, _ := body["mappings"].(map[string]interface{})
+	for typ, mapping := range mappings {
+		b := map[string]interface{}{
+			"settings": body["settings"],
+			"mappings": mapping,
+		}
+		if err := c.doJSON(ctx, "PUT", "/"+TypeIndex(c.index, typ), b, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMapping implements Client for typelessClient. Types whose index
+// doesn't exist have no mapping.
+func (c typelessClient) GetMapping(ctx context.Context) (map[string]interface{}, error) {
+	mappings := make(map[string]interface{})
+	for typ := range typeMappings() {
+		var res map[string]map[string]interface{}
+		err := c.doJSON(ctx, "GET", "/"+TypeIndex(c.index, typ)+"/_mapping", nil, &res)
+		if elastic.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The response is keyed by the name of the index,
+		// which may be an alias' target.
+		for _, index := range res {
+			mappings[typ] = index["mappings"]
+		}
+	}
+	return mappings, nil
+}
+
+// PackageVersions implements Client for typelessClient. Packages are
+// paged through with search_after, sorted by load path and version.
+func (c typelessClient) PackageVersions(ctx context.Context) (map[string][]int64, error) {
+	versions := make(map[string][]int64)
+	path := "/" + TypeIndex(c.index, PackageType) + "/_search"
+	var after json.RawMessage
+	for {
+		body := map[string]interface{}{
+			"size": scrollSize,
+			"sort": []interface{}{
+				map[string]string{"loadpath": "asc"},
+				map[string]string{"version": "asc"},
+			},
+		}
+		if after != nil {
+			body["search_after"] = after
+		}
+		// Sort values are kept raw, as versions don't fit in
+		// a float64.
+		var res struct {
+			Hits struct {
+				Hits []struct {
+					Source goref.Package   `json:"_source"`
+					Sort   json.RawMessage `json:"sort"`
+				} `json:"hits"`
+			} `json:"hits"`
+		}
+		err := c.doJSON(ctx, "POST", path, body, &res)
+		if elastic.IsNotFound(err) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range res.Hits.Hits {
+			versions[hit.Source.Path] = append(versions[hit.Source.Path], hit.Source.Version)
+			after = hit.Sort
+		}
+		if len(res.Hits.Hits) < scrollSize {
+			return versions, nil
+		}
+	}
+}
+
+// DeletePackageVersions implements Client for typelessClient
+func (c typelessClient) DeletePackageVersions(ctx context.Context, loadpath string, versions []int64) (int64, error) {
+	var deleted int64
+	for _, typ := range deletionOrder {
+		q, err := versionsQuery(typ, loadpath, versions).Source()
+		if err != nil {
+			return deleted, err
+		}
+		var res struct {
+			Deleted int64 `json:"deleted"`
+		}
+		path := "/" + TypeIndex(c.index, typ) + "/_delete_by_query?conflicts=proceed"
+		err = c.doJSON(ctx, "POST", path, map[string]interface{}{"query": q}, &res)
+		if elastic.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted += res.Deleted
+	}
+	return deleted, nil
+}
+
+// AliasTarget implements Client for typelessClient. Each type has its
+// own alias, and they're always updated together, so the alias of
+// packages designates the same index as the other ones.
+func (c typelessClient) AliasTarget(ctx context.Context) (string, error) {
+	var res map[string]interface{}
+	err := c.doJSON(ctx, "GET", "/_alias/"+TypeIndex(c.index, PackageType), nil, &res)
+	if elastic.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for index := range res {
+		return strings.TrimSuffix(index, "-"+PackageType), nil
+	}
+	return "", nil
+}
+
+// SetAliasTarget implements Client for typelessClient. The aliases of
+// all types are updated in a single request, so they are swapped
+// atomically.
+func (c typelessClient) SetAliasTarget(ctx context.Context, target string) error {
+	previous, err := c.AliasTarget(ctx)
+	if err != nil {
+		return err
+	}
+	actions := make([]interface{}, 0)
+	for typ := range typeMappings() {
+		alias := TypeIndex(c.index, typ)
+		if previous != "" {
+			actions = append(actions, map[string]interface{}{
+				"remove": map[string]string{"index": TypeIndex(previous, typ), "alias": alias},
+			})
+		}
+		actions = append(actions, map[string]interface{}{
+			"add": map[string]string{"index": TypeIndex(target, typ), "alias": alias},
+		})
+	}
+	return c.doJSON(ctx, "POST", "/_aliases", map[string]interface{}{"actions": actions}, nil)
+}
+
+// ListIndices implements Client for typelessClient. Indices are
+// listed by the name of their index of packages, without the type.
+func (c typelessClient) ListIndices(ctx context.Context, prefix string) ([]string, error) {
+	var res map[string]interface{}
+	if err := c.doJSON(ctx, "GET", "/"+TypeIndex(prefix+"*", PackageType)+"/_settings", nil, &res); err != nil {
+		return nil, err
+	}
+	indices := make([]string, 0, len(res))
+	for index := range res {
+		indices = append(indices, strings.TrimSuffix(index, "-"+PackageType))
+	}
+	return indices, nil
+}
+
+// NewBulk implements Client for typelessClient
+func (c typelessClient) NewBulk(ctx context.Context, config BulkConfig) (Bulk, error) {
+	b := &typelessBulk{
+		client:   c,
+		ctx:      ctx,
+		config:   config,
+		batches:  make(chan []bulkItem),
+		done:     make(chan struct{}),
+		failures: make([]BulkFailure, 0),
+	}
+	if b.config.BatchSize < 1 {
+		b.config.BatchSize = DefaultBulkConfig.BatchSize
+	}
+	workers := config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		b.workers.Add(1)
+		go b.work()
+	}
+	if config.FlushInterval > 0 {
+		b.ticker = time.NewTicker(config.FlushInterval)
+		b.flusher.Add(1)
+		go b.flushPeriodically()
+	}
+	return b, nil
+}
+
+// bulkItem is a document queued in a typelessBulk.
+type bulkItem struct {
+	index string
+	id    string
+	doc   interface{}
+}
+
+// typelessBulk implements Bulk with the _bulk API. Batches are sent
+// by a pool of workers.
+type typelessBulk struct {
+	client typelessClient
+	ctx    context.Context
+	config BulkConfig
+
+	// mu protects pending and failures.
+	mu       sync.Mutex
+	pending  []bulkItem
+	failures []BulkFailure
+
+	batches chan []bulkItem
+	workers sync.WaitGroup
+	ticker  *time.Ticker
+	flusher sync.WaitGroup
+	done    chan struct{}
+}
+
+// add queues a document, and sends the pending batch if it's full.
+func (b *typelessBulk) add(typ, id string, doc interface{}) {
+	b.mu.Lock()
+	b.pending = append(b.pending, bulkItem{
+		index: TypeIndex(b.client.index, typ),
+		id:    id,
+		doc:   doc,
+	})
+	var batch []bulkItem
+	if len(b.pending) >= b.config.BatchSize {
+		batch, b.pending = b.pending, nil
+	}
+	b.mu.Unlock()
+	if batch != nil {
+		b.batches <- batch
+	}
+}
+
+// AddFile implements Bulk for typelessBulk
+func (b *typelessBulk) AddFile(f File) {
+	b.add(FileType, f.DocumentID(), f)
+}
+
+// AddRef implements Bulk for typelessBulk
+func (b *typelessBulk) AddRef(r *goref.Ref) {
+	b.add(RefType, r.DocumentID(), r)
+}
+
+// AddSymbol implements Bulk for typelessBulk
+func (b *typelessBulk) AddSymbol(s Symbol) {
+	b.add(SymbolType, s.DocumentID(), s)
+}
+
+// flush sends the pending batch, if any.
+func (b *typelessBulk) flush() {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		b.batches <- batch
+	}
+}
+
+// flushPeriodically flushes the pending batch at every tick, until
+// the Bulk is closed.
+func (b *typelessBulk) flushPeriodically() {
+	defer b.flusher.Done()
+	for {
+		select {
+		case <-b.ticker.C:
+			b.flush()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// work sends batches until the Bulk is closed.
+func (b *typelessBulk) work() {
+	defer b.workers.Done()
+	for batch := range b.batches {
+		b.send(batch)
+	}
+}
+
+// bulkResponse is the response to a _bulk request.
+type bulkResponse struct {
+	Errors bool                                   `json:"errors"`
+	Items  []map[string]*elastic.BulkResponseItem `json:"items"`
+}
+
+// send sends a batch with a single _bulk request and records the
+// documents that failed to be indexed. Items of the response are in
+// the same order as the request's documents.
+func (b *typelessBulk) send(batch []bulkItem) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	var err error
+	for _, item := range batch {
+		action := map[string]interface{}{
+			"index": map[string]string{"_index": item.index, "_id": item.id},
+		}
+		if err = enc.Encode(action); err != nil {
+			break
+		}
+		var doc json.RawMessage
+		if doc, err = withDocID(item.doc, item.id); err != nil {
+			break
+		}
+		if err = enc.Encode(doc); err != nil {
+			break
+		}
+	}
+	var res bulkResponse
+	if err == nil {
+		err = b.client.do(b.ctx, "POST", "/_bulk", &body, "application/x-ndjson", &res)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, item := range batch {
+		itemErr := err
+		if itemErr == nil && i < len(res.Items) {
+			for _, r := range res.Items[i] {
+				if r.Error != nil {
+					itemErr = fmt.Errorf("%s: %s", r.Error.Type, r.Error.Reason)
+				}
+			}
+		}
+		if itemErr != nil {
+			b.failures = append(b.failures, BulkFailure{
+				Doc: item.doc,
+				Err: itemErr,
+			})
+		}
+	}
+}
+
+// Close implements Bulk for typelessBulk
+func (b *typelessBulk) Close() ([]BulkFailure, error) {
+	if b.ticker != nil {
+		b.ticker.Stop()
+	}
+	close(b.done)
+	b.flusher.Wait()
+	b.flush()
+	close(b.batches)
+	b.workers.Wait()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures, nil
+}
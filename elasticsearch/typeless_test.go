@@ -0,0 +1,175 @@
+package elasticsearch_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/elasticsearch"
+	"github.com/korfuri/goref/elasticsearch/estest"
+	"github.com/stretchr/testify/assert"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+func TestTypelessClient_ensureIndex(t *testing.T) {
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	ctx := context.Background()
+
+	exists, err := client.IndexExists(ctx)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	// The first call creates one index per type, the second one
+	// finds that their mappings are compatible.
+	assert.NoError(t, elasticsearch.EnsureIndex(ctx, client))
+	assert.NoError(t, elasticsearch.EnsureIndex(ctx, client))
+	exists, err = client.IndexExists(ctx)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// An index created with a dynamic mapping is rejected.
+	other := elasticsearch.NewTypelessClient(s.URL, "", "", "other")
+	_, err = other.CreateFile(ctx, elasticsearch.File{Filename: "a.go"})
+	assert.NoError(t, err)
+	assert.Error(t, elasticsearch.EnsureIndex(ctx, other))
+}
+
+func TestTypelessClient_packages(t *testing.T) {
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	ctx := context.Background()
+
+	p := &goref.Package{Path: "github.com/korfuri/goref", Version: 42}
+	_, err := client.GetPackage(ctx, p.DocumentID())
+	assert.True(t, elastic.IsNotFound(err))
+
+	assert.NoError(t, client.CreatePackage(ctx, p))
+	res, err := client.GetPackage(ctx, p.DocumentID())
+	assert.NoError(t, err)
+	assert.True(t, res.Found)
+	var doc goref.Package
+	assert.NoError(t, json.Unmarshal(*res.Source, &doc))
+	assert.Equal(t, "github.com/korfuri/goref", doc.Path)
+	exists, err := elasticsearch.PackageExists(p.Path, p.Version, client)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = elasticsearch.PackageExists(p.Path, p.Version+1, client)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestTypelessClient_loadGraph(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	pg.ComputeInterfaceImplementationMatrix()
+	refs := 0
+	for _, p := range pg.Packages {
+		refs += len(p.OutRefs)
+	}
+
+	// Small batches, so that several bulk requests are sent.
+	config := elasticsearch.BulkConfig{BatchSize: 7, FlushInterval: time.Millisecond, Workers: 3}
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, config))
+	assert.Len(t, s.Documents("goref-package"), len(pg.Packages))
+	assert.Len(t, s.Documents("goref-ref"), refs)
+	assert.Contains(t, s.Documents("goref-file"), "v1@0@"+pkgpath+"/main.go")
+
+	// Indexing the same packages again at the same version
+	// overwrites their documents instead of duplicating them.
+	// LoadGraphToElastic skips packages that already exist, so they
+	// are put directly.
+	packages := make([]*goref.Package, 0)
+	for _, p := range pg.Packages {
+		packages = append(packages, p)
+	}
+	st := elasticsearch.NewStore(client, config)
+	assert.NoError(t, st.PutPackages(context.Background(), packages))
+	assert.Len(t, s.Documents("goref-package"), len(pg.Packages))
+	assert.Len(t, s.Documents("goref-ref"), refs)
+}
+
+func TestTypelessClient_loadGraphNewVersion(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	pg.ComputeInterfaceImplementationMatrix()
+	refs := 0
+	for _, p := range pg.Packages {
+		refs += len(p.OutRefs)
+	}
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
+
+	// A new version of the packages is indexed alongside the
+	// previous one.
+	for _, p := range pg.Packages {
+		p.Version = 1
+	}
+	assert.NoError(t, elasticsearch.LoadGraphToElastic(*pg, client, elasticsearch.DefaultBulkConfig))
+	assert.Len(t, s.Documents("goref-package"), 2*len(pg.Packages))
+	assert.Len(t, s.Documents("goref-ref"), 2*refs)
+	assert.Contains(t, s.Documents("goref-file"), "v1@1@"+pkgpath+"/main.go")
+}
+
+func TestTypelessClient_searchAfterTies(t *testing.T) {
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	ctx := context.Background()
+	assert.NoError(t, elasticsearch.EnsureIndex(ctx, client))
+
+	// The files have the same package and version, so they're only
+	// told apart by their document ID when paging through them.
+	for _, filename := range []string{"a.go", "b.go", "c.go"} {
+		_, err := client.CreateFile(ctx, elasticsearch.File{Filename: filename, Package: "a", Version: 1})
+		assert.NoError(t, err)
+	}
+	ids := make([]string, 0)
+	var after []interface{}
+	for {
+		hits, err := client.SearchAfter(ctx, elasticsearch.FileType, nil, []string{"package"}, after, 1)
+		assert.NoError(t, err)
+		if len(hits.Hits) == 0 {
+			break
+		}
+		ids = append(ids, hits.Hits[0].Id)
+		after = hits.Hits[0].Sort
+	}
+	assert.Equal(t, s.Documents("goref-file"), ids)
+}
+
+func TestTypelessClient_defaultBatchSize(t *testing.T) {
+	s := estest.NewServer()
+	defer s.Close()
+	client := elasticsearch.NewTypelessClient(s.URL, "", "", "goref")
+	assert.NoError(t, elasticsearch.EnsureIndex(context.Background(), client))
+
+	// Documents are held until the default batch is full, or the
+	// Bulk is closed.
+	bulk, err := client.NewBulk(context.Background(), elasticsearch.BulkConfig{})
+	assert.NoError(t, err)
+	bulk.AddFile(elasticsearch.File{Filename: "a.go", Package: "a"})
+	bulk.AddFile(elasticsearch.File{Filename: "b.go", Package: "a"})
+	assert.Empty(t, s.Documents("goref-file"))
+	failures, err := bulk.Close()
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.Len(t, s.Documents("goref-file"), 2)
+}
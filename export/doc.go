@@ -0,0 +1,4 @@
+// Package export writes the contents of a PackageGraph in formats
+// understood by other tools, so that goref's cross-package references
+// can be used outside of goref.
+package export
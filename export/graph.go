@@ -0,0 +1,179 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/korfuri/goref"
+)
+
+// nodeIDs returns the index of each node of a Graph, which the
+// writers use as node IDs so that they're unambiguous.
+func nodeIDs(g *goref.Graph) map[goref.GraphNode]int {
+	ids := make(map[goref.GraphNode]int)
+	for i, n := range g.Nodes {
+		ids[n] = i
+	}
+	return ids
+}
+
+// dotQuote quotes a string for DOT.
+func dotQuote(s string) string {
+	return `"` + strings.Replace(strings.Replace(s, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+}
+
+// WriteDOT writes a Graph in Graphviz's DOT language. Edges are
+// labeled with their type and, if they stand for more than one Ref,
+// their count.
+func WriteDOT(w io.Writer, g *goref.Graph) error {
+	ids := nodeIDs(g)
+	lines := []string{"digraph goref {"}
+	for i, n := range g.Nodes {
+		lines = append(lines, fmt.Sprintf("  n%d [label=%s];", i, dotQuote(n.String())))
+	}
+	for _, e := range g.Edges {
+		label := e.RefType.String()
+		if e.Count > 1 {
+			label = fmt.Sprintf("%s (%d)", label, e.Count)
+		}
+		lines = append(lines, fmt.Sprintf("  n%d -> n%d [label=%s, weight=%d];", ids[e.From], ids[e.To], dotQuote(label), e.Count))
+	}
+	lines = append(lines, "}")
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// WriteGraphML writes a Graph in GraphML. Nodes have package and
+// ident attributes, and edges have type and count attributes.
+func WriteGraphML(w io.Writer, g *goref.Graph) error {
+	ids := nodeIDs(g)
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "package", For: "node", Name: "package", Type: "string"},
+			{ID: "ident", For: "node", Name: "ident", Type: "string"},
+			{ID: "type", For: "edge", Name: "type", Type: "string"},
+			{ID: "count", For: "edge", Name: "count", Type: "int"},
+		},
+	}
+	doc.Graph.ID = "goref"
+	doc.Graph.EdgeDefault = "directed"
+	for i, n := range g.Nodes {
+		node := graphMLNode{
+			ID:   fmt.Sprintf("n%d", i),
+			Data: []graphMLData{{Key: "package", Value: n.Package}},
+		}
+		if n.Ident != "" {
+			node.Data = append(node.Data, graphMLData{Key: "ident", Value: n.Ident})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: fmt.Sprintf("n%d", ids[e.From]),
+			Target: fmt.Sprintf("n%d", ids[e.To]),
+			Data: []graphMLData{
+				{Key: "type", Value: e.RefType.String()},
+				{Key: "count", Value: fmt.Sprint(e.Count)},
+			},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type nodeLinkNode struct {
+	ID      int    `json:"id"`
+	Package string `json:"package"`
+	Ident   string `json:"ident,omitempty"`
+	Label   string `json:"label"`
+}
+
+type nodeLinkLink struct {
+	Source int    `json:"source"`
+	Target int    `json:"target"`
+	Type   string `json:"type"`
+	Count  int    `json:"count"`
+}
+
+// WriteNodeLinkJSON writes a Graph as node-link JSON, as read by
+// d3.js and networkx's node_link_graph. Links refer to nodes by their
+// id.
+func WriteNodeLinkJSON(w io.Writer, g *goref.Graph) error {
+	ids := nodeIDs(g)
+	doc := struct {
+		Directed   bool              `json:"directed"`
+		Multigraph bool              `json:"multigraph"`
+		Graph      map[string]string `json:"graph"`
+		Nodes      []nodeLinkNode    `json:"nodes"`
+		Links      []nodeLinkLink    `json:"links"`
+	}{
+		Directed:   true,
+		Multigraph: true,
+		Graph:      map[string]string{"name": "goref"},
+		Nodes:      make([]nodeLinkNode, 0, len(g.Nodes)),
+		Links:      make([]nodeLinkLink, 0, len(g.Edges)),
+	}
+	for i, n := range g.Nodes {
+		doc.Nodes = append(doc.Nodes, nodeLinkNode{ID: i, Package: n.Package, Ident: n.Ident, Label: n.String()})
+	}
+	for _, e := range g.Edges {
+		doc.Links = append(doc.Links, nodeLinkLink{
+			Source: ids[e.From],
+			Target: ids[e.To],
+			Type:   e.RefType.String(),
+			Count:  e.Count,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
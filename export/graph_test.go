@@ -0,0 +1,78 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/export"
+	"github.com/stretchr/testify/assert"
+)
+
+// testGraph returns a Graph of two nodes with two edges.
+func testGraph() *goref.Graph {
+	a := goref.GraphNode{Package: "a"}
+	b := goref.GraphNode{Package: "b", Ident: `T"x`}
+	return &goref.Graph{
+		Nodes: []goref.GraphNode{a, b},
+		Edges: []*goref.GraphEdge{
+			{From: a, To: b, RefType: goref.Call, Count: 3},
+			{From: b, To: a, RefType: goref.Import, Count: 1},
+		},
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteDOT(&buf, testGraph()))
+	assert.Equal(t, `digraph goref {
+  n0 [label="a"];
+  n1 [label="b.T\"x"];
+  n0 -> n1 [label="Call (3)", weight=3];
+  n1 -> n0 [label="Import", weight=1];
+}
+`, buf.String())
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteGraphML(&buf, testGraph()))
+	var doc struct {
+		Nodes []struct {
+			ID   string `xml:"id,attr"`
+			Data []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"graph>edge"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(t, doc.Nodes, 2)
+	assert.Equal(t, "n1", doc.Nodes[1].ID)
+	assert.Equal(t, `T"x`, doc.Nodes[1].Data[1].Value)
+	assert.Len(t, doc.Edges, 2)
+	assert.Equal(t, "n0", doc.Edges[0].Source)
+	assert.Equal(t, "n1", doc.Edges[0].Target)
+}
+
+func TestWriteNodeLinkJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteNodeLinkJSON(&buf, testGraph()))
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, true, doc["directed"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": 0.0, "package": "a", "label": "a"},
+		map[string]interface{}{"id": 1.0, "package": "b", "ident": `T"x`, "label": `b.T"x`},
+	}, doc["nodes"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"source": 0.0, "target": 1.0, "type": "Call", "count": 3.0},
+		map[string]interface{}{"source": 1.0, "target": 0.0, "type": "Import", "count": 1.0},
+	}, doc["links"])
+}
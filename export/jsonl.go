@@ -0,0 +1,213 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+)
+
+// A jsonlRecord is a line of a JSONL export. Exactly one of its
+// fields is set. Refs are encoded as pb.Ref messages in the proto3
+// JSON mapping, with the field names of ref.proto.
+type jsonlRecord struct {
+	Package *jsonlPackage   `json:"package,omitempty"`
+	File    *jsonlFile      `json:"file,omitempty"`
+	Decl    *jsonlDecl      `json:"decl,omitempty"`
+	Ref     json.RawMessage `json:"ref,omitempty"`
+}
+
+// jsonlPackage is a package record: the fields of a Package's JSON
+// encoding, and its name.
+type jsonlPackage struct {
+	Path    string `json:"loadpath"`
+	Version int64  `json:"version"`
+	Name    string `json:"name,omitempty"`
+}
+
+// jsonlFile is a file record, with the same fields as the file
+// documents of package elasticsearch.
+type jsonlFile struct {
+	Filename string `json:"filename"`
+	Package  string `json:"package"`
+	Version  int64  `json:"version"`
+}
+
+// jsonlDecl is a declaration record, with the fields of a Decl other
+// than its types.Object. Positions are encoded as pb.Positions, like
+// those of Refs.
+type jsonlDecl struct {
+	Kind     string       `json:"kind"`
+	Name     string       `json:"name"`
+	Recv     string       `json:"recv,omitempty"`
+	Package  string       `json:"package"`
+	Position *pb.Position `json:"position"`
+	Extent   *pb.Position `json:"extent"`
+}
+
+// WriteJSONL writes the packages of a PackageGraph for which include
+// returns true (or all packages if include is nil) as JSON lines.
+// Each package is written as a package record followed by a record
+// for each of its files, Decls and OutRefs, so that ReadJSONL can read
+// packages one at a time. Records are written as they're encoded,
+// without buffering the output.
+func WriteJSONL(w io.Writer, pg *goref.PackageGraph, include func(loadpath string) bool) error {
+	enc := json.NewEncoder(w)
+	m := jsonpb.Marshaler{OrigName: true}
+	packages := make([]*goref.Package, 0)
+	for _, p := range pg.Packages {
+		if include == nil || include(p.Path) {
+			packages = append(packages, p)
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	for _, p := range packages {
+		if err := enc.Encode(jsonlRecord{Package: &jsonlPackage{Path: p.Path, Version: p.Version, Name: p.Name}}); err != nil {
+			return err
+		}
+		for _, f := range p.Files {
+			if err := enc.Encode(jsonlRecord{File: &jsonlFile{Filename: f, Package: p.Path, Version: p.Version}}); err != nil {
+				return err
+			}
+		}
+		for _, d := range p.Decls() {
+			rec := jsonlRecord{Decl: &jsonlDecl{
+				Kind:     d.Kind.String(),
+				Name:     d.Name,
+				Recv:     d.Recv,
+				Package:  p.Path,
+				Position: d.Position.ToProto(),
+				Extent:   d.Extent.ToProto(),
+			}}
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		for _, r := range p.OutRefs {
+			var buf bytes.Buffer
+			if err := m.Marshal(&buf, r.ToProto()); err != nil {
+				return err
+			}
+			if err := enc.Encode(jsonlRecord{Ref: buf.Bytes()}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// positionFromProto returns the Position of a pb.Position.
+func positionFromProto(p *pb.Position) goref.Position {
+	if p == nil {
+		return goref.Position{}
+	}
+	return goref.Position{
+		File: p.Filename,
+		PosL: int(p.StartLine),
+		PosC: int(p.StartCol),
+		EndL: int(p.EndLine),
+		EndC: int(p.EndCol),
+	}
+}
+
+// ReadJSONL reads packages written by WriteJSONL and calls f with
+// each of them, with their Files, Decls and OutRefs. Only one package
+// is held in memory at a time, so exports of any size can be read.
+//
+// Packages read this way have no type information: their Decls are
+// set with SetDecls and have no Object, and the targets of their Refs
+// are packages that only have a load path.
+func ReadJSONL(r io.Reader, f func(p *goref.Package) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var current *goref.Package
+	var decls []*goref.Decl
+	// Targets of the current package's Refs, by load path.
+	targets := make(map[string]*goref.Package)
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		p := current
+		p.SetDecls(decls)
+		current = nil
+		decls = nil
+		targets = make(map[string]*goref.Package)
+		return f(p)
+	}
+	for line := 1; ; line++ {
+		var rec jsonlRecord
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("Invalid record on line %d: %s", line, err)
+		}
+		switch {
+		case rec.Package != nil:
+			if err := flush(); err != nil {
+				return err
+			}
+			current = &goref.Package{
+				Path:    rec.Package.Path,
+				Version: rec.Package.Version,
+				Name:    rec.Package.Name,
+				Files:   make([]string, 0),
+				OutRefs: make([]*goref.Ref, 0),
+			}
+		case rec.File != nil:
+			if current == nil || rec.File.Package != current.Path {
+				return fmt.Errorf("File record on line %d doesn't follow its package record", line)
+			}
+			current.Files = append(current.Files, rec.File.Filename)
+		case rec.Decl != nil:
+			if current == nil || rec.Decl.Package != current.Path {
+				return fmt.Errorf("Declaration record on line %d doesn't follow its package record", line)
+			}
+			kind, err := goref.ParseDeclKind(rec.Decl.Kind)
+			if err != nil {
+				return fmt.Errorf("Invalid declaration on line %d: %s", line, err)
+			}
+			decls = append(decls, &goref.Decl{
+				Kind:     kind,
+				Name:     rec.Decl.Name,
+				Recv:     rec.Decl.Recv,
+				Package:  current,
+				Position: positionFromProto(rec.Decl.Position),
+				Extent:   positionFromProto(rec.Decl.Extent),
+			})
+		case rec.Ref != nil:
+			var ref pb.Ref
+			if err := jsonpb.Unmarshal(bytes.NewReader(rec.Ref), &ref); err != nil {
+				return fmt.Errorf("Invalid Ref on line %d: %s", line, err)
+			}
+			if current == nil || ref.From == nil || ref.To == nil || ref.From.Package != current.Path {
+				return fmt.Errorf("Ref record on line %d doesn't follow its package record", line)
+			}
+			to, in := targets[ref.To.Package]
+			if !in {
+				to = &goref.Package{Path: ref.To.Package}
+				if to.Path == current.Path {
+					to = current
+				}
+				targets[to.Path] = to
+			}
+			current.OutRefs = append(current.OutRefs, &goref.Ref{
+				RefType:      goref.RefType(ref.Type),
+				FromIdent:    ref.From.Ident,
+				FromPackage:  current,
+				FromPosition: positionFromProto(ref.From.Position),
+				ToIdent:      ref.To.Ident,
+				ToPackage:    to,
+				ToPosition:   positionFromProto(ref.To.Position),
+			})
+		default:
+			return fmt.Errorf("Empty record on line %d", line)
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,113 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/export"
+	"github.com/korfuri/goref/store/bolt"
+	"github.com/korfuri/goref/symbols"
+	"github.com/stretchr/testify/assert"
+)
+
+// refStrings returns the sorted proto text of a package's OutRefs.
+func refStrings(p *goref.Package) []string {
+	l := make([]string, 0, len(p.OutRefs))
+	for _, r := range p.OutRefs {
+		l = append(l, r.ToProto().String())
+	}
+	sort.Strings(l)
+	return l
+}
+
+// declStrings returns a description of each of a package's Decls.
+func declStrings(p *goref.Package) []string {
+	l := make([]string, 0)
+	for _, d := range p.Decls() {
+		l = append(l, fmt.Sprintf("%s at %s in %s", d, d.Position, d.Extent))
+	}
+	return l
+}
+
+func TestJSONL_roundTrip(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(3))
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+
+	var buf bytes.Buffer
+	include := func(loadpath string) bool { return strings.HasPrefix(loadpath, pkgpath) }
+	assert.NoError(t, export.WriteJSONL(&buf, pg, include))
+
+	// Each line is a single record.
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal([]byte(line), &rec))
+		assert.Len(t, rec, 1)
+	}
+
+	read := make([]*goref.Package, 0)
+	assert.NoError(t, export.ReadJSONL(&buf, func(p *goref.Package) error {
+		read = append(read, p)
+		return nil
+	}))
+	assert.Len(t, read, 2)
+	for _, p := range read {
+		expected := pg.Packages[p.Path]
+		if !assert.NotNil(t, expected, p.Path) {
+			continue
+		}
+		assert.Equal(t, expected.Name, p.Name)
+		assert.Equal(t, int64(3), p.Version)
+		assert.Equal(t, expected.Files, p.Files)
+		assert.Equal(t, refStrings(expected), refStrings(p))
+		assert.Equal(t, declStrings(expected), declStrings(p))
+		assert.NotEmpty(t, p.Decls())
+	}
+}
+
+func TestReadJSONL_invalid(t *testing.T) {
+	noop := func(*goref.Package) error { return nil }
+	assert.Error(t, export.ReadJSONL(strings.NewReader(`{"file":{"filename":"a.go","package":"a","version":1}}`), noop))
+	assert.Error(t, export.ReadJSONL(strings.NewReader(`{"package":{"loadpath":"a","version":1}}
+{"ref":{"from":{"package":"b"},"to":{"package":"a"}}}`), noop))
+	assert.Error(t, export.ReadJSONL(strings.NewReader(`{"package":{"loadpath":"a","version":1}}
+{"decl":{"kind":"nope","name":"A","package":"a"}}`), noop))
+	assert.Error(t, export.ReadJSONL(strings.NewReader(`{}`), noop))
+	assert.Error(t, export.ReadJSONL(strings.NewReader(`{"package":`), noop))
+}
+
+func TestJSONL_symbols(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteJSONL(&buf, pg, nil))
+
+	// The symbols of packages imported into a Store can be
+	// searched.
+	dir, err := ioutil.TempDir("", "goref-jsonl")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := bolt.Open(filepath.Join(dir, "goref.db"))
+	assert.NoError(t, err)
+	defer s.Close()
+	ctx := context.Background()
+	assert.NoError(t, export.ReadJSONL(&buf, func(p *goref.Package) error {
+		return s.PutPackages(ctx, []*goref.Package{p})
+	}))
+	results, err := s.SearchSymbols(ctx, symbols.Query{Text: "IfaceLibA", PackagePrefix: pkgpath})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, "IfaceLibA", results[0].Location.Ident)
+		assert.Equal(t, "type", results[0].Kind)
+	}
+}
@@ -0,0 +1,370 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/korfuri/goref"
+)
+
+const (
+	// LSIFVersion is the version of the LSIF specification
+	// implemented by WriteLSIF.
+	LSIFVersion = "0.4.3"
+
+	// Scheme of the monikers of exported symbols. Their identifier
+	// is the load path of their package and their qualified
+	// identifier, e.g. "net/http:Request.URL".
+	lsifScheme = "goref"
+)
+
+// lsifPos is a position in an LSIF document, with 0-based lines and
+// characters.
+type lsifPos struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lsifToolInfo struct {
+	Name string `json:"name"`
+}
+
+// lsifElement is a vertex or an edge of an LSIF dump. Fields that
+// don't apply to an element are left empty.
+type lsifElement struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+
+	// Vertices
+	Version          string        `json:"version,omitempty"`
+	ProjectRoot      string        `json:"projectRoot,omitempty"`
+	PositionEncoding string        `json:"positionEncoding,omitempty"`
+	ToolInfo         *lsifToolInfo `json:"toolInfo,omitempty"`
+	Kind             string        `json:"kind,omitempty"`
+	URI              string        `json:"uri,omitempty"`
+	LanguageID       string        `json:"languageId,omitempty"`
+	Start            *lsifPos      `json:"start,omitempty"`
+	End              *lsifPos      `json:"end,omitempty"`
+	Scheme           string        `json:"scheme,omitempty"`
+	Identifier       string        `json:"identifier,omitempty"`
+
+	// Edges
+	OutV     int    `json:"outV,omitempty"`
+	InV      int    `json:"inV,omitempty"`
+	InVs     []int  `json:"inVs,omitempty"`
+	Document int    `json:"document,omitempty"`
+	Property string `json:"property,omitempty"`
+}
+
+// lsifWriter assigns IDs to elements and writes them. It stops
+// writing after the first error, which is kept in err.
+type lsifWriter struct {
+	enc *json.Encoder
+	id  int
+	err error
+}
+
+func (w *lsifWriter) vertex(label string, e lsifElement) int {
+	w.id++
+	e.ID, e.Type, e.Label = w.id, "vertex", label
+	if w.err == nil {
+		w.err = w.enc.Encode(e)
+	}
+	return w.id
+}
+
+// edge writes a 1:1 edge.
+func (w *lsifWriter) edge(label string, outV, inV int) {
+	w.edgeN(label, outV, nil, lsifElement{InV: inV})
+}
+
+// edgeN writes a 1:n edge.
+func (w *lsifWriter) edgeN(label string, outV int, inVs []int, e lsifElement) {
+	w.id++
+	e.ID, e.Type, e.Label, e.OutV, e.InVs = w.id, "edge", label, outV, inVs
+	if w.err == nil {
+		w.err = w.enc.Encode(e)
+	}
+}
+
+// rangeKey identifies a range by its start.
+type rangeKey struct {
+	file      string
+	line, col int
+}
+
+func keyOf(p goref.Position) rangeKey {
+	return rangeKey{p.File, p.PosL, p.PosC}
+}
+
+func (k rangeKey) less(o rangeKey) bool {
+	if k.file != o.file {
+		return k.file < o.file
+	}
+	if k.line != o.line {
+		return k.line < o.line
+	}
+	return k.col < o.col
+}
+
+// An lsifRange is an identifier in a document. It refers to, or
+// defines, at most one symbol.
+type lsifRange struct {
+	key    rangeKey
+	end    lsifPos
+	doc    int
+	symbol *lsifSymbol
+	id     int
+}
+
+// An lsifSymbol is a declaration that's the target of Refs.
+type lsifSymbol struct {
+	key        rangeKey
+	identifier string
+	def        *lsifRange
+	refs       []*lsifRange
+	impls      []*lsifRange
+}
+
+// lsifDump is the state built from a PackageGraph before it's
+// written.
+type lsifDump struct {
+	docs    map[string]int
+	ranges  map[rangeKey]*lsifRange
+	symbols map[rangeKey]*lsifSymbol
+
+	// Qualified identifiers of the declarations of each package,
+	// by position.
+	decls map[*goref.Package]map[rangeKey]string
+}
+
+// rangeAt returns the range of an identifier at a position, or nil if
+// its file isn't in the dump. Positions without an end are assumed to
+// span ident.
+func (d *lsifDump) rangeAt(p goref.Position, ident string) *lsifRange {
+	doc, in := d.docs[p.File]
+	if !in {
+		return nil
+	}
+	k := keyOf(p)
+	if r, in := d.ranges[k]; in {
+		return r
+	}
+	r := &lsifRange{
+		key: k,
+		end: lsifPos{Line: p.PosL - 1, Character: p.PosC - 1 + len(ident)},
+		doc: doc,
+	}
+	if p.EndL > 0 {
+		r.end = lsifPos{Line: p.EndL - 1, Character: p.EndC - 1}
+	}
+	d.ranges[k] = r
+	return r
+}
+
+// identifier returns the moniker identifier of the target of a Ref.
+func (d *lsifDump) identifier(r *goref.Ref) string {
+	if r.RefType == goref.Import {
+		return r.ToPackage.Path
+	}
+	p := r.ToPackage
+	if _, in := d.decls[p]; !in {
+		d.decls[p] = make(map[rangeKey]string)
+		for _, decl := range p.Decls() {
+			d.decls[p][keyOf(decl.Position)] = decl.Ident()
+		}
+	}
+	if ident, in := d.decls[p][keyOf(r.ToPosition)]; in {
+		return p.Path + ":" + ident
+	}
+	return p.Path + ":" + r.ToIdent
+}
+
+// addRef adds a Ref's source and target to the dump.
+func (d *lsifDump) addRef(r *goref.Ref) {
+	k := keyOf(r.ToPosition)
+	sym, in := d.symbols[k]
+	if !in {
+		sym = &lsifSymbol{
+			key:        k,
+			identifier: d.identifier(r),
+			def:        d.rangeAt(r.ToPosition, r.ToIdent),
+		}
+		if sym.def != nil && sym.def.symbol == nil {
+			sym.def.symbol = sym
+		}
+		d.symbols[k] = sym
+	}
+	from := d.rangeAt(r.FromPosition, r.FromIdent)
+	if from == nil {
+		return
+	}
+	switch r.RefType {
+	case goref.Implementation, goref.Extension:
+		// The source of these Refs is the definition of the
+		// implementing type, which may be a symbol of its
+		// own.
+		sym.impls = append(sym.impls, from)
+	default:
+		if from.symbol == nil {
+			from.symbol = sym
+		}
+		sym.refs = append(sym.refs, from)
+	}
+}
+
+// sortRanges sorts ranges by position, dropping duplicates.
+func sortRanges(ranges []*lsifRange) []*lsifRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].key.less(ranges[j].key) })
+	l := make([]*lsifRange, 0, len(ranges))
+	for i, r := range ranges {
+		if i == 0 || r != ranges[i-1] {
+			l = append(l, r)
+		}
+	}
+	return l
+}
+
+// byDocument calls f with the ids of the ranges of each document.
+// Ranges are sorted, so those of a document are consecutive.
+func byDocument(ranges []*lsifRange, f func(doc int, ids []int)) {
+	for i := 0; i < len(ranges); {
+		doc := ranges[i].doc
+		ids := make([]int, 0)
+		for ; i < len(ranges) && ranges[i].doc == doc; i++ {
+			ids = append(ids, ranges[i].id)
+		}
+		f(doc, ids)
+	}
+}
+
+// items writes item edges from a result to ranges, one per document.
+func (w *lsifWriter) items(outV int, ranges []*lsifRange, property string) {
+	byDocument(ranges, func(doc int, ids []int) {
+		w.edgeN("item", outV, ids, lsifElement{Document: doc, Property: property})
+	})
+}
+
+// WriteLSIF writes an LSIF dump of a PackageGraph, with a document
+// for each file of the packages for which include returns true (or
+// of all packages if include is nil). Symbols are linked to their
+// definition, references and implementations, and get a moniker so
+// that dumps of different projects can be linked. root is the
+// absolute path of the project's root directory.
+//
+// goref's columns are byte offsets, which LSIF consumers treat as
+// UTF-16 offsets: ranges are only exact on ASCII lines.
+func WriteLSIF(w io.Writer, pg *goref.PackageGraph, root string, include func(loadpath string) bool) error {
+	d := &lsifDump{
+		docs:    make(map[string]int),
+		ranges:  make(map[rangeKey]*lsifRange),
+		symbols: make(map[rangeKey]*lsifSymbol),
+		decls:   make(map[*goref.Package]map[rangeKey]string),
+	}
+	lw := &lsifWriter{enc: json.NewEncoder(w)}
+
+	lw.vertex("metaData", lsifElement{
+		Version:          LSIFVersion,
+		ProjectRoot:      fileURI(root),
+		PositionEncoding: "utf-16",
+		ToolInfo:         &lsifToolInfo{Name: "goref"},
+	})
+	project := lw.vertex("project", lsifElement{Kind: "go"})
+
+	packages := make([]*goref.Package, 0)
+	for _, p := range pg.Packages {
+		if p.Corpus != "" && (include == nil || include(p.Path)) {
+			packages = append(packages, p)
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	docs := make([]int, 0)
+	for _, p := range packages {
+		for _, f := range p.Files {
+			if _, in := d.docs[f]; in {
+				continue
+			}
+			d.docs[f] = lw.vertex("document", lsifElement{
+				URI:        fileURI(p.Corpus.Abs(f)),
+				LanguageID: "go",
+			})
+			docs = append(docs, d.docs[f])
+		}
+	}
+	if len(docs) > 0 {
+		lw.edgeN("contains", project, docs, lsifElement{})
+	}
+
+	for _, p := range packages {
+		for _, r := range p.OutRefs {
+			d.addRef(r)
+		}
+		for _, r := range p.LocalRefs {
+			d.addRef(r)
+		}
+	}
+
+	symbols := make([]*lsifSymbol, 0, len(d.symbols))
+	resultSets := make(map[*lsifSymbol]int)
+	for _, sym := range d.symbols {
+		symbols = append(symbols, sym)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].key.less(symbols[j].key) })
+	for _, sym := range symbols {
+		rs := lw.vertex("resultSet", lsifElement{})
+		resultSets[sym] = rs
+		kind := "import"
+		if sym.def != nil {
+			kind = "export"
+		}
+		moniker := lw.vertex("moniker", lsifElement{Kind: kind, Scheme: lsifScheme, Identifier: sym.identifier})
+		lw.edge("moniker", rs, moniker)
+	}
+
+	ranges := make([]*lsifRange, 0, len(d.ranges))
+	for _, r := range d.ranges {
+		ranges = append(ranges, r)
+	}
+	ranges = sortRanges(ranges)
+	for _, r := range ranges {
+		r.id = lw.vertex("range", lsifElement{
+			Start: &lsifPos{Line: r.key.line - 1, Character: r.key.col - 1},
+			End:   &r.end,
+		})
+		if r.symbol != nil {
+			lw.edge("next", r.id, resultSets[r.symbol])
+		}
+	}
+	byDocument(ranges, func(doc int, ids []int) {
+		lw.edgeN("contains", doc, ids, lsifElement{})
+	})
+
+	for _, sym := range symbols {
+		rs := resultSets[sym]
+		if sym.def != nil {
+			def := lw.vertex("definitionResult", lsifElement{})
+			lw.edge("textDocument/definition", rs, def)
+			lw.items(def, []*lsifRange{sym.def}, "")
+		}
+		refs := lw.vertex("referenceResult", lsifElement{})
+		lw.edge("textDocument/references", rs, refs)
+		if sym.def != nil {
+			lw.items(refs, []*lsifRange{sym.def}, "definitions")
+		}
+		lw.items(refs, sortRanges(sym.refs), "references")
+		if len(sym.impls) > 0 {
+			impls := lw.vertex("implementationResult", lsifElement{})
+			lw.edge("textDocument/implementation", rs, impls)
+			lw.items(impls, sortRanges(sym.impls), "")
+		}
+	}
+	return lw.err
+}
+
+// fileURI returns the file URI of an absolute path.
+func fileURI(path string) string {
+	return "file://" + filepath.ToSlash(path)
+}
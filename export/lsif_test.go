@@ -0,0 +1,179 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/export"
+	"github.com/stretchr/testify/assert"
+)
+
+const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+type lsifPos struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lsifElement struct {
+	ID         int      `json:"id"`
+	Type       string   `json:"type"`
+	Label      string   `json:"label"`
+	URI        string   `json:"uri"`
+	Start      *lsifPos `json:"start"`
+	Identifier string   `json:"identifier"`
+	OutV       int      `json:"outV"`
+	InV        int      `json:"inV"`
+	InVs       []int    `json:"inVs"`
+	Document   int      `json:"document"`
+}
+
+// lsifDump is a parsed LSIF dump.
+type lsifDump struct {
+	t        *testing.T
+	elements map[int]*lsifElement
+	edges    []*lsifElement
+}
+
+func parseLSIF(t *testing.T, data []byte) *lsifDump {
+	d := &lsifDump{t: t, elements: make(map[int]*lsifElement)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var e lsifElement
+		assert.NoError(t, dec.Decode(&e))
+		assert.Equal(t, len(d.elements)+1, e.ID)
+		d.elements[e.ID] = &e
+		if e.Type == "edge" {
+			d.edges = append(d.edges, &e)
+			// Edges only point to vertices written before
+			// them.
+			for _, v := range append([]int{e.OutV, e.InV, e.Document}, e.InVs...) {
+				if v != 0 {
+					assert.True(t, v < e.ID && d.elements[v].Type == "vertex", "edge %d points to %d", e.ID, v)
+				}
+			}
+		}
+	}
+	return d
+}
+
+// follow returns the vertices reached from v through edges with the
+// provided label.
+func (d *lsifDump) follow(v int, label string) []*lsifElement {
+	l := make([]*lsifElement, 0)
+	for _, e := range d.edges {
+		if e.OutV != v || e.Label != label {
+			continue
+		}
+		if e.InV != 0 {
+			l = append(l, d.elements[e.InV])
+		}
+		for _, in := range e.InVs {
+			l = append(l, d.elements[in])
+		}
+	}
+	return l
+}
+
+// rangeAt returns the range that starts at a position in a document.
+func (d *lsifDump) rangeAt(file string, line, character int) *lsifElement {
+	for _, e := range d.elements {
+		if e.Label == "document" && strings.HasSuffix(e.URI, "/"+file) {
+			for _, r := range d.follow(e.ID, "contains") {
+				if *r.Start == (lsifPos{line, character}) {
+					return r
+				}
+			}
+		}
+	}
+	d.t.Fatalf("No range at %s:%d:%d", file, line, character)
+	return nil
+}
+
+// result returns the ranges of a result of the symbol of a range.
+func (d *lsifDump) result(r *lsifElement, label string) []string {
+	l := make([]string, 0)
+	for _, rs := range d.follow(r.ID, "next") {
+		for _, result := range d.follow(rs.ID, label) {
+			for _, e := range d.edges {
+				if e.OutV != result.ID || e.Label != "item" {
+					continue
+				}
+				doc := filepath.Base(d.elements[e.Document].URI)
+				for _, in := range e.InVs {
+					pos := d.elements[in].Start
+					l = append(l, fmt.Sprintf("%s:%d", doc, pos.Line))
+				}
+			}
+		}
+	}
+	return l
+}
+
+func TestWriteLSIF(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+
+	var buf bytes.Buffer
+	include := func(loadpath string) bool { return strings.HasPrefix(loadpath, pkgpath) }
+	assert.NoError(t, export.WriteLSIF(&buf, pg, "/src", include))
+	d := parseLSIF(t, buf.Bytes())
+
+	assert.Equal(t, "metaData", d.elements[1].Label)
+	documents := make([]string, 0)
+	for _, e := range d.elements {
+		if e.Label == "document" {
+			documents = append(documents, filepath.Base(e.URI))
+		}
+	}
+	assert.ElementsMatch(t, []string{"main.go", "lib.go"}, documents)
+
+	// lib.LibA in main.go refers to the declaration of LibA.
+	use := d.rangeAt("main.go", 12, 9)
+	assert.Equal(t, []string{"lib.go:23"}, d.result(use, "textDocument/definition"))
+	assert.Contains(t, d.result(use, "textDocument/references"), "main.go:12")
+
+	// IfaceLibA is implemented by types from both packages, and
+	// extended by IfaceLibAB.
+	iface := d.rangeAt("lib.go", 6, 5)
+	impls := d.result(iface, "textDocument/implementation")
+	assert.Contains(t, impls, "lib.go:23")
+	assert.Contains(t, impls, "lib.go:17")
+	assert.Contains(t, impls, "main.go:43")
+	assert.Contains(t, impls, "main.go:49")
+	assert.NotContains(t, impls, "main.go:46")
+
+	// Symbols have monikers named after their package.
+	monikers := make([]string, 0)
+	for _, e := range d.elements {
+		if e.Label == "moniker" {
+			monikers = append(monikers, e.Identifier)
+		}
+	}
+	assert.Contains(t, monikers, pkgpath+"/lib:IfaceLibA")
+}
+
+func TestWriteLSIF_localRefs(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetLocalRefs(true)
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+
+	var buf bytes.Buffer
+	include := func(loadpath string) bool { return strings.HasPrefix(loadpath, pkgpath) }
+	assert.NoError(t, export.WriteLSIF(&buf, pg, "/src", include))
+	d := parseLSIF(t, buf.Bytes())
+
+	// acceptAB in main.go refers to its declaration in the same
+	// package.
+	use := d.rangeAt("main.go", 13, 1)
+	assert.Equal(t, []string{"main.go:64"}, d.result(use, "textDocument/definition"))
+	def := d.rangeAt("main.go", 64, 5)
+	assert.Contains(t, d.result(def, "textDocument/references"), "main.go:13")
+}
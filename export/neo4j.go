@@ -0,0 +1,342 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/korfuri/goref"
+)
+
+// The Neo4j exports are property graphs with three labels of nodes:
+//
+//   - Package, with loadpath, name and version properties,
+//   - File, with filename and package properties,
+//   - Symbol, a declaration, with package, ident, kind, file and line
+//     properties. kind, file and line are only set for declarations
+//     of loaded packages.
+//
+// Packages CONTAIN their Files and DECLARE their Symbols. References
+// are relationships from the Symbol that encloses them (or from their
+// Package, for references that aren't made from a declaration, such
+// as imports) to the Symbol or Package they refer to. Their type is
+// the RefType in upper snake case (e.g. DYNAMIC_CALL), and their
+// count property is the number of references they stand for.
+//
+// Each node has an id property, unique among all nodes, by which
+// relationships refer to it.
+
+// neo4jNode is a node of a Neo4j export.
+type neo4jNode struct {
+	id    string
+	label string
+
+	// Properties of the node. Each label has its own properties,
+	// listed in neo4jProperties.
+	props map[string]interface{}
+}
+
+// neo4jLabels are the labels of nodes, in the order in which they're
+// written.
+var neo4jLabels = []string{"Package", "File", "Symbol"}
+
+// neo4jProperties are the properties of each label, in the order in
+// which they're written. Properties that aren't strings have a type
+// for neo4j-admin.
+var neo4jProperties = map[string][]struct{ name, typ string }{
+	"Package": {{"loadpath", ""}, {"name", ""}, {"version", "long"}},
+	"File":    {{"filename", ""}, {"package", ""}},
+	"Symbol":  {{"package", ""}, {"ident", ""}, {"kind", ""}, {"file", ""}, {"line", "int"}},
+}
+
+// neo4jRel is a relationship of a Neo4j export. Its count is only
+// set for references.
+type neo4jRel struct {
+	from, to string
+	typ      string
+	count    int
+}
+
+// neo4jGraph is the set of nodes and relationships of an export.
+type neo4jGraph struct {
+	// Nodes by label, sorted by id.
+	packages, files, symbols []*neo4jNode
+
+	// Relationships, sorted by source, target and type.
+	rels []neo4jRel
+}
+
+func packageID(loadpath string) string {
+	return "package:" + loadpath
+}
+
+func fileID(filename string) string {
+	return "file:" + filename
+}
+
+func symbolID(loadpath, ident string) string {
+	return "symbol:" + loadpath + ":" + ident
+}
+
+// relType returns the relationship type of a RefType, e.g.
+// DYNAMIC_CALL for DynamicCall.
+func relType(rt goref.RefType) string {
+	var b bytes.Buffer
+	for i, r := range rt.String() {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// newNeo4jGraph builds the Neo4j graph of the packages of a
+// PackageGraph for which include returns true (or of all packages if
+// include is nil), and of the packages and Symbols they refer to.
+func newNeo4jGraph(pg *goref.PackageGraph, include func(loadpath string) bool) *neo4jGraph {
+	included := func(loadpath string) bool {
+		return include == nil || include(loadpath)
+	}
+	nodes := make(map[string]*neo4jNode)
+	rels := make(map[neo4jRel]bool)
+
+	// Decls of each loaded package, by qualified identifier.
+	decls := make(map[string]map[string]*goref.Decl)
+	declOf := func(loadpath, ident string) *goref.Decl {
+		if _, in := decls[loadpath]; !in {
+			decls[loadpath] = make(map[string]*goref.Decl)
+			if p, in := pg.Packages[loadpath]; in {
+				for _, d := range p.Decls() {
+					if _, in := decls[loadpath][d.Ident()]; !in {
+						decls[loadpath][d.Ident()] = d
+					}
+				}
+			}
+		}
+		return decls[loadpath][ident]
+	}
+
+	addPackage := func(loadpath string) string {
+		id := packageID(loadpath)
+		if _, in := nodes[id]; !in {
+			n := &neo4jNode{id: id, label: "Package", props: map[string]interface{}{"loadpath": loadpath}}
+			if p, in := pg.Packages[loadpath]; in {
+				n.props["name"] = p.Name
+				n.props["version"] = p.Version
+			}
+			nodes[id] = n
+		}
+		return id
+	}
+	addSymbol := func(loadpath, ident string) string {
+		id := symbolID(loadpath, ident)
+		if _, in := nodes[id]; !in {
+			n := &neo4jNode{id: id, label: "Symbol", props: map[string]interface{}{"package": loadpath, "ident": ident}}
+			if d := declOf(loadpath, ident); d != nil {
+				n.props["kind"] = d.Kind.String()
+				n.props["file"] = d.Position.File
+				n.props["line"] = d.Position.PosL
+			}
+			nodes[id] = n
+			rels[neo4jRel{from: addPackage(loadpath), to: id, typ: "DECLARES"}] = true
+		}
+		return id
+	}
+	addGraphNode := func(n goref.GraphNode) string {
+		if n.Ident == "" {
+			return addPackage(n.Package)
+		}
+		return addSymbol(n.Package, n.Ident)
+	}
+
+	for loadpath, p := range pg.Packages {
+		if !included(loadpath) {
+			continue
+		}
+		pid := addPackage(loadpath)
+		for _, f := range p.Files {
+			id := fileID(f)
+			nodes[id] = &neo4jNode{id: id, label: "File", props: map[string]interface{}{"filename": f, "package": loadpath}}
+			rels[neo4jRel{from: pid, to: id, typ: "CONTAINS"}] = true
+		}
+		for _, d := range p.Decls() {
+			addSymbol(loadpath, d.Ident())
+		}
+	}
+
+	g := pg.Graph(goref.GraphOptions{Granularity: goref.IdentGranularity})
+	for _, e := range g.Edges {
+		if !included(e.From.Package) {
+			continue
+		}
+		rels[neo4jRel{
+			from:  addGraphNode(e.From),
+			to:    addGraphNode(e.To),
+			typ:   relType(e.RefType),
+			count: e.Count,
+		}] = true
+	}
+
+	ng := &neo4jGraph{}
+	for _, n := range nodes {
+		switch n.label {
+		case "Package":
+			ng.packages = append(ng.packages, n)
+		case "File":
+			ng.files = append(ng.files, n)
+		case "Symbol":
+			ng.symbols = append(ng.symbols, n)
+		}
+	}
+	for _, l := range [][]*neo4jNode{ng.packages, ng.files, ng.symbols} {
+		sort.Slice(l, func(i, j int) bool { return l[i].id < l[j].id })
+	}
+	for r := range rels {
+		ng.rels = append(ng.rels, r)
+	}
+	sort.Slice(ng.rels, func(i, j int) bool {
+		a, b := ng.rels[i], ng.rels[j]
+		if a.from != b.from {
+			return a.from < b.from
+		}
+		if a.to != b.to {
+			return a.to < b.to
+		}
+		return a.typ < b.typ
+	})
+	return ng
+}
+
+// labelOf returns the label of the node with the provided id.
+func labelOf(id string) string {
+	switch {
+	case strings.HasPrefix(id, "package:"):
+		return "Package"
+	case strings.HasPrefix(id, "file:"):
+		return "File"
+	}
+	return "Symbol"
+}
+
+// cypherQuote quotes a string as a Cypher string literal.
+func cypherQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// cypherMap returns the Cypher map literal of a node's id and
+// properties.
+func cypherMap(n *neo4jNode) string {
+	fields := []string{"id: " + cypherQuote(n.id)}
+	for _, p := range neo4jProperties[n.label] {
+		switch v := n.props[p.name].(type) {
+		case string:
+			fields = append(fields, p.name+": "+cypherQuote(v))
+		case int, int64:
+			fields = append(fields, fmt.Sprintf("%s: %d", p.name, v))
+		}
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// WriteCypher writes the packages of a PackageGraph for which include
+// returns true (or all packages if include is nil) as Cypher
+// statements, one per line, that create their nodes and
+// relationships in an empty Neo4j database, e.g. with cypher-shell.
+// Packages and Symbols that they refer to are included, so that paths
+// to dependencies can be queried. Relationships are created by
+// matching nodes by id, which is faster with an index on the id of
+// each label.
+func WriteCypher(w io.Writer, pg *goref.PackageGraph, include func(loadpath string) bool) error {
+	g := newNeo4jGraph(pg, include)
+	lines := make([]string, 0, len(g.packages)+len(g.files)+len(g.symbols)+len(g.rels))
+	for _, l := range [][]*neo4jNode{g.packages, g.files, g.symbols} {
+		for _, n := range l {
+			lines = append(lines, fmt.Sprintf("CREATE (:%s %s);", n.label, cypherMap(n)))
+		}
+	}
+	for _, r := range g.rels {
+		props := ""
+		if r.count > 0 {
+			props = fmt.Sprintf(" {count: %d}", r.count)
+		}
+		lines = append(lines, fmt.Sprintf("MATCH (a:%s {id: %s}), (b:%s {id: %s}) CREATE (a)-[:%s%s]->(b);",
+			labelOf(r.from), cypherQuote(r.from), labelOf(r.to), cypherQuote(r.to), r.typ, props))
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// Neo4jCSVFiles are the files written by WriteNeo4jCSV, in the order
+// in which they should be passed to neo4j-admin import.
+var Neo4jCSVFiles = []string{"packages.csv", "files.csv", "symbols.csv", "relationships.csv"}
+
+// writeCSV writes a CSV file with a header to dir.
+func writeCSV(dir, name string, header []string, rows [][]string) error {
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write(header)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteNeo4jCSV writes the same graph as WriteCypher to dir, in the
+// CSV format of neo4j-admin's bulk importer: a file for each label of
+// nodes and a file of relationships (see Neo4jCSVFiles). Each file
+// starts with its header, so the graph can be imported with
+//
+//	neo4j-admin import --nodes=packages.csv --nodes=files.csv \
+//	  --nodes=symbols.csv --relationships=relationships.csv
+func WriteNeo4jCSV(dir string, pg *goref.PackageGraph, include func(loadpath string) bool) error {
+	g := newNeo4jGraph(pg, include)
+	for i, l := range [][]*neo4jNode{g.packages, g.files, g.symbols} {
+		label := neo4jLabels[i]
+		header := []string{"id:ID"}
+		for _, p := range neo4jProperties[label] {
+			if p.typ != "" {
+				header = append(header, p.name+":"+p.typ)
+			} else {
+				header = append(header, p.name)
+			}
+		}
+		header = append(header, ":LABEL")
+		rows := make([][]string, 0, len(l))
+		for _, n := range l {
+			row := []string{n.id}
+			for _, p := range neo4jProperties[label] {
+				if v, in := n.props[p.name]; in {
+					row = append(row, fmt.Sprint(v))
+				} else {
+					row = append(row, "")
+				}
+			}
+			rows = append(rows, append(row, label))
+		}
+		if err := writeCSV(dir, Neo4jCSVFiles[i], header, rows); err != nil {
+			return err
+		}
+	}
+	rows := make([][]string, 0, len(g.rels))
+	for _, r := range g.rels {
+		count := ""
+		if r.count > 0 {
+			count = fmt.Sprint(r.count)
+		}
+		rows = append(rows, []string{r.from, r.to, r.typ, count})
+	}
+	return writeCSV(dir, Neo4jCSVFiles[3], []string{":START_ID", ":END_ID", ":TYPE", "count:int"}, rows)
+}
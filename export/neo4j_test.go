@@ -0,0 +1,61 @@
+package export_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/export"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "Update the golden files in testdata.")
+
+// golden compares data to the golden file testdata/name, or updates
+// that file with -update.
+func golden(t *testing.T, name string, data []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+		return
+	}
+	expected, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(data), "%s differs from its golden file", name)
+}
+
+func loadNeo4jTestGraph(t *testing.T) *goref.PackageGraph {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetLocalRefs(true)
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+	return pg
+}
+
+func TestWriteCypher(t *testing.T) {
+	pg := loadNeo4jTestGraph(t)
+	var buf bytes.Buffer
+	include := func(loadpath string) bool { return strings.HasPrefix(loadpath, pkgpath) }
+	assert.NoError(t, export.WriteCypher(&buf, pg, include))
+	golden(t, "neo4j/interfaces.cypher", buf.Bytes())
+}
+
+func TestWriteNeo4jCSV(t *testing.T) {
+	pg := loadNeo4jTestGraph(t)
+	dir, err := ioutil.TempDir("", "goref-neo4j")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	include := func(loadpath string) bool { return strings.HasPrefix(loadpath, pkgpath) }
+	assert.NoError(t, export.WriteNeo4jCSV(dir, pg, include))
+	for _, name := range export.Neo4jCSVFiles {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		golden(t, filepath.Join("neo4j", name), data)
+	}
+}
@@ -0,0 +1,127 @@
+CREATE (:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces", loadpath: "github.com/korfuri/goref/testprograms/interfaces", name: "main", version: 0});
+CREATE (:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib", loadpath: "github.com/korfuri/goref/testprograms/interfaces/lib", name: "lib", version: 0});
+CREATE (:File {id: "file:github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", filename: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", package: "github.com/korfuri/goref/testprograms/interfaces/lib"});
+CREATE (:File {id: "file:github.com/korfuri/goref/testprograms/interfaces/main.go", filename: "github.com/korfuri/goref/testprograms/interfaces/main.go", package: "github.com/korfuri/goref/testprograms/interfaces"});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibA", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 7});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA.A", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibA.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 8});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibAB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 18});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB.A", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibAB.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 19});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB.B", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibAB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 20});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 12});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB.B", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "IfaceLibB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 13});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibA", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 24});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA.A", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibA.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 37});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibAB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 34});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB.A", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibAB.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 46});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB.B", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibAB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 49});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibC", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibC", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 31});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibC.C", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "LibC.C", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 43});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "libB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 27});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB.B", package: "github.com/korfuri/goref/testprograms/interfaces/lib", ident: "libB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/lib/lib.go", line: 40});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "A", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 44});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A.A", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "A.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 53});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "AB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 50});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB.A", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "AB.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 59});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB.B", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "AB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 62});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "B", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 47});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B.B", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "B.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 56});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:Empty", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "Empty", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 39});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceA", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 18});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA.A", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceA.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 19});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceAB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 28});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB.A", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceAB.A", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 29});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB.B", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceAB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 30});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceB", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 23});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB.B", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "IfaceB.B", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 24});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:acceptAB", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "acceptAB", kind: "func", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 65});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:ifaceC", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "ifaceC", kind: "type", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 34});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:ifaceC.C", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "ifaceC.C", kind: "method", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 35});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:main", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "main", kind: "func", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 12});
+CREATE (:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:use", package: "github.com/korfuri/goref/testprograms/interfaces", ident: "use", kind: "func", file: "github.com/korfuri/goref/testprograms/interfaces/main.go", line: 10});
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:File {id: "file:github.com/korfuri/goref/testprograms/interfaces/main.go"}) CREATE (a)-[:CONTAINS]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}) CREATE (a)-[:IMPORT {count: 1}]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:Empty"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:acceptAB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:ifaceC"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:ifaceC.C"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:main"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:use"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:File {id: "file:github.com/korfuri/goref/testprograms/interfaces/lib/lib.go"}) CREATE (a)-[:CONTAINS]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB.A"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibC"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibC.C"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Package {id: "package:github.com/korfuri/goref/testprograms/interfaces/lib"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB.B"}) CREATE (a)-[:DECLARES]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA.A"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB.A"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB.B"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibAB"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibC.C"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibC"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB.B"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:libB"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A.A"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:A"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB.A"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB.B"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:IMPLEMENTATION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B.B"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:B"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibA"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibAB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceA"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:IfaceLibB"}) CREATE (a)-[:EXTENSION {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:acceptAB"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:IfaceAB"}) CREATE (a)-[:REFERENCE {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:main"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces/lib:LibA"}) CREATE (a)-[:CALL {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:main"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:AB"}) CREATE (a)-[:CALL {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:main"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:acceptAB"}) CREATE (a)-[:CALL {count: 1}]->(b);
+MATCH (a:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:main"}), (b:Symbol {id: "symbol:github.com/korfuri/goref/testprograms/interfaces:use"}) CREATE (a)-[:CALL {count: 1}]->(b);
@@ -0,0 +1,47 @@
+package goref_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterF(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/simple"
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetFilterF(func(loadpath string, version int64) (bool, error) {
+		return loadpath != "fmt", nil
+	})
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+
+	// Filtered packages are in the graph, but aren't loaded.
+	assert.NotEmpty(t, pg.Packages[pkgpath].Files)
+	assert.Contains(t, pg.Packages, "fmt")
+	assert.Empty(t, pg.Packages["fmt"].Files)
+	assert.Empty(t, pg.Packages["fmt"].OutRefs)
+}
+
+func TestFilterF_error(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/simple"
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetFilterF(func(loadpath string, version int64) (bool, error) {
+		if loadpath == "fmt" {
+			return false, errors.New("index unreachable")
+		}
+		return true, nil
+	})
+	err := pg.LoadPackages([]string{pkgpath}, false)
+	assert.EqualError(t, err, "Couldn't filter package fmt: index unreachable")
+
+	// The graph is left untouched.
+	assert.Empty(t, pg.Packages)
+
+	// It can be loaded once the filter works.
+	pg.SetFilterF(goref.FilterPass)
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	assert.NotEmpty(t, pg.Packages["fmt"].Files)
+}
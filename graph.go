@@ -0,0 +1,193 @@
+package goref
+
+import (
+	"sort"
+	"strings"
+)
+
+// GraphGranularity selects what the nodes of a Graph stand for.
+type GraphGranularity int
+
+// These are the possible granularities of a Graph.
+const (
+	// PackageGranularity makes a node of each package. Refs
+	// within a package are left out.
+	PackageGranularity GraphGranularity = iota
+
+	// IdentGranularity makes a node of each declaration, and of
+	// each package for Refs that aren't made from or to a
+	// declaration, such as imports.
+	IdentGranularity
+)
+
+// A GraphNode is a package, or a declaration if Ident is set.
+type GraphNode struct {
+	Package string `json:"package"`
+
+	// Ident is the qualified identifier of a declaration (see
+	// Decl.Ident), or empty for a package.
+	Ident string `json:"ident,omitempty"`
+}
+
+func (n GraphNode) String() string {
+	if n.Ident == "" {
+		return n.Package
+	}
+	return n.Package + "." + n.Ident
+}
+
+func (n GraphNode) less(o GraphNode) bool {
+	if n.Package != o.Package {
+		return n.Package < o.Package
+	}
+	return n.Ident < o.Ident
+}
+
+// A GraphEdge stands for all the Refs of one type between two nodes.
+type GraphEdge struct {
+	From    GraphNode
+	To      GraphNode
+	RefType RefType
+
+	// Count is the number of Refs this edge stands for.
+	Count int
+}
+
+// A Graph is a view of a PackageGraph's Refs as a directed graph,
+// suitable for visualization.
+type Graph struct {
+	// Nodes, sorted by package and identifier.
+	Nodes []GraphNode
+
+	// Edges, sorted by source, target and type.
+	Edges []*GraphEdge
+}
+
+// GraphOptions selects the Refs of a Graph and what its nodes stand
+// for.
+type GraphOptions struct {
+	Granularity GraphGranularity
+
+	// RefTypes are the types of Refs to include. All types are
+	// included if empty.
+	RefTypes []RefType
+
+	// Prefixes are load path prefixes. Only Refs between packages
+	// that start with one of them are included. All packages are
+	// included if empty.
+	Prefixes []string
+}
+
+// includes returns whether a Ref is part of the Graph.
+func (o GraphOptions) includes(r *Ref) bool {
+	if len(o.RefTypes) > 0 {
+		found := false
+		for _, rt := range o.RefTypes {
+			found = found || rt == r.RefType
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(o.Prefixes) == 0 {
+		return true
+	}
+	hasPrefix := func(loadpath string) bool {
+		for _, p := range o.Prefixes {
+			if strings.HasPrefix(loadpath, p) {
+				return true
+			}
+		}
+		return false
+	}
+	return hasPrefix(r.FromPackage.Path) && hasPrefix(r.ToPackage.Path)
+}
+
+// Graph returns the graph of the Refs of this PackageGraph, as
+// selected by opts. At IdentGranularity, a Ref is made from the
+// innermost declaration that encloses it, and LocalRefs are included
+// if they were recorded (see SetLocalRefs).
+func (pg *PackageGraph) Graph(opts GraphOptions) *Graph {
+	// Decls are computed once per package, and indexed by the
+	// position of their identifier for targets of Refs.
+	decls := make(map[*Package][]*Decl)
+	declAt := make(map[*Package]map[Position]*Decl)
+	declsOf := func(p *Package) []*Decl {
+		if _, in := decls[p]; !in {
+			decls[p] = p.Decls()
+			declAt[p] = make(map[Position]*Decl)
+			for _, d := range decls[p] {
+				declAt[p][d.Position] = d
+			}
+		}
+		return decls[p]
+	}
+
+	type edgeKey struct {
+		from, to GraphNode
+		rt       RefType
+	}
+	nodes := make(map[GraphNode]bool)
+	edges := make(map[edgeKey]*GraphEdge)
+	add := func(r *Ref) {
+		if !opts.includes(r) {
+			return
+		}
+		from := GraphNode{Package: r.FromPackage.Path}
+		to := GraphNode{Package: r.ToPackage.Path}
+		if opts.Granularity == IdentGranularity {
+			if d := enclosingDecl(declsOf(r.FromPackage), r.FromPosition); d != nil {
+				from.Ident = d.Ident()
+			}
+			if r.RefType != Import {
+				declsOf(r.ToPackage)
+				if d, in := declAt[r.ToPackage][r.ToPosition]; in {
+					to.Ident = d.Ident()
+				} else {
+					to.Ident = r.ToIdent
+				}
+			}
+		} else if from == to {
+			return
+		}
+		nodes[from], nodes[to] = true, true
+		k := edgeKey{from, to, r.RefType}
+		if _, in := edges[k]; !in {
+			edges[k] = &GraphEdge{From: from, To: to, RefType: r.RefType}
+		}
+		edges[k].Count++
+	}
+	for _, p := range pg.Packages {
+		for _, r := range p.OutRefs {
+			add(r)
+		}
+		if opts.Granularity == IdentGranularity {
+			for _, r := range p.LocalRefs {
+				add(r)
+			}
+		}
+	}
+
+	g := &Graph{
+		Nodes: make([]GraphNode, 0, len(nodes)),
+		Edges: make([]*GraphEdge, 0, len(edges)),
+	}
+	for n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].less(g.Nodes[j]) })
+	for _, e := range edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From.less(b.From)
+		}
+		if a.To != b.To {
+			return a.To.less(b.To)
+		}
+		return a.RefType < b.RefType
+	})
+	return g
+}
@@ -0,0 +1,80 @@
+package goref_test
+
+import (
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+// edgeOf returns the edge of a Graph between two nodes with the
+// provided type, or nil.
+func edgeOf(g *goref.Graph, from, to goref.GraphNode, rt goref.RefType) *goref.GraphEdge {
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to && e.RefType == rt {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestGraph_packages(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+
+	pkg := goref.GraphNode{Package: pkgpath}
+	lib := goref.GraphNode{Package: libpath}
+	g := pg.Graph(goref.GraphOptions{Prefixes: []string{pkgpath}})
+	assert.Equal(t, []goref.GraphNode{pkg, lib}, g.Nodes)
+	imp := edgeOf(g, pkg, lib, goref.Import)
+	if assert.NotNil(t, imp) {
+		assert.Equal(t, 1, imp.Count)
+	}
+	assert.NotNil(t, edgeOf(g, lib, pkg, goref.Implementation))
+	// Refs within a package aren't edges at package granularity.
+	assert.Nil(t, edgeOf(g, pkg, pkg, goref.Implementation))
+
+	g = pg.Graph(goref.GraphOptions{Prefixes: []string{pkgpath}, RefTypes: []goref.RefType{goref.Import}})
+	assert.Len(t, g.Edges, 1)
+
+	// Both ends of a Ref must match a prefix.
+	assert.Empty(t, pg.Graph(goref.GraphOptions{Prefixes: []string{libpath}}).Nodes)
+	assert.Len(t, pg.Graph(goref.GraphOptions{}).Nodes, 2)
+}
+
+func TestGraph_idents(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetLocalRefs(true)
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+
+	g := pg.Graph(goref.GraphOptions{Granularity: goref.IdentGranularity, Prefixes: []string{pkgpath}})
+	main := goref.GraphNode{Package: pkgpath, Ident: "main"}
+	// main calls lib.LibA and the local acceptAB.
+	assert.NotNil(t, edgeOf(g, main, goref.GraphNode{Package: libpath, Ident: "LibA"}, goref.Call))
+	assert.NotNil(t, edgeOf(g, main, goref.GraphNode{Package: pkgpath, Ident: "acceptAB"}, goref.Call))
+	assert.NotNil(t, edgeOf(g, goref.GraphNode{Package: pkgpath, Ident: "AB"}, goref.GraphNode{Package: libpath, Ident: "IfaceLibA"}, goref.Implementation))
+	// Imports are made from and to packages.
+	assert.NotNil(t, edgeOf(g, goref.GraphNode{Package: pkgpath}, goref.GraphNode{Package: libpath}, goref.Import))
+}
+
+func TestParseRefType(t *testing.T) {
+	for rt := goref.RefType(goref.Instantiation); rt <= goref.DynamicCall; rt++ {
+		parsed, err := goref.ParseRefType(rt.String())
+		assert.NoError(t, err)
+		assert.Equal(t, rt, parsed)
+	}
+	_, err := goref.ParseRefType("Use")
+	assert.Error(t, err)
+}
@@ -0,0 +1,304 @@
+package goref
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A LineRange is a range of changed lines in a file.
+type LineRange struct {
+	// File is the path of the changed file. It is matched against
+	// the end of the graph's file paths, so it may be relative to
+	// the root of a repository within a corpus.
+	File string
+
+	// Start and End are the first and last changed lines,
+	// inclusive.
+	Start int
+	End   int
+}
+
+func (lr LineRange) String() string {
+	return fmt.Sprintf("%s:%d-%d", lr.File, lr.Start, lr.End)
+}
+
+// matchesFile returns whether the provided corpus-relative file path
+// designates this LineRange's File.
+func (lr LineRange) matchesFile(f string) bool {
+	return f == lr.File || strings.HasSuffix(f, "/"+lr.File)
+}
+
+// matchesDir returns whether the provided corpus-relative file path
+// is in the same directory as this LineRange's File. This is used to
+// find the package of files that were added or deleted.
+func (lr LineRange) matchesDir(f string) bool {
+	dir := path.Dir(lr.File)
+	if dir == "." {
+		return lr.matchesFile(f)
+	}
+	return path.Dir(f) == dir || strings.HasSuffix(path.Dir(f), "/"+dir)
+}
+
+// ParseLineRange parses a LineRange of the form "file.go:start-end",
+// "file.go:line" or "file.go". The latter designates the whole file.
+func ParseLineRange(s string) (LineRange, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return LineRange{File: s, Start: 1, End: math.MaxInt32}, nil
+	}
+	lr := LineRange{File: s[:i]}
+	bounds := strings.SplitN(s[i+1:], "-", 2)
+	var err error
+	if lr.Start, err = strconv.Atoi(bounds[0]); err != nil {
+		return LineRange{}, fmt.Errorf("Invalid line range %s: %s", s, err)
+	}
+	lr.End = lr.Start
+	if len(bounds) == 2 {
+		if lr.End, err = strconv.Atoi(bounds[1]); err != nil {
+			return LineRange{}, fmt.Errorf("Invalid line range %s: %s", s, err)
+		}
+	}
+	if lr.End < lr.Start {
+		return LineRange{}, fmt.Errorf("Invalid line range %s: end is before start", s)
+	}
+	return lr, nil
+}
+
+// hunkHeader matches the header of a hunk of a unified diff, with the
+// start and the number of lines of the hunk in the old and new
+// files. Numbers of lines default to 1.
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// diffFilename extracts the filename from a "--- " or "+++ " line of
+// a unified diff, removing timestamps and git's a/ and b/ prefixes.
+func diffFilename(line string) string {
+	name := line[4:]
+	if i := strings.IndexByte(name, '\t'); i >= 0 {
+		name = name[:i]
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		name = name[2:]
+	}
+	return name
+}
+
+// ParseUnifiedDiff returns the ranges of lines changed by a unified
+// diff, in the newer version of each file. Context lines are not
+// part of the ranges. Lines removed by the diff are attributed to the
+// lines around them. Deleted files are designated as a whole.
+func ParseUnifiedDiff(r io.Reader) ([]LineRange, error) {
+	ranges := make([]LineRange, 0)
+	var oldFile, file string
+	line := 0
+	changed := func(l int) {
+		if l < 1 {
+			l = 1
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].File == file && ranges[n-1].End >= l-1 {
+			if l > ranges[n-1].End {
+				ranges[n-1].End = l
+			}
+			return
+		}
+		ranges = append(ranges, LineRange{File: file, Start: l, End: l})
+	}
+
+	// hunkLines returns a number of lines of a hunk header, or 1
+	// if it's omitted.
+	hunkLines := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	// oldLeft and newLeft are the numbers of lines of the current
+	// hunk that are left to read in the old and new files. Lines
+	// within a hunk are never headers, even if they start with
+	// "--- " or "+++ ".
+	oldLeft, newLeft := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if file != "" {
+					changed(line)
+				}
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				// Hunks of deleted files are skipped.
+				if file != "" {
+					changed(line - 1)
+					changed(line)
+				}
+				oldLeft--
+			case strings.HasPrefix(text, "\\"):
+				// "\ No newline at end of file" follows the last line of a file.
+			default:
+				line++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "--- "):
+			oldFile = diffFilename(text)
+			file = ""
+		case strings.HasPrefix(text, "+++ "):
+			file = diffFilename(text)
+			if file == "/dev/null" {
+				file = ""
+				ranges = append(ranges, LineRange{File: oldFile, Start: 1, End: math.MaxInt32})
+			}
+		case strings.HasPrefix(text, "@@"):
+			m := hunkHeader.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("Invalid hunk header: %s", text)
+			}
+			line, _ = strconv.Atoi(m[3])
+			oldLeft, newLeft = hunkLines(m[2]), hunkLines(m[4])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// An Impact is the set of declarations and packages that a change
+// may affect.
+type Impact struct {
+	// Changed are the declarations whose extent overlaps a
+	// changed line range.
+	Changed []*Decl
+
+	// Affected are the declarations that transitively reference
+	// a changed declaration.
+	Affected []*Decl
+
+	// Packages are the sorted load paths of packages that contain
+	// a changed file, or a changed or affected declaration.
+	Packages []string
+
+	// TestPackages are the sorted load paths of the packages
+	// whose tests should run, i.e. the Packages that have tests
+	// or XTests. XTest packages are designated by the load path
+	// of the package they test.
+	TestPackages []string
+}
+
+// Impact maps changed line ranges to the declarations they touch,
+// and walks Refs to these declarations transitively to find all
+// declarations, packages and test packages that the change may
+// affect.
+//
+// If the graph wasn't configured with SetLocalRefs(true),
+// references within a package are unknown, so any declaration of a
+// package with an affected declaration is considered affected.
+func (pg *PackageGraph) Impact(changes []LineRange) *Impact {
+	impact := &Impact{
+		Changed:      make([]*Decl, 0),
+		Affected:     make([]*Decl, 0),
+		Packages:     make([]string, 0),
+		TestPackages: make([]string, 0),
+	}
+
+	// Decls are computed once per package so that they can be
+	// compared by pointer.
+	decls := make(map[*Package][]*Decl)
+	declsOf := func(p *Package) []*Decl {
+		if _, in := decls[p]; !in {
+			decls[p] = p.Decls()
+		}
+		return decls[p]
+	}
+
+	pkgs := make(map[string]bool)
+	seen := make(map[*Decl]bool)
+	queue := make([]*Decl, 0)
+	for _, p := range pg.Packages {
+		for _, lr := range changes {
+			for _, f := range p.Files {
+				if lr.matchesDir(f) {
+					pkgs[p.Path] = true
+				}
+			}
+			for _, d := range declsOf(p) {
+				if !seen[d] && lr.matchesFile(d.Extent.File) && d.Extent.OverlapsLines(d.Extent.File, lr.Start, lr.End) {
+					seen[d] = true
+					impact.Changed = append(impact.Changed, d)
+					queue = append(queue, d)
+				}
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		p := d.Package
+		pkgs[p.Path] = true
+
+		refs := p.InRefsTo(d)
+		if pg.localRefs {
+			refs = append(refs, p.LocalRefsTo(d)...)
+		} else {
+			for _, e := range declsOf(p) {
+				if !seen[e] {
+					seen[e] = true
+					impact.Affected = append(impact.Affected, e)
+					queue = append(queue, e)
+				}
+			}
+		}
+		for _, r := range refs {
+			pkgs[r.FromPackage.Path] = true
+			e := enclosingDecl(declsOf(r.FromPackage), r.FromPosition)
+			if e == nil || seen[e] {
+				continue
+			}
+			seen[e] = true
+			impact.Affected = append(impact.Affected, e)
+			queue = append(queue, e)
+		}
+	}
+
+	tests := make(map[string]bool)
+	for loadpath := range pkgs {
+		impact.Packages = append(impact.Packages, loadpath)
+		if tested := strings.TrimSuffix(loadpath, "_test"); tested != loadpath {
+			if _, in := pg.Packages[tested]; in {
+				tests[tested] = true
+				continue
+			}
+		}
+		if _, in := pg.Packages[loadpath+"_test"]; in {
+			tests[loadpath] = true
+		}
+		for _, f := range pg.Packages[loadpath].Files {
+			if strings.HasSuffix(f, "_test.go") {
+				tests[loadpath] = true
+			}
+		}
+	}
+	for loadpath := range tests {
+		impact.TestPackages = append(impact.TestPackages, loadpath)
+	}
+	sortDecls(impact.Changed)
+	sortDecls(impact.Affected)
+	sort.Strings(impact.Packages)
+	sort.Strings(impact.TestPackages)
+	return impact
+}
@@ -0,0 +1,149 @@
+package goref_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+const impactDiff = `diff --git a/testprograms/impact/a/a.go b/testprograms/impact/a/a.go
+index 1111111..2222222 100644
+--- a/testprograms/impact/a/a.go
++++ b/testprograms/impact/a/a.go
+@@ -3,7 +3,7 @@ package a
+ 
+ // A is used by b.B.
+ func A() int {
+-	return 1
++	return 10
+ }
+ 
+ // Other is used by b.Indep and d.D.
+diff --git a/testprograms/impact/d/old.go b/testprograms/impact/d/old.go
+deleted file mode 100644
+--- a/testprograms/impact/d/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package d
+-
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	ranges, err := goref.ParseUnifiedDiff(strings.NewReader(impactDiff))
+	assert.NoError(t, err)
+	assert.Equal(t, []goref.LineRange{
+		{File: "testprograms/impact/a/a.go", Start: 5, End: 6},
+		{File: "testprograms/impact/d/old.go", Start: 1, End: math.MaxInt32},
+	}, ranges)
+}
+
+func TestParseUnifiedDiff_hunkLines(t *testing.T) {
+	// Lines of a hunk may start with "--- " or "+++ " without
+	// being file headers.
+	const diff = `--- a/query.sql
++++ b/query.sql
+@@ -1,3 +1,3 @@
+ SELECT 1;
+--- x
++++ y
+ SELECT 2;
+@@ -10 +10 @@
+-a
++b
+\ No newline at end of file
+--- a/other.sql
++++ b/other.sql
+@@ -2,0 +3,2 @@
++-- comment
++c
+`
+	ranges, err := goref.ParseUnifiedDiff(strings.NewReader(diff))
+	assert.NoError(t, err)
+	assert.Equal(t, []goref.LineRange{
+		{File: "query.sql", Start: 1, End: 2},
+		{File: "query.sql", Start: 9, End: 10},
+		{File: "other.sql", Start: 3, End: 4},
+	}, ranges)
+}
+
+func TestParseLineRange(t *testing.T) {
+	lr, err := goref.ParseLineRange("a/a.go:10-20")
+	assert.NoError(t, err)
+	assert.Equal(t, goref.LineRange{File: "a/a.go", Start: 10, End: 20}, lr)
+	lr, err = goref.ParseLineRange("a/a.go:10")
+	assert.NoError(t, err)
+	assert.Equal(t, goref.LineRange{File: "a/a.go", Start: 10, End: 10}, lr)
+	lr, err = goref.ParseLineRange("a/a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, goref.LineRange{File: "a/a.go", Start: 1, End: math.MaxInt32}, lr)
+	_, err = goref.ParseLineRange("a/a.go:20-10")
+	assert.Error(t, err)
+	_, err = goref.ParseLineRange("a/a.go:x")
+	assert.Error(t, err)
+}
+
+// declIdents returns the qualified identifiers of decls.
+func declIdents(decls []*goref.Decl) []string {
+	idents := make([]string, 0, len(decls))
+	for _, d := range decls {
+		idents = append(idents, d.Package.Name+"."+d.Ident())
+	}
+	return idents
+}
+
+func TestImpact(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/impact"
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetLocalRefs(true)
+	pg.LoadPackages([]string{pkgpath, pkgpath + "/b"}, true)
+
+	impact := pg.Impact([]goref.LineRange{
+		{File: "testprograms/impact/a/a.go", Start: 6, End: 6},
+	})
+	assert.Equal(t, []string{"a.A"}, declIdents(impact.Changed))
+	// b.Local is only affected through a local reference to b.B.
+	assert.Equal(t, []string{"main.main", "b.B", "b.Local", "b.TestB", "c.C"}, declIdents(impact.Affected))
+	assert.Equal(t, []string{
+		pkgpath,
+		pkgpath + "/a",
+		pkgpath + "/b",
+		pkgpath + "/c",
+	}, impact.Packages)
+	assert.Equal(t, []string{pkgpath + "/b"}, impact.TestPackages)
+}
+
+func TestImpact_noLocalRefs(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/impact"
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	for _, p := range pg.Packages {
+		assert.Empty(t, p.LocalRefs)
+	}
+
+	impact := pg.Impact([]goref.LineRange{
+		{File: "testprograms/impact/a/a.go", Start: 6, End: 6},
+	})
+	// Without local refs, all of a is affected, and hence d too.
+	assert.Contains(t, declIdents(impact.Affected), "a.Other")
+	assert.Contains(t, declIdents(impact.Affected), "d.D")
+	assert.Contains(t, impact.Packages, pkgpath+"/d")
+}
+
+func TestImpact_deletedFile(t *testing.T) {
+	const pkgpath = "github.com/korfuri/goref/testprograms/impact"
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.SetLocalRefs(true)
+	pg.LoadPackages([]string{pkgpath}, false)
+
+	impact := pg.Impact([]goref.LineRange{
+		{File: "impact/d/old.go", Start: 1, End: math.MaxInt32},
+	})
+	assert.Empty(t, impact.Changed)
+	assert.Equal(t, []string{pkgpath + "/d"}, impact.Packages)
+}
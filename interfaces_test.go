@@ -77,3 +77,66 @@ func TestInterfaceImplMatrix(t *testing.T) {
 	testutils.AssertPresenceOfRef(t, pkg, "Empty", lib, "IfaceLibB", goref.Implementation, false)
 	testutils.AssertPresenceOfRef(t, pkg, "Empty", lib, "IfaceLibAB", goref.Implementation, false)
 }
+
+// TestInterfaceImplMatrix_positions checks that Implementation and
+// Extension Refs point to the declarations of the interface they
+// refer to and of the type or interface they're made from.
+func TestInterfaceImplMatrix_positions(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	pg.ComputeInterfaceImplementationMatrix()
+
+	decls := make(map[string]goref.Position)
+	for _, p := range pg.Packages {
+		for _, d := range p.Decls() {
+			decls[p.Path+"."+d.Ident()] = d.Position
+		}
+	}
+	extensions := 0
+	for _, p := range pg.Packages {
+		for _, r := range p.InRefs {
+			if r.RefType != goref.Implementation && r.RefType != goref.Extension {
+				continue
+			}
+			if r.RefType == goref.Extension {
+				extensions++
+			}
+			assert.Equal(t, decls[r.ToPackage.Path+"."+r.ToIdent], r.ToPosition, "To of %s", r)
+			assert.Equal(t, decls[r.FromPackage.Path+"."+r.FromIdent], r.FromPosition, "From of %s", r)
+		}
+	}
+	assert.NotZero(t, extensions)
+}
+
+func TestInterfaceImplMatrix_extensionTarget(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	pg.ComputeInterfaceImplementationMatrix()
+
+	// Extension Refs point to the extended interface, and come
+	// from the extending one.
+	lib := pg.Packages[pkgpath+"/lib"]
+	n := 0
+	for _, r := range lib.InRefs {
+		if r.RefType != goref.Extension || r.ToIdent != "IfaceLibA" {
+			continue
+		}
+		n++
+		assert.Equal(t, pkgpath+"/lib/lib.go", r.ToPosition.File)
+		assert.Equal(t, 7, r.ToPosition.PosL)
+		assert.Equal(t, 6, r.ToPosition.PosC)
+		if r.FromIdent == "IfaceAB" {
+			assert.Equal(t, pkgpath+"/main.go", r.FromPosition.File)
+			assert.Equal(t, 28, r.FromPosition.PosL)
+		}
+	}
+	assert.NotZero(t, n)
+}
@@ -22,11 +22,16 @@ type Package struct {
 	// the Ref is to an identifier within this package.  Most
 	// RefTypes are not indexed if the ToPackage and the
 	// FromPackage are the same, but some do such as
-	// Implementation. This means that a ref can exist in both
-	// OutRefs and InRefs of the same package.
+	// Implementation and DynamicCall. This means that a ref can
+	// exist in both OutRefs and InRefs of the same package.
 	OutRefs []*Ref `json:"-"`
 	InRefs  []*Ref `json:"-"`
 
+	// LocalRefs are references from this package to identifiers
+	// declared in this package. They are only recorded if the
+	// PackageGraph was configured with SetLocalRefs(true).
+	LocalRefs []*Ref `json:"-"`
+
 	// Interfaces is the list of interface types in this package.
 	//
 	// This is used to compute the interface-implementation matrix.
@@ -45,6 +50,12 @@ type Package struct {
 	// methods.
 	Impls []*types.Named `json:"-"`
 
+	// Types is the type-checked package as loaded by go/loader.
+	//
+	// This is used to enumerate the package's declarations. It
+	// is nil for special packages such as "unsafe".
+	Types *types.Package `json:"-"`
+
 	// Fset is a reference to the token.FileSet that loaded this
 	// package.
 	Fset *token.FileSet `json:"-"`
@@ -57,6 +68,13 @@ type Package struct {
 
 	// Corpus is the corpus that contains this package
 	Corpus `json:"-"`
+
+	// extents maps the position of each declared identifier to
+	// the extent of its declaration.
+	extents map[token.Pos]Position
+
+	// decls are the declarations set with SetDecls.
+	decls []*Decl
 }
 
 // String implements the Stringer interface
@@ -90,11 +108,14 @@ func newPackage(pi *loader.PackageInfo, fset *token.FileSet, version int64, corp
 		Name:       pi.Pkg.Name(),
 		OutRefs:    make([]*Ref, 0),
 		InRefs:     make([]*Ref, 0),
+		LocalRefs:  make([]*Ref, 0),
 		Interfaces: make([]*types.Named, 0),
 		Impls:      make([]*types.Named, 0),
+		Types:      pi.Pkg,
 		Fset:       fset,
 		Version:    version,
 		Path:       pi.Pkg.Path(),
 		Corpus:     corpus,
+		extents:    make(map[token.Pos]Position),
 	}
 }
@@ -1,7 +1,9 @@
 package goref
 
 import (
+	"fmt"
 	"go/ast"
+	"go/build"
 	"go/types"
 	"path"
 	"strings"
@@ -36,8 +38,21 @@ type PackageGraph struct {
 	versionF func(loader.Program, loader.PackageInfo) (int64, error)
 
 	// filterF is a function that determines whether a package
-	// version should be loaded into the graph.
-	filterF func(loadpath string, version int64) bool
+	// version should be loaded into the graph. If it returns an
+	// error, LoadPackages fails.
+	filterF func(loadpath string, version int64) (bool, error)
+
+	// buildContext is the go/build context used to locate
+	// packages. If nil, build.Default is used.
+	buildContext *build.Context
+
+	// localRefs is whether Refs between identifiers of the same
+	// package are recorded in Package.LocalRefs.
+	localRefs bool
+
+	// callGraph is the algorithm used to resolve dynamic calls
+	// into DynamicCall Refs, or NoCallGraph.
+	callGraph CallGraphAlgorithm
 }
 
 // CleanImportSpec takes an ast.ImportSpec and cleans the Path
@@ -88,6 +103,19 @@ func CandidatePaths(loadpath, parent string) []string {
 	return paths
 }
 
+// isPackageLevel returns whether obj is a package-level func, type,
+// var or const, or a method or field. Other objects (local
+// variables, labels, imported package names) are not declarations
+// that other identifiers can depend on.
+func isPackageLevel(obj types.Object) bool {
+	switch obj.(type) {
+	case *types.Func, *types.Var, *types.Const, *types.TypeName:
+		// Methods and fields have no parent scope.
+		return obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope()
+	}
+	return false
+}
+
 // specialPackage returns hardcoded packages for packages wihtout a Go
 // implementation. Currently this is only "unsafe".
 func specialPackage(loadpath string) *Package {
@@ -119,11 +147,43 @@ func specialPackage(loadpath string) *Package {
 	return nil
 }
 
+// filterResult is the version of a package, and whether it passed
+// the graph's filterF.
+type filterResult struct {
+	version int64
+	pass    bool
+}
+
+// filterPackages computes the version of each package of a loaded
+// program that isn't in the graph yet, and applies filterF to it. It
+// returns an error if filterF fails for any package. Packages whose
+// version can't be determined are left out.
+func (pg *PackageGraph) filterPackages(prog *loader.Program) (map[string]filterResult, error) {
+	filtered := make(map[string]filterResult)
+	for k, v := range prog.AllPackages {
+		loadpath := k.Path()
+		if _, in := pg.Packages[loadpath]; in || specialPackage(loadpath) != nil {
+			continue
+		}
+		version, err := pg.versionF(*prog, *v)
+		if err != nil {
+			continue
+		}
+		pass, err := pg.filterF(loadpath, version)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't filter package %s: %s", loadpath, err)
+		}
+		filtered[loadpath] = filterResult{version: version, pass: pass}
+	}
+	return filtered, nil
+}
+
 // loadPackage recursively loads a Go package into the Package
 // Graph. If the package was already loaded, it returns early. It
-// always returns the Package object for the loaded package.
-func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *loader.PackageInfo) *Package {
-	var pkg *Package
+// always returns the Package object for the loaded package, or nil if
+// its version couldn't be determined. The version of packages and
+// whether they pass filterF are provided by filterPackages.
+func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *loader.PackageInfo, filtered map[string]filterResult) *Package {
 	// First find whether we already know about this package,
 	// either because it's already in the graph (then return it)
 	// or because it has a hardcoded definition.
@@ -131,21 +191,14 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 		return existingPkg
 	}
 	if specialPkg := specialPackage(loadpath); specialPkg != nil {
-		pkg = specialPkg
+		return specialPkg
 	}
 
-	// If we didn't find a hardcoded package, rely on versionF to
-	// tell us what version this package should have. Otherwise
-	// use the hardcoded Package's Version.
-	var version int64
-	if pkg == nil {
-		var err error
-		version, err = pg.versionF(*prog, *pi)
-		if err != nil {
-			return nil
-		}
-	} else {
-		version = pkg.Version
+	// If versionF failed to tell us what version this package
+	// should have, it's not loaded.
+	res, in := filtered[loadpath]
+	if !in {
+		return nil
 	}
 
 	// Find what corpus this package was loaded from.
@@ -160,18 +213,13 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 		}
 	}
 
-	// If pkg was a hardcoded package, return it now. Otherwise,
-	// create it.
-	if pkg != nil {
-		return pkg
-	}
-	pkg = newPackage(pi, prog.Fset, version, corpus)
+	pkg := newPackage(pi, prog.Fset, res.version, corpus)
 	pg.Packages[loadpath] = pkg
 
-	// Apply filterF to stop loading any package that doesn't pass
-	// the filter. Note that if a package was already present in
-	// the graph, filterF is not called.
-	if !pg.filterF(loadpath, version) {
+	// Stop loading any package that doesn't pass the filter.
+	// Note that if a package was already present in the graph,
+	// filterF is not called.
+	if !res.pass {
 		return pkg
 	}
 
@@ -180,6 +228,9 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 		// Add that file to the package's file list
 		pkg.Files = append(pkg.Files, corpus.Rel(prog.Fset.File(f.Package).Name()))
 
+		// Record the extent of the file's declarations
+		addExtents(pkg.extents, corpus, prog.Fset, f)
+
 		// Iterate over all imports in that file
 		for _, imported := range f.Imports {
 			// Find the import's load-path and load that
@@ -198,7 +249,7 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 				log.Warnf("Tried to load package `%s` imported by package `%s` but it wasn't found anywhere in the load path. The candidate load paths were: %s\n", ipath, loadpath, candidatePaths)
 				continue
 			}
-			importedPkg := pg.loadPackage(prog, ipath, i)
+			importedPkg := pg.loadPackage(prog, ipath, i, filtered)
 			if importedPkg == nil {
 				// This happens if versionF fails to
 				// determine the pacakge's version.
@@ -253,7 +304,7 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 						RefType:      refTypeForIdent(prog, id),
 						ToIdent:      obj.Name(),
 						ToPackage:    foreignPkg,
-						ToPosition:   NewPosition(corpus, prog.Fset, obj.Pos(), NoPos),
+						ToPosition:   NewPosition(foreignPkg.Corpus, prog.Fset, obj.Pos(), NoPos),
 						FromIdent:    id.Name,
 						FromPackage:  pkg,
 						FromPosition: NewPosition(corpus, prog.Fset, id.Pos(), id.End()),
@@ -262,6 +313,16 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 					foreignPkg.InRefs = append(foreignPkg.InRefs, ref)
 					pkg.OutRefs = append(pkg.OutRefs, ref)
 				}
+			} else if pg.localRefs && isPackageLevel(obj) {
+				pkg.LocalRefs = append(pkg.LocalRefs, &Ref{
+					RefType:      refTypeForIdent(prog, id),
+					ToIdent:      obj.Name(),
+					ToPackage:    pkg,
+					ToPosition:   NewPosition(corpus, prog.Fset, obj.Pos(), NoPos),
+					FromIdent:    id.Name,
+					FromPackage:  pkg,
+					FromPosition: NewPosition(corpus, prog.Fset, id.Pos(), id.End()),
+				})
 			}
 		}
 	}
@@ -291,8 +352,13 @@ func (pg *PackageGraph) loadPackage(prog *loader.Program, loadpath string, pi *l
 // dependencies, as well as XTests (as defined by go/loader) if
 // includeTests is true.  It may be called multiple times to load
 // multiple package sets in the PackageGraph.
+//
+// If filterF returns an error, LoadPackages returns it without
+// modifying the graph.
 func (pg *PackageGraph) LoadPackages(packages []string, includeTests bool) error {
-	conf := loader.Config{}
+	conf := loader.Config{
+		Build: pg.buildContext,
+	}
 	if _, err := conf.FromArgs(packages, includeTests); err != nil {
 		return err
 	}
@@ -302,8 +368,30 @@ func (pg *PackageGraph) LoadPackages(packages []string, includeTests bool) error
 		return err
 	}
 
+	filtered, err := pg.filterPackages(prog)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	for loadpath := range pg.Packages {
+		known[loadpath] = true
+	}
 	for k, v := range prog.AllPackages {
-		pg.loadPackage(prog, k.Path(), v)
+		pg.loadPackage(prog, k.Path(), v, filtered)
+	}
+
+	if pg.callGraph != NoCallGraph {
+		// Only packages that were loaded by this call, and
+		// that passed filterF, get their dynamic calls
+		// resolved.
+		loaded := make(map[*Package]bool)
+		for loadpath, p := range pg.Packages {
+			if !known[loadpath] && len(p.Files) > 0 {
+				loaded[p] = true
+			}
+		}
+		pg.addDynamicCalls(prog, loaded)
 	}
 
 	return nil
@@ -343,7 +431,7 @@ func (pg *PackageGraph) ComputeInterfaceImplementationMatrix() {
 							RefType:    Extension,
 							ToIdent:    iface.Obj().Name(),
 							ToPackage:  pa,
-							ToPosition: NewPosition(pa.Corpus, pa.Fset, ifaceb.Obj().Pos(), NoPos),
+							ToPosition: NewPosition(pa.Corpus, pa.Fset, iface.Obj().Pos(), NoPos),
 
 							FromIdent:    ifaceb.Obj().Name(),
 							FromPackage:  pb,
@@ -370,6 +458,33 @@ func NewPackageGraph(versionF func(loader.Program, loader.PackageInfo) (int64, e
 }
 
 // SetFilterF sets the filterF for this PackageGraph
-func (pg *PackageGraph) SetFilterF(f func(string, int64) bool) {
+func (pg *PackageGraph) SetFilterF(f func(string, int64) (bool, error)) {
 	pg.filterF = f
 }
+
+// SetBuildContext sets the go/build context used to locate packages
+// for this PackageGraph, e.g. to load packages from a different
+// GOPATH. It also resets the graph's Corpora to the source
+// directories of that context.
+func (pg *PackageGraph) SetBuildContext(ctxt *build.Context) {
+	pg.buildContext = ctxt
+	pg.Corpora = corporaForContext(ctxt)
+}
+
+// SetLocalRefs sets whether Refs between identifiers of the same
+// package should be recorded in Package.LocalRefs when loading
+// packages. This is off by default, as it roughly doubles the
+// memory used by the graph.
+func (pg *PackageGraph) SetLocalRefs(b bool) {
+	pg.localRefs = b
+}
+
+// SetCallGraph sets the algorithm used to build the call graph of
+// loaded programs. Unless it is NoCallGraph (the default), calls
+// through interfaces and function values are resolved to their
+// possible callees, which are recorded as DynamicCall Refs. This
+// requires building the SSA form of all loaded packages, which is
+// expensive.
+func (pg *PackageGraph) SetCallGraph(algo CallGraphAlgorithm) {
+	pg.callGraph = algo
+}
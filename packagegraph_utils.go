@@ -49,6 +49,6 @@ func FileMTimeVersion(prog loader.Program, pi loader.PackageInfo) (int64, error)
 }
 
 // FilterPass is a filterF function that always says yes.
-func FilterPass(loadpath string, version int64) bool {
-	return true
+func FilterPass(loadpath string, version int64) (bool, error) {
+	return true, nil
 }
@@ -96,3 +96,36 @@ func (p Position) ToProto() *pb.Position {
 		EndCol:    int32(p.EndC),
 	}
 }
+
+// Contains returns whether q lies within p. If q has no End, only its
+// start must lie within p. It returns false if p has no End.
+func (p Position) Contains(q Position) bool {
+	if p.File != q.File || p.EndL < 0 {
+		return false
+	}
+	endL, endC := q.EndL, q.EndC
+	if endL < 0 {
+		endL, endC = q.PosL, q.PosC
+	}
+	return !positionBefore(q.PosL, q.PosC, p.PosL, p.PosC) &&
+		!positionBefore(p.EndL, p.EndC, endL, endC)
+}
+
+// OverlapsLines returns whether p spans any line between start and
+// end, inclusive, of the provided file.
+func (p Position) OverlapsLines(file string, start, end int) bool {
+	if p.File != file {
+		return false
+	}
+	endL := p.EndL
+	if endL < 0 {
+		endL = p.PosL
+	}
+	return p.PosL <= end && start <= endL
+}
+
+// positionBefore returns whether line:col a is strictly before
+// line:col b.
+func positionBefore(aL, aC, bL, bC int) bool {
+	return aL < bL || (aL == bL && aC < bC)
+}
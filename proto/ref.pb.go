@@ -38,6 +38,7 @@ const (
 	Type_Extension      Type = 3
 	Type_Import         Type = 4
 	Type_Reference      Type = 5
+	Type_DynamicCall    Type = 6
 )
 
 var Type_name = map[int32]string{
@@ -47,6 +48,7 @@ var Type_name = map[int32]string{
 	3: "Extension",
 	4: "Import",
 	5: "Reference",
+	6: "DynamicCall",
 }
 var Type_value = map[string]int32{
 	"Instantiation":  0,
@@ -55,6 +57,7 @@ var Type_value = map[string]int32{
 	"Extension":      3,
 	"Import":         4,
 	"Reference":      5,
+	"DynamicCall":    6,
 }
 
 func (x Type) String() string {
@@ -192,27 +195,28 @@ func init() {
 func init() { proto.RegisterFile("ref.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 348 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0x91, 0xcf, 0x6a, 0xdb, 0x40,
-	0x10, 0xc6, 0xab, 0x7f, 0xb6, 0x34, 0xc6, 0xae, 0x3a, 0x14, 0xaa, 0xb6, 0x14, 0x1b, 0xf7, 0x62,
-	0x5a, 0xf0, 0xc1, 0x7d, 0x04, 0xd3, 0x83, 0xc1, 0x87, 0xb0, 0xe4, 0x6e, 0x36, 0xd2, 0xc8, 0x2c,
-	0x59, 0xed, 0x8a, 0xd5, 0x12, 0xe2, 0x17, 0xc8, 0x23, 0xe4, 0x79, 0x83, 0x46, 0x96, 0x73, 0xc9,
-	0x6d, 0xbf, 0xf9, 0xcd, 0x37, 0xfb, 0x31, 0x03, 0x99, 0xa3, 0x7a, 0xdb, 0x3a, 0xeb, 0x2d, 0x26,
-	0x67, 0xeb, 0xa8, 0x5e, 0xbf, 0x04, 0x10, 0x09, 0xaa, 0xb1, 0x80, 0xe9, 0x13, 0xb9, 0x4e, 0x59,
-	0x53, 0x04, 0xab, 0x60, 0x13, 0x89, 0x51, 0xe2, 0x6f, 0x88, 0x6b, 0x67, 0x9b, 0x22, 0x5c, 0x05,
-	0x9b, 0xd9, 0xee, 0xf3, 0x96, 0x7d, 0xdb, 0xa3, 0x2d, 0xa5, 0x57, 0xd6, 0x08, 0x86, 0xb8, 0x84,
-	0xd0, 0xdb, 0x22, 0xfa, 0xb8, 0x25, 0xf4, 0x16, 0x97, 0x10, 0xfb, 0x4b, 0x4b, 0x45, 0xbc, 0x0a,
-	0x36, 0x8b, 0xdd, 0xec, 0xda, 0x72, 0x7f, 0x69, 0x49, 0x30, 0x58, 0x9f, 0x21, 0x1d, 0x0d, 0xf8,
-	0x17, 0xd2, 0xd6, 0x76, 0xca, 0x8f, 0x69, 0xde, 0x67, 0xde, 0x5d, 0xcb, 0xe2, 0xd6, 0xd0, 0x27,
-	0x6f, 0x65, 0xf9, 0x28, 0xcf, 0xc4, 0x11, 0x33, 0x31, 0x4a, 0xfc, 0x0a, 0x89, 0xaa, 0xc8, 0x78,
-	0xce, 0x95, 0x89, 0x41, 0xac, 0x5f, 0x03, 0x48, 0xc7, 0x31, 0xf8, 0x03, 0xd2, 0x5a, 0x69, 0x32,
-	0xb2, 0x21, 0xfe, 0x29, 0x13, 0x37, 0x8d, 0xbf, 0x00, 0x3a, 0x2f, 0x9d, 0x3f, 0x69, 0x65, 0x86,
-	0xd9, 0x89, 0xc8, 0xb8, 0x72, 0x54, 0x86, 0xf0, 0x27, 0x0c, 0xe2, 0x54, 0x5a, 0xcd, 0x3f, 0x24,
-	0x22, 0xe5, 0xc2, 0xde, 0x6a, 0xfc, 0x0e, 0x29, 0x99, 0x6a, 0x70, 0xc6, 0xcc, 0xa6, 0x64, 0x2a,
-	0xf6, 0x7d, 0x83, 0xfe, 0xc9, 0xae, 0x84, 0xc9, 0x84, 0x4c, 0xb5, 0xb7, 0xfa, 0x8f, 0x84, 0xb8,
-	0xdf, 0x07, 0x7e, 0x81, 0xf9, 0xc1, 0x74, 0x5e, 0x1a, 0xaf, 0x78, 0x1d, 0xf9, 0x27, 0x4c, 0x21,
-	0xde, 0x4b, 0xad, 0xf3, 0x00, 0x11, 0x16, 0x87, 0xa6, 0xd5, 0xd4, 0x90, 0xf1, 0x03, 0x0d, 0x71,
-	0x0e, 0xd9, 0xff, 0x67, 0x4f, 0xa6, 0x3f, 0x57, 0x1e, 0x21, 0xc0, 0xe4, 0xd0, 0xb4, 0xd6, 0xf9,
-	0x3c, 0xee, 0x91, 0xa0, 0x9a, 0x1c, 0x99, 0x92, 0xf2, 0xe4, 0x61, 0xc2, 0xb7, 0xff, 0xf7, 0x16,
-	0x00, 0x00, 0xff, 0xff, 0xca, 0x63, 0x79, 0xe7, 0x08, 0x02, 0x00, 0x00,
+	// 353 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x52, 0x4d, 0xcb, 0xd3, 0x40,
+	0x10, 0x36, 0x9f, 0x4d, 0xa6, 0xb4, 0x8d, 0x83, 0x60, 0x54, 0xa4, 0xa5, 0x5e, 0x8a, 0x42, 0x0f,
+	0xf5, 0x27, 0x54, 0x0f, 0x85, 0x1e, 0x64, 0xf1, 0x5e, 0xd6, 0x64, 0x52, 0x16, 0x37, 0xbb, 0x61,
+	0xb3, 0x88, 0xfd, 0x03, 0xfe, 0x04, 0x7f, 0xef, 0x4b, 0x26, 0x4d, 0xdf, 0xcb, 0x7b, 0xcb, 0x33,
+	0xcf, 0xc7, 0x3c, 0x4c, 0x16, 0x72, 0x47, 0xcd, 0xbe, 0x73, 0xd6, 0x5b, 0x4c, 0xae, 0xd6, 0x51,
+	0xb3, 0xfd, 0x17, 0x40, 0x24, 0xa8, 0xc1, 0x12, 0x66, 0x7f, 0xc8, 0xf5, 0xca, 0x9a, 0x32, 0xd8,
+	0x04, 0xbb, 0x48, 0x4c, 0x10, 0x3f, 0x41, 0xdc, 0x38, 0xdb, 0x96, 0xe1, 0x26, 0xd8, 0xcd, 0x0f,
+	0xab, 0x3d, 0xfb, 0xf6, 0x67, 0x5b, 0x49, 0xaf, 0xac, 0x11, 0x4c, 0xe2, 0x1a, 0x42, 0x6f, 0xcb,
+	0xe8, 0x65, 0x49, 0xe8, 0x2d, 0xae, 0x21, 0xf6, 0xb7, 0x8e, 0xca, 0x78, 0x13, 0xec, 0x96, 0x87,
+	0xf9, 0x5d, 0xf2, 0xf3, 0xd6, 0x91, 0x60, 0x62, 0x7b, 0x85, 0x6c, 0x32, 0xe0, 0x17, 0xc8, 0x3a,
+	0xdb, 0x2b, 0x3f, 0xb5, 0x79, 0xce, 0xfc, 0x71, 0x1f, 0x8b, 0x87, 0x60, 0x68, 0xde, 0xc9, 0xea,
+	0xb7, 0xbc, 0x12, 0x57, 0xcc, 0xc5, 0x04, 0xf1, 0x0d, 0x24, 0xaa, 0x26, 0xe3, 0xb9, 0x57, 0x2e,
+	0x46, 0xb0, 0xfd, 0x1f, 0x40, 0x36, 0xc5, 0xe0, 0x7b, 0xc8, 0x1a, 0xa5, 0xc9, 0xc8, 0x96, 0x78,
+	0x53, 0x2e, 0x1e, 0x18, 0x3f, 0x02, 0xf4, 0x5e, 0x3a, 0x7f, 0xd1, 0xca, 0x8c, 0xd9, 0x89, 0xc8,
+	0x79, 0x72, 0x56, 0x86, 0xf0, 0x03, 0x8c, 0xe0, 0x52, 0x59, 0xcd, 0x1b, 0x12, 0x91, 0xf1, 0xe0,
+	0x68, 0x35, 0xbe, 0x83, 0x8c, 0x4c, 0x3d, 0x3a, 0x63, 0xe6, 0x66, 0x64, 0x6a, 0xf6, 0xbd, 0x85,
+	0xe1, 0x93, 0x5d, 0x09, 0x33, 0x29, 0x99, 0xfa, 0x68, 0xf5, 0x67, 0x07, 0xf1, 0x70, 0x0f, 0x7c,
+	0x0d, 0x8b, 0x93, 0xe9, 0xbd, 0x34, 0x5e, 0xf1, 0x39, 0x8a, 0x57, 0x98, 0x41, 0x7c, 0x94, 0x5a,
+	0x17, 0x01, 0x22, 0x2c, 0x4f, 0x6d, 0xa7, 0xa9, 0x25, 0xe3, 0x47, 0x36, 0xc4, 0x05, 0xe4, 0xdf,
+	0xff, 0x7a, 0x32, 0xc3, 0xef, 0x2a, 0x22, 0x04, 0x48, 0x4f, 0x6d, 0x67, 0x9d, 0x2f, 0xe2, 0x81,
+	0x12, 0xd4, 0x90, 0x23, 0x53, 0x51, 0x91, 0xe0, 0x0a, 0xe6, 0xdf, 0x6e, 0x46, 0xb6, 0xaa, 0xe2,
+	0xb8, 0xf4, 0x57, 0xca, 0x8f, 0xe1, 0xeb, 0xd3, 0x00, 0x4e, 0x46, 0x6e, 0x25, 0x19, 0x02, 0x00,
+	0x00,
 }
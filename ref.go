@@ -1,8 +1,10 @@
 package goref
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	pb "github.com/korfuri/goref/proto"
 )
@@ -42,6 +44,22 @@ func (r *Ref) String() string {
 		r.FromPackage, r.FromIdent, r.FromPosition)
 }
 
+// DocumentID returns a consistent id for this Ref. This can be used
+// to index the Ref e.g. in ElasticSearch, so that indexing it again
+// overwrites the same document. The ID contains the version of the
+// FromPackage and a hash of both ends of the Ref and its type.
+func (r Ref) DocumentID() string {
+	h := sha1.New()
+	h.Write([]byte(strings.Join([]string{
+		r.RefType.String(),
+		r.FromPackage.Path, r.FromPosition.String(), r.FromIdent,
+		fmt.Sprint(r.ToPackage.Version), r.ToPackage.Path, r.ToPosition.String(), r.ToIdent,
+	}, "\x00")))
+	// "v1" is a prefix to recognize this DocumentID format, in
+	// case the format changes in the future.
+	return fmt.Sprintf("v1@%d@%x", r.FromPackage.Version, h.Sum(nil))
+}
+
 // MarshalJSON implements encoding/json.Marshaler interface
 func (r Ref) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.ToProto())
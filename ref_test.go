@@ -39,3 +39,28 @@ func TestJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, correct, string(j))
 }
+
+func TestRefDocumentID(t *testing.T) {
+	from := &goref.Package{Path: "path/to/x", Version: 42}
+	to := &goref.Package{Path: "path/to/y", Version: 43}
+	newRef := func(line int) goref.Ref {
+		return goref.Ref{
+			RefType:      goref.Call,
+			FromPosition: goref.Position{File: "x/foo.go", PosL: line, PosC: 2, EndL: line, EndC: 5},
+			ToPosition:   goref.Position{File: "y/bar.go", PosL: 10, PosC: 6, EndL: -1, EndC: -1},
+			FromIdent:    "Bar",
+			ToIdent:      "Bar",
+			FromPackage:  from,
+			ToPackage:    to,
+		}
+	}
+
+	r := newRef(12)
+	assert.Regexp(t, "^v1@42@[0-9a-f]{40}$", r.DocumentID())
+	assert.Equal(t, r.DocumentID(), newRef(12).DocumentID())
+	assert.NotEqual(t, r.DocumentID(), newRef(13).DocumentID())
+
+	r2 := newRef(12)
+	r2.RefType = goref.Reference
+	assert.NotEqual(t, r.DocumentID(), r2.DocumentID())
+}
@@ -2,6 +2,7 @@ package goref
 
 import (
 	"encoding/json"
+	"fmt"
 	"go/ast"
 
 	"golang.org/x/tools/go/loader"
@@ -34,6 +35,12 @@ const (
 	// Reference is the default, used when we can't determine the
 	// type of reference.
 	Reference
+
+	// DynamicCall is a call through an interface or a function
+	// value, from the call site to a function or method it may
+	// dispatch to. These are only computed if the PackageGraph
+	// was configured with SetCallGraph.
+	DynamicCall
 )
 
 func (rt RefType) String() string {
@@ -50,10 +57,22 @@ func (rt RefType) String() string {
 		return "Import"
 	case Reference:
 		return "Reference"
+	case DynamicCall:
+		return "DynamicCall"
 	}
 	panic("Unknown RefType used")
 }
 
+// ParseRefType returns the RefType whose name is s, e.g. "Call".
+func ParseRefType(s string) (RefType, error) {
+	for rt := RefType(Instantiation); rt <= DynamicCall; rt++ {
+		if rt.String() == s {
+			return rt, nil
+		}
+	}
+	return Reference, fmt.Errorf("Unknown reference type %s", s)
+}
+
 // refTypeForIdent walks the AST from a given Ident and deducts what
 // type of Reference it is performing.
 func refTypeForIdent(prog *loader.Program, id *ast.Ident) RefType {
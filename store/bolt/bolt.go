@@ -0,0 +1,506 @@
+// Package bolt implements store.Store in an embedded bbolt key-value
+// database. The database is a single memory-mapped file, so a server
+// can open an index built by cmd/index and serve it right away,
+// without loading any package.
+//
+// Refs are stored once, encoded as pb.Ref protobufs, and indexed by
+// keys designed for prefix scans: by target package and identifier,
+// by source file and by target file. Declarations are stored as
+// pb.Location protobufs, keyed by package.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/symbols"
+	log "github.com/sirupsen/logrus"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// Buckets of the database. Keys are made of strings separated by
+// sep, which can't appear in load paths, identifiers or file names,
+// and of big-endian integers so that they sort numerically.
+var (
+	// packages maps loadpath, version to nothing.
+	packagesBucket = []byte("packages")
+
+	// files maps loadpath, version, filename to nothing.
+	filesBucket = []byte("files")
+
+	// refs maps loadpath, version, sequence number of the source
+	// package to an encoded pb.Ref. The key of a Ref is its ref
+	// key.
+	refsBucket = []byte("refs")
+
+	// toIdent maps target loadpath, target ident, ref key to
+	// nothing.
+	toIdentBucket = []byte("to_ident")
+
+	// fromFile maps source filename, ref key to nothing.
+	fromFileBucket = []byte("from_file")
+
+	// toFile maps target filename, ref key to nothing.
+	toFileBucket = []byte("to_file")
+
+	// symbols maps loadpath, version, kind, identifier to the
+	// encoded pb.Location of a declaration.
+	symbolsBucket = []byte("symbols")
+
+	buckets = [][]byte{packagesBucket, filesBucket, refsBucket, toIdentBucket, fromFileBucket, toFileBucket, symbolsBucket}
+)
+
+const (
+	sep = 0
+
+	// How long to wait for another process to release the
+	// database, e.g. an indexer holding it open for writing.
+	lockTimeout = 5 * time.Second
+)
+
+// key joins strings with sep, so that the key of a string is a prefix
+// of the keys that start with that string.
+func key(parts ...string) []byte {
+	var b bytes.Buffer
+	for _, p := range parts {
+		b.WriteString(p)
+		b.WriteByte(sep)
+	}
+	return b.Bytes()
+}
+
+// versionKey returns the key of a version of a package, which is the
+// prefix of the keys of its files and Refs.
+func versionKey(loadpath string, version int64) []byte {
+	k := key(loadpath)
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], uint64(version))
+	return append(k, v[:]...)
+}
+
+// refKey returns the key of the i-th Ref of a version of a package.
+func refKey(loadpath string, version int64, i int) []byte {
+	k := versionKey(loadpath, version)
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(i))
+	return append(k, n[:]...)
+}
+
+// indexKeys returns the keys of a Ref in each index bucket.
+func indexKeys(r *pb.Ref, rk []byte) map[string][]byte {
+	return map[string][]byte{
+		string(toIdentBucket):  append(key(r.To.Package, r.To.Ident), rk...),
+		string(fromFileBucket): append(key(r.From.Position.Filename), rk...),
+		string(toFileBucket):   append(key(r.To.Position.Filename), rk...),
+	}
+}
+
+// boltStore implements store.Store in a bbolt database.
+type boltStore struct {
+	db *bbolt.DB
+}
+
+// Open opens the database at path for reading and writing, creating
+// it if needed. Only one process may have it open for writing.
+func Open(path string) (store.Store, error) {
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, b := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+// OpenReadOnly opens an existing database at path for reading.
+// Several processes may have it open for reading, but not while
+// another one has it open for writing. PutPackages fails on a
+// read-only Store.
+func OpenReadOnly(path string) (store.Store, error) {
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{ReadOnly: true, Timeout: lockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.View(func(tx *bbolt.Tx) error {
+		for _, b := range buckets {
+			if tx.Bucket(b) == nil {
+				return fmt.Errorf("%s is not a goref index", path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) PackageExists(ctx context.Context, loadpath string, version int64) (bool, error) {
+	exists := false
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		exists = tx.Bucket(packagesBucket).Get(versionKey(loadpath, version)) != nil
+		return nil
+	})
+	return exists, err
+}
+
+// PutPackages stores each package in its own transaction, so a
+// package is either fully stored or not at all.
+func (s *boltStore) PutPackages(ctx context.Context, packages []*goref.Package) error {
+	for _, p := range packages {
+		log.Debugf("Storing Package %s", p)
+		if err := s.db.Update(func(tx *bbolt.Tx) error {
+			return putPackage(tx, p)
+		}); err != nil {
+			return fmt.Errorf("Couldn't store package %s@%d: %s", p.Path, p.Version, err)
+		}
+	}
+	return nil
+}
+
+// deletePrefix deletes the keys of a bucket that start with prefix.
+func deletePrefix(b *bbolt.Bucket, prefix []byte) error {
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePackage deletes a version of a package, with its files,
+// symbols, Refs and their index keys.
+func deletePackage(tx *bbolt.Tx, loadpath string, version int64) error {
+	vk := versionKey(loadpath, version)
+	refs := tx.Bucket(refsBucket)
+	c := refs.Cursor()
+	for k, v := c.Seek(vk); k != nil && bytes.HasPrefix(k, vk); k, v = c.Seek(vk) {
+		var r pb.Ref
+		if err := proto.Unmarshal(v, &r); err != nil {
+			return err
+		}
+		for b, ik := range indexKeys(&r, k) {
+			if err := tx.Bucket([]byte(b)).Delete(ik); err != nil {
+				return err
+			}
+		}
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	if err := deletePrefix(tx.Bucket(filesBucket), vk); err != nil {
+		return err
+	}
+	if err := deletePrefix(tx.Bucket(symbolsBucket), vk); err != nil {
+		return err
+	}
+	return tx.Bucket(packagesBucket).Delete(vk)
+}
+
+func putPackage(tx *bbolt.Tx, p *goref.Package) error {
+	// Anything left by a previous attempt is replaced.
+	if err := deletePackage(tx, p.Path, p.Version); err != nil {
+		return err
+	}
+	vk := versionKey(p.Path, p.Version)
+	files := tx.Bucket(filesBucket)
+	for _, f := range p.Files {
+		if err := files.Put(append(key(string(vk)), f...), []byte{}); err != nil {
+			return err
+		}
+	}
+	refs := tx.Bucket(refsBucket)
+	for i, ref := range p.OutRefs {
+		r := ref.ToProto()
+		v, err := proto.Marshal(r)
+		if err != nil {
+			return err
+		}
+		rk := refKey(p.Path, p.Version, i)
+		if err := refs.Put(rk, v); err != nil {
+			return err
+		}
+		for b, ik := range indexKeys(r, rk) {
+			if err := tx.Bucket([]byte(b)).Put(ik, []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+	syms := tx.Bucket(symbolsBucket)
+	for _, d := range p.Decls() {
+		v, err := proto.Marshal(&pb.Location{
+			Position: d.Position.ToProto(),
+			Package:  p.Path,
+			Ident:    d.Ident(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := syms.Put(append(key(string(vk), d.Kind.String()), d.Ident()...), v); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(packagesBucket).Put(vk, []byte{})
+}
+
+// scan calls f with the keys of a bucket that start with prefix.
+func scan(b *bbolt.Bucket, prefix []byte, f func(k []byte) error) error {
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		if err := f(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refKeyOf returns the ref key at the end of an index key. A ref key
+// is a load path, a separator and two integers, and the load path
+// starts after the last separator that precedes them.
+func refKeyOf(ik []byte) []byte {
+	start := bytes.LastIndexByte(ik[:len(ik)-17], sep) + 1
+	return ik[start:]
+}
+
+// refsByIndex returns the Refs whose keys in an index bucket start
+// with prefix.
+func (s *boltStore) refsByIndex(bucket, prefix []byte) ([]*pb.Ref, error) {
+	result := make([]*pb.Ref, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		refs := tx.Bucket(refsBucket)
+		return scan(tx.Bucket(bucket), prefix, func(ik []byte) error {
+			v := refs.Get(refKeyOf(ik))
+			if v == nil {
+				return fmt.Errorf("Index %s has a key for a missing Ref: %q", bucket, ik)
+			}
+			var r pb.Ref
+			if err := proto.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			result = append(result, &r)
+			return nil
+		})
+	})
+	return result, err
+}
+
+// refsByIndexPage returns a page of the Refs whose keys in an index
+// bucket start with prefix, in the order of their keys. Page tokens
+// hold the index key of the last Ref of a page.
+func (s *boltStore) refsByIndexPage(bucket, prefix []byte, page store.Page) ([]*pb.Ref, string, error) {
+	start := prefix
+	if page.Token != "" {
+		if err := store.DecodeToken(page.Token, &start); err != nil {
+			return nil, "", err
+		}
+		if !bytes.HasPrefix(start, prefix) {
+			return nil, "", store.ErrInvalidPageToken
+		}
+	}
+	limit := page.Limit()
+	result := make([]*pb.Ref, 0)
+	next := ""
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		refs := tx.Bucket(refsBucket)
+		c := tx.Bucket(bucket).Cursor()
+		var last []byte
+		for ik, _ := c.Seek(start); ik != nil && bytes.HasPrefix(ik, prefix); ik, _ = c.Next() {
+			if page.Token != "" && bytes.Equal(ik, start) {
+				continue
+			}
+			if len(result) == limit {
+				next = store.EncodeToken(last)
+				return nil
+			}
+			v := refs.Get(refKeyOf(ik))
+			if v == nil {
+				return fmt.Errorf("Index %s has a key for a missing Ref: %q", bucket, ik)
+			}
+			var r pb.Ref
+			if err := proto.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			result = append(result, &r)
+			// Keys are only valid during the transaction.
+			last = append([]byte{}, ik...)
+		}
+		return nil
+	})
+	return result, next, err
+}
+
+func (s *boltStore) RefsToFile(ctx context.Context, filename string, page store.Page) ([]*pb.Ref, string, error) {
+	return s.refsByIndexPage(toFileBucket, key(filename), page)
+}
+
+func (s *boltStore) RefsFromFile(ctx context.Context, filename string) ([]*pb.Ref, error) {
+	return s.refsByIndex(fromFileBucket, key(filename))
+}
+
+// RefsFromIdent scans the Refs of the source package, which are keyed
+// by its load path, instead of maintaining another index.
+func (s *boltStore) RefsFromIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error) {
+	result := make([]*pb.Ref, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		prefix := key(loadpath)
+		c := tx.Bucket(refsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var r pb.Ref
+			if err := proto.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if r.From.Ident == ident {
+				result = append(result, &r)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *boltStore) RefsToIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error) {
+	return s.refsByIndex(toIdentBucket, key(loadpath, ident))
+}
+
+func (s *boltStore) RefsToPackage(ctx context.Context, loadpath string) ([]*pb.Ref, error) {
+	return s.refsByIndex(toIdentBucket, key(loadpath))
+}
+
+// sortedSet returns the sorted elements of a set of strings.
+func sortedSet(set map[string]bool) []string {
+	l := make([]string, 0, len(set))
+	for s := range set {
+		l = append(l, s)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// PackageFiles reads the files of all the versions of a package
+// before returning a page of them.
+func (s *boltStore) PackageFiles(ctx context.Context, loadpath string, page store.Page) ([]string, string, error) {
+	files := make(map[string]bool)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		prefix := key(loadpath)
+		return scan(tx.Bucket(filesBucket), prefix, func(k []byte) error {
+			// Skips the version and separator that follow
+			// the load path.
+			files[string(k[len(prefix)+9:])] = true
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return store.PageStrings(sortedSet(files), page)
+}
+
+// ListPackages reads the load paths of all the matching packages
+// before returning a page of them.
+func (s *boltStore) ListPackages(ctx context.Context, prefix string, page store.Page) ([]string, string, error) {
+	packages := make(map[string]bool)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return scan(tx.Bucket(packagesBucket), []byte(prefix), func(k []byte) error {
+			packages[string(k[:bytes.IndexByte(k, sep)])] = true
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return store.PageStrings(sortedSet(packages), page)
+}
+
+// countRefsTo returns the number of Refs to a symbol, which are found
+// by their target identifier and then by their target position.
+func countRefsTo(tx *bbolt.Tx, sym *symbols.Symbol) (int, error) {
+	n := 0
+	refs := tx.Bucket(refsBucket)
+	p := sym.Location.Position
+	err := scan(tx.Bucket(toIdentBucket), key(sym.Location.Package, sym.Name()), func(ik []byte) error {
+		var r pb.Ref
+		if err := proto.Unmarshal(refs.Get(refKeyOf(ik)), &r); err != nil {
+			return err
+		}
+		to := r.To.GetPosition()
+		if to.GetFilename() == p.GetFilename() && to.GetStartLine() == p.GetStartLine() && to.GetStartCol() == p.GetStartCol() {
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// SearchSymbols scans the symbols of the packages that start with the
+// query's package prefix, and counts the Refs to the matches that may
+// be returned.
+func (s *boltStore) SearchSymbols(ctx context.Context, q symbols.Query) ([]*symbols.Result, error) {
+	results := make([]*symbols.Result, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		// Versions of a package are scanned in increasing
+		// order, so each identifier ends up with its latest
+		// version.
+		latest := make(map[string]*symbols.Symbol)
+		idents := make([]string, 0)
+		prefix := []byte(q.PackagePrefix)
+		c := tx.Bucket(symbolsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var loc pb.Location
+			if err := proto.Unmarshal(v, &loc); err != nil {
+				return err
+			}
+			// Skips the load path, the version and their
+			// separators.
+			kind := k[len(loc.Package)+10:]
+			kind = kind[:bytes.IndexByte(kind, sep)]
+			ident := loc.Package + "." + loc.Ident
+			if _, in := latest[ident]; !in {
+				idents = append(idents, ident)
+			}
+			latest[ident] = &symbols.Symbol{Location: &loc, Kind: string(kind)}
+		}
+
+		for _, ident := range idents {
+			sym := latest[ident]
+			if m := q.Match(sym); m != symbols.NoMatch {
+				results = append(results, &symbols.Result{Symbol: sym, Match: m})
+			}
+		}
+		results = symbols.Survivors(q, results)
+		for _, r := range results {
+			var err error
+			if r.Refs, err = countRefsTo(tx, r.Symbol); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return symbols.Rank(q, results), nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
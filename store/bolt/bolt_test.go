@@ -0,0 +1,88 @@
+package bolt_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/bolt"
+	"github.com/korfuri/goref/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// openStore opens a Store in a temporary directory, which is removed
+// by the returned function.
+func openStore(t *testing.T) (store.Store, string, func()) {
+	dir, err := ioutil.TempDir("", "goref-bolt")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "goref.db")
+	s, err := bolt.Open(path)
+	assert.NoError(t, err)
+	return s, path, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestBolt_roundTrip(t *testing.T) {
+	s, _, cleanup := openStore(t)
+	defer cleanup()
+	storetest.RoundTrip(t, s)
+}
+
+func TestBolt_pages(t *testing.T) {
+	s, _, cleanup := openStore(t)
+	defer cleanup()
+	storetest.Pages(t, s)
+}
+
+func TestBolt_readOnly(t *testing.T) {
+	s, path, cleanup := openStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	a := &goref.Package{Path: "a", Version: 1, Files: []string{"a/a.go", "a/b.go"}}
+	b := &goref.Package{Path: "b", Version: 1, Files: []string{"b/b.go"}}
+	ref := func(file string) *goref.Ref {
+		return &goref.Ref{
+			FromPackage:  a,
+			FromPosition: goref.Position{File: file, PosL: 1, PosC: 1},
+			ToPackage:    b,
+			ToIdent:      "B",
+			ToPosition:   goref.Position{File: "b/b.go", PosL: 2, PosC: 3},
+		}
+	}
+	a.OutRefs = []*goref.Ref{ref("a/a.go"), ref("a/b.go")}
+	assert.NoError(t, s.PutPackages(ctx, []*goref.Package{a, b}))
+	// Storing a package again replaces it, along with its index
+	// keys.
+	a.Files = a.Files[:1]
+	a.OutRefs = a.OutRefs[:1]
+	assert.NoError(t, s.PutPackages(ctx, []*goref.Package{a}))
+	assert.NoError(t, s.Close())
+
+	s, err := bolt.OpenReadOnly(path)
+	assert.NoError(t, err)
+	exists, err := s.PackageExists(ctx, "a", 1)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	files, _, err := s.PackageFiles(ctx, "a", store.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/a.go"}, files)
+	refs, err := s.RefsToIdent(ctx, "b", "B")
+	assert.NoError(t, err)
+	assert.Len(t, refs, 1)
+	assert.Equal(t, "a/a.go", refs[0].From.Position.Filename)
+	refs, err = s.RefsFromFile(ctx, "a/b.go")
+	assert.NoError(t, err)
+	assert.Empty(t, refs)
+	assert.Error(t, s.PutPackages(ctx, []*goref.Package{b}))
+	assert.NoError(t, s.Close())
+
+	_, err = bolt.OpenReadOnly(path + ".missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,268 @@
+// Code generated by mockery v1.0.0
+package mocks
+
+import context "context"
+import goref "github.com/korfuri/goref"
+import mock "github.com/stretchr/testify/mock"
+import proto "github.com/korfuri/goref/proto"
+import store "github.com/korfuri/goref/store"
+import symbols "github.com/korfuri/goref/symbols"
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// Close provides a mock function with given fields:
+func (_m *Store) Close() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListPackages provides a mock function with given fields: ctx, prefix, page
+func (_m *Store) ListPackages(ctx context.Context, prefix string, page store.Page) ([]string, string, error) {
+	ret := _m.Called(ctx, prefix, page)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string, store.Page) []string); ok {
+		r0 = rf(ctx, prefix, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, string, store.Page) string); ok {
+		r1 = rf(ctx, prefix, page)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, store.Page) error); ok {
+		r2 = rf(ctx, prefix, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// PackageExists provides a mock function with given fields: ctx, loadpath, version
+func (_m *Store) PackageExists(ctx context.Context, loadpath string, version int64) (bool, error) {
+	ret := _m.Called(ctx, loadpath, version)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) bool); ok {
+		r0 = rf(ctx, loadpath, version)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, loadpath, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PackageFiles provides a mock function with given fields: ctx, loadpath, page
+func (_m *Store) PackageFiles(ctx context.Context, loadpath string, page store.Page) ([]string, string, error) {
+	ret := _m.Called(ctx, loadpath, page)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string, store.Page) []string); ok {
+		r0 = rf(ctx, loadpath, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, string, store.Page) string); ok {
+		r1 = rf(ctx, loadpath, page)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, store.Page) error); ok {
+		r2 = rf(ctx, loadpath, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// PutPackages provides a mock function with given fields: ctx, packages
+func (_m *Store) PutPackages(ctx context.Context, packages []*goref.Package) error {
+	ret := _m.Called(ctx, packages)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*goref.Package) error); ok {
+		r0 = rf(ctx, packages)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefsFromFile provides a mock function with given fields: ctx, filename
+func (_m *Store) RefsFromFile(ctx context.Context, filename string) ([]*proto.Ref, error) {
+	ret := _m.Called(ctx, filename)
+
+	var r0 []*proto.Ref
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*proto.Ref); ok {
+		r0 = rf(ctx, filename)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*proto.Ref)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, filename)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefsFromIdent provides a mock function with given fields: ctx, loadpath, ident
+func (_m *Store) RefsFromIdent(ctx context.Context, loadpath string, ident string) ([]*proto.Ref, error) {
+	ret := _m.Called(ctx, loadpath, ident)
+
+	var r0 []*proto.Ref
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*proto.Ref); ok {
+		r0 = rf(ctx, loadpath, ident)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*proto.Ref)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, loadpath, ident)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefsToFile provides a mock function with given fields: ctx, filename, page
+func (_m *Store) RefsToFile(ctx context.Context, filename string, page store.Page) ([]*proto.Ref, string, error) {
+	ret := _m.Called(ctx, filename, page)
+
+	var r0 []*proto.Ref
+	if rf, ok := ret.Get(0).(func(context.Context, string, store.Page) []*proto.Ref); ok {
+		r0 = rf(ctx, filename, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*proto.Ref)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, string, store.Page) string); ok {
+		r1 = rf(ctx, filename, page)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, store.Page) error); ok {
+		r2 = rf(ctx, filename, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RefsToIdent provides a mock function with given fields: ctx, loadpath, ident
+func (_m *Store) RefsToIdent(ctx context.Context, loadpath string, ident string) ([]*proto.Ref, error) {
+	ret := _m.Called(ctx, loadpath, ident)
+
+	var r0 []*proto.Ref
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*proto.Ref); ok {
+		r0 = rf(ctx, loadpath, ident)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*proto.Ref)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, loadpath, ident)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefsToPackage provides a mock function with given fields: ctx, loadpath
+func (_m *Store) RefsToPackage(ctx context.Context, loadpath string) ([]*proto.Ref, error) {
+	ret := _m.Called(ctx, loadpath)
+
+	var r0 []*proto.Ref
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*proto.Ref); ok {
+		r0 = rf(ctx, loadpath)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*proto.Ref)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, loadpath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchSymbols provides a mock function with given fields: ctx, q
+func (_m *Store) SearchSymbols(ctx context.Context, q symbols.Query) ([]*symbols.Result, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 []*symbols.Result
+	if rf, ok := ret.Get(0).(func(context.Context, symbols.Query) []*symbols.Result); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*symbols.Result)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, symbols.Query) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
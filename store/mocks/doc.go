@@ -0,0 +1,3 @@
+//go:generate mockery -name Store -dir .. -output ./
+
+package mocks
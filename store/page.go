@@ -0,0 +1,105 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	// DefaultPageSize is the number of results of a Page whose
+	// Size is zero.
+	DefaultPageSize = 1000
+
+	// MaxPageSize is the largest number of results of a Page.
+	// Larger sizes are reduced to it.
+	MaxPageSize = 5000
+)
+
+// ErrInvalidPageToken is returned by Stores for page tokens that they
+// didn't return.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// A Page selects a page of the results of a query. Queries that take
+// a Page return their results in a stable order, along with the token
+// of the next page, or an empty token for the last page.
+type Page struct {
+	// Size is the maximum number of results of the page.
+	Size int
+
+	// Token is the token of the page, as returned with the
+	// previous page, or empty for the first page. Tokens are
+	// opaque, and only valid for the query that returned them.
+	Token string
+}
+
+// NewPage returns the Page of a request's page size and page token,
+// or an error if the size is negative.
+func NewPage(size int32, token string) (Page, error) {
+	if size < 0 {
+		return Page{}, fmt.Errorf("Invalid page size %d", size)
+	}
+	return Page{Size: int(size), Token: token}, nil
+}
+
+// Limit returns the maximum number of results of the page.
+func (p Page) Limit() int {
+	if p.Size <= 0 {
+		return DefaultPageSize
+	}
+	if p.Size > MaxPageSize {
+		return MaxPageSize
+	}
+	return p.Size
+}
+
+// EncodeToken returns a page token that holds v, as encoded by
+// encoding/json.
+func EncodeToken(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// Tokens are only made of values that can be
+		// marshalled.
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeToken decodes a token returned by EncodeToken into v.
+// Numbers decoded into an interface{} are json.Numbers, so that
+// they're encoded again exactly. It returns ErrInvalidPageToken if the
+// token can't be decoded.
+func DecodeToken(token string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidPageToken
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(v); err != nil {
+		return ErrInvalidPageToken
+	}
+	return nil
+}
+
+// PageStrings returns a page of sorted, distinct strings, and the
+// token of the next page. Tokens hold the last string of a page, so
+// that pages stay consistent if strings are added or removed.
+func PageStrings(l []string, p Page) ([]string, string, error) {
+	start := 0
+	if p.Token != "" {
+		var last string
+		if err := DecodeToken(p.Token, &last); err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(l), func(i int) bool { return l[i] > last })
+	}
+	end := start + p.Limit()
+	if end >= len(l) {
+		return l[start:], "", nil
+	}
+	return l[start:end], EncodeToken(l[end-1]), nil
+}
@@ -0,0 +1,368 @@
+// Package sqlite implements store.Store in an embedded SQLite
+// database, for deployments that don't warrant an ElasticSearch
+// cluster. It uses a pure-Go SQLite, so it doesn't require cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/symbols"
+	log "github.com/sirupsen/logrus"
+
+	// Registers the "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// schema creates goref's tables and indexes if they don't exist.
+//
+// Refs are looked up by target identifier, by source file and by
+// target file, which are all indexed. The version of a file, a ref or
+// a symbol is the version of the package it belongs to.
+const schema = `
+CREATE TABLE IF NOT EXISTS packages (
+	loadpath TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	PRIMARY KEY (loadpath, version)
+);
+CREATE TABLE IF NOT EXISTS files (
+	filename TEXT NOT NULL,
+	package TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	PRIMARY KEY (package, version, filename)
+);
+CREATE TABLE IF NOT EXISTS refs (
+	version INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	from_package TEXT NOT NULL,
+	from_ident TEXT NOT NULL,
+	from_file TEXT NOT NULL,
+	from_start_line INTEGER NOT NULL,
+	from_start_col INTEGER NOT NULL,
+	from_end_line INTEGER NOT NULL,
+	from_end_col INTEGER NOT NULL,
+	to_package TEXT NOT NULL,
+	to_ident TEXT NOT NULL,
+	to_file TEXT NOT NULL,
+	to_start_line INTEGER NOT NULL,
+	to_start_col INTEGER NOT NULL,
+	to_end_line INTEGER NOT NULL,
+	to_end_col INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS refs_to_ident ON refs (to_package, to_ident);
+CREATE INDEX IF NOT EXISTS refs_from_file ON refs (from_file);
+CREATE INDEX IF NOT EXISTS refs_to_file ON refs (to_file);
+CREATE INDEX IF NOT EXISTS refs_from_package ON refs (from_package, version);
+CREATE TABLE IF NOT EXISTS symbols (
+	package TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	ident TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	filename TEXT NOT NULL,
+	start_line INTEGER NOT NULL,
+	start_col INTEGER NOT NULL,
+	end_line INTEGER NOT NULL,
+	end_col INTEGER NOT NULL,
+	PRIMARY KEY (package, version, ident)
+);
+CREATE INDEX IF NOT EXISTS symbols_ident ON symbols (ident);
+`
+
+// refColumns are the columns of the refs table, in the order used by
+// scanRef.
+const refColumns = `version, type,
+	from_package, from_ident, from_file, from_start_line, from_start_col, from_end_line, from_end_col,
+	to_package, to_ident, to_file, to_start_line, to_start_col, to_end_line, to_end_col`
+
+// sqliteStore implements store.Store in a SQLite database.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database at path, creating it and goref's
+// schema if needed.
+func Open(path string) (store.Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only allows one writer at a time.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Couldn't create the schema of %s: %s", path, err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) PackageExists(ctx context.Context, loadpath string, version int64) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM packages WHERE loadpath = ? AND version = ?`,
+		loadpath, version).Scan(&n)
+	return n > 0, err
+}
+
+// PutPackages stores each package in its own transaction, so a
+// package is either fully stored or not at all.
+func (s *sqliteStore) PutPackages(ctx context.Context, packages []*goref.Package) error {
+	for _, p := range packages {
+		log.Debugf("Storing Package %s", p)
+		if err := s.putPackage(ctx, p); err != nil {
+			return fmt.Errorf("Couldn't store package %s@%d: %s", p.Path, p.Version, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) putPackage(ctx context.Context, p *goref.Package) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Anything left by a previous attempt is replaced.
+	for _, q := range []string{
+		`DELETE FROM files WHERE package = ? AND version = ?`,
+		`DELETE FROM refs WHERE from_package = ? AND version = ?`,
+		`DELETE FROM symbols WHERE package = ? AND version = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, p.Path, p.Version); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range p.Files {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO files (filename, package, version) VALUES (?, ?, ?)`,
+			f, p.Path, p.Version); err != nil {
+			return err
+		}
+	}
+
+	insertRef, err := tx.PrepareContext(ctx,
+		`INSERT INTO refs (`+refColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertRef.Close()
+	for _, r := range p.OutRefs {
+		from, to := r.FromPosition, r.ToPosition
+		if _, err := insertRef.ExecContext(ctx, p.Version, int(r.RefType),
+			p.Path, r.FromIdent, from.File, from.PosL, from.PosC, from.EndL, from.EndC,
+			r.ToPackage.Path, r.ToIdent, to.File, to.PosL, to.PosC, to.EndL, to.EndC); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range p.Decls() {
+		pos := d.Position
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO symbols (package, version, ident, kind, filename, start_line, start_col, end_line, end_col)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			p.Path, p.Version, d.Ident(), d.Kind.String(), pos.File, pos.PosL, pos.PosC, pos.EndL, pos.EndC); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO packages (loadpath, version) VALUES (?, ?)`,
+		p.Path, p.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanRef reads a Ref from a row of refColumns, preceded by the
+// columns scanned into dest.
+func scanRef(rows *sql.Rows, dest ...interface{}) (*pb.Ref, error) {
+	r := &pb.Ref{
+		From: &pb.Location{Position: &pb.Position{}},
+		To:   &pb.Location{Position: &pb.Position{}},
+	}
+	var typ int32
+	from, to := r.From.Position, r.To.Position
+	err := rows.Scan(append(dest, &r.Version, &typ,
+		&r.From.Package, &r.From.Ident, &from.Filename, &from.StartLine, &from.StartCol, &from.EndLine, &from.EndCol,
+		&r.To.Package, &r.To.Ident, &to.Filename, &to.StartLine, &to.StartCol, &to.EndLine, &to.EndCol)...)
+	r.Type = pb.Type(typ)
+	return r, err
+}
+
+// queryRefs returns the Refs that match a WHERE clause.
+func (s *sqliteStore) queryRefs(ctx context.Context, where string, args ...interface{}) ([]*pb.Ref, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+refColumns+` FROM refs WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	refs := make([]*pb.Ref, 0)
+	for rows.Next() {
+		r, err := scanRef(rows)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+	return refs, rows.Err()
+}
+
+// queryRefsPage returns a page of the Refs that match a WHERE clause.
+// Refs are sorted by rowid, and page tokens hold the rowid of the
+// last Ref of a page.
+func (s *sqliteStore) queryRefsPage(ctx context.Context, page store.Page, where string, args ...interface{}) ([]*pb.Ref, string, error) {
+	var after int64
+	if page.Token != "" {
+		if err := store.DecodeToken(page.Token, &after); err != nil {
+			return nil, "", err
+		}
+	}
+	limit := page.Limit()
+	// One more Ref is queried to find out if there's a next page.
+	args = append(args, after, limit+1)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT rowid, `+refColumns+` FROM refs WHERE `+where+` AND rowid > ? ORDER BY rowid LIMIT ?`, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	refs := make([]*pb.Ref, 0)
+	next := ""
+	for rows.Next() {
+		var rowid int64
+		r, err := scanRef(rows, &rowid)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(refs) == limit {
+			next = store.EncodeToken(after)
+			break
+		}
+		refs = append(refs, r)
+		after = rowid
+	}
+	return refs, next, rows.Err()
+}
+
+func (s *sqliteStore) RefsToFile(ctx context.Context, filename string, page store.Page) ([]*pb.Ref, string, error) {
+	return s.queryRefsPage(ctx, page, `to_file = ?`, filename)
+}
+
+func (s *sqliteStore) RefsFromFile(ctx context.Context, filename string) ([]*pb.Ref, error) {
+	return s.queryRefs(ctx, `from_file = ?`, filename)
+}
+
+func (s *sqliteStore) RefsFromIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error) {
+	return s.queryRefs(ctx, `from_package = ? AND from_ident = ?`, loadpath, ident)
+}
+
+func (s *sqliteStore) RefsToIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error) {
+	return s.queryRefs(ctx, `to_package = ? AND to_ident = ?`, loadpath, ident)
+}
+
+func (s *sqliteStore) RefsToPackage(ctx context.Context, loadpath string) ([]*pb.Ref, error) {
+	return s.queryRefs(ctx, `to_package = ?`, loadpath)
+}
+
+// queryStringsPage returns a page of the distinct strings of a
+// column that match a WHERE clause, in order. Page tokens hold the
+// last string of a page.
+func (s *sqliteStore) queryStringsPage(ctx context.Context, page store.Page, column, table, where string, args ...interface{}) ([]string, string, error) {
+	after := ""
+	if page.Token != "" {
+		if err := store.DecodeToken(page.Token, &after); err != nil {
+			return nil, "", err
+		}
+	}
+	limit := page.Limit()
+	// One more string is queried to find out if there's a next
+	// page.
+	args = append(args, after, limit+1)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT DISTINCT `+column+` FROM `+table+` WHERE `+where+` AND `+column+` > ? ORDER BY `+column+` LIMIT ?`,
+		args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	l := make([]string, 0)
+	next := ""
+	for rows.Next() {
+		var str string
+		if err := rows.Scan(&str); err != nil {
+			return nil, "", err
+		}
+		if len(l) == limit {
+			next = store.EncodeToken(l[limit-1])
+			break
+		}
+		l = append(l, str)
+	}
+	return l, next, rows.Err()
+}
+
+func (s *sqliteStore) PackageFiles(ctx context.Context, loadpath string, page store.Page) ([]string, string, error) {
+	return s.queryStringsPage(ctx, page, "filename", "files", `package = ?`, loadpath)
+}
+
+func (s *sqliteStore) ListPackages(ctx context.Context, prefix string, page store.Page) ([]string, string, error) {
+	// LIKE is case-insensitive and has wildcards, so the prefix is
+	// matched by comparing substrings instead.
+	return s.queryStringsPage(ctx, page, "loadpath", "packages", `substr(loadpath, 1, length(?)) = ?`,
+		prefix, prefix)
+}
+
+// SearchSymbols matches the symbols of the packages that start with
+// the query's package prefix in Go, then counts the Refs to the
+// matches that may be returned.
+func (s *sqliteStore) SearchSymbols(ctx context.Context, q symbols.Query) ([]*symbols.Result, error) {
+	// The other columns of a row with MAX(version) come from the
+	// row of the latest version.
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT package, ident, kind, filename, start_line, start_col, end_line, end_col, MAX(version)
+		FROM symbols WHERE substr(package, 1, length(?)) = ? GROUP BY package, ident`,
+		q.PackagePrefix, q.PackagePrefix)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*symbols.Result, 0)
+	for rows.Next() {
+		sym := &symbols.Symbol{Location: &pb.Location{Position: &pb.Position{}}}
+		loc, pos := sym.Location, sym.Location.Position
+		var version int64
+		if err := rows.Scan(&loc.Package, &loc.Ident, &sym.Kind,
+			&pos.Filename, &pos.StartLine, &pos.StartCol, &pos.EndLine, &pos.EndCol, &version); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if m := q.Match(sym); m != symbols.NoMatch {
+			results = append(results, &symbols.Result{Symbol: sym, Match: m})
+		}
+	}
+	// Rows are closed before querying again, as the database only
+	// has one connection.
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results = symbols.Survivors(q, results)
+	for _, r := range results {
+		loc := r.Location
+		if err := s.db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM refs WHERE to_package = ? AND to_ident = ? AND to_file = ? AND to_start_line = ? AND to_start_col = ?`,
+			loc.Package, r.Name(), loc.Position.Filename, loc.Position.StartLine, loc.Position.StartCol).Scan(&r.Refs); err != nil {
+			return nil, err
+		}
+	}
+	return symbols.Rank(q, results), nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/sqlite"
+	"github.com/korfuri/goref/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// openStore opens a Store in a temporary directory, which is removed
+// by the returned function.
+func openStore(t *testing.T) (store.Store, string, func()) {
+	dir, err := ioutil.TempDir("", "goref-sqlite")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "goref.db")
+	s, err := sqlite.Open(path)
+	assert.NoError(t, err)
+	return s, path, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSQLite_roundTrip(t *testing.T) {
+	s, _, cleanup := openStore(t)
+	defer cleanup()
+	storetest.RoundTrip(t, s)
+}
+
+func TestSQLite_pages(t *testing.T) {
+	s, _, cleanup := openStore(t)
+	defer cleanup()
+	storetest.Pages(t, s)
+}
+
+func TestSQLite_reopen(t *testing.T) {
+	s, path, cleanup := openStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	p := &goref.Package{Path: "a", Version: 1, Files: []string{"a/a.go"}}
+	assert.NoError(t, s.PutPackages(ctx, []*goref.Package{p}))
+	// Storing a package again replaces it.
+	assert.NoError(t, s.PutPackages(ctx, []*goref.Package{p}))
+	assert.NoError(t, s.Close())
+
+	s, err := sqlite.Open(path)
+	assert.NoError(t, err)
+	exists, err := s.PackageExists(ctx, "a", 1)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	files, _, err := s.PackageFiles(ctx, "a", store.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/a.go"}, files)
+	// ListPackages doesn't treat LIKE wildcards as such.
+	packages, _, err := s.ListPackages(ctx, "%", store.Page{})
+	assert.NoError(t, err)
+	assert.Empty(t, packages)
+	assert.NoError(t, s.Close())
+}
@@ -0,0 +1,114 @@
+// Package store defines Store, the interface between goref's indexer
+// and servers and the backends that persist a PackageGraph, such as
+// the one in package elasticsearch.
+package store
+
+import (
+	"context"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/symbols"
+	log "github.com/sirupsen/logrus"
+)
+
+// A Store persists the packages of PackageGraphs, with their files
+// and references, and answers the queries of goref's servers.
+//
+// Several versions of a package may be stored. Queries return results
+// from all stored versions.
+type Store interface {
+	// PackageExists returns whether a version of a package was
+	// stored by PutPackages. Errors are only returned if the
+	// backend can't be queried.
+	PackageExists(ctx context.Context, loadpath string, version int64) (bool, error)
+
+	// PutPackages stores packages, with their files and OutRefs.
+	// A package only exists once all its files and Refs are
+	// stored, so that PutPackages can be called again for
+	// packages it failed to store.
+	PutPackages(ctx context.Context, packages []*goref.Package) error
+
+	// RefsToFile returns a page of the Refs whose target is in the
+	// provided file, and the token of the next page.
+	RefsToFile(ctx context.Context, filename string, page Page) ([]*pb.Ref, string, error)
+
+	// RefsFromFile returns the Refs whose source is in the
+	// provided file.
+	RefsFromFile(ctx context.Context, filename string) ([]*pb.Ref, error)
+
+	// RefsFromIdent returns the Refs whose source is an
+	// identifier of a package. The source of Implementation and
+	// Extension Refs is the implementing type or the extending
+	// interface.
+	RefsFromIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error)
+
+	// RefsToIdent returns the Refs to an identifier of a package.
+	RefsToIdent(ctx context.Context, loadpath, ident string) ([]*pb.Ref, error)
+
+	// RefsToPackage returns the Refs to any identifier of a
+	// package.
+	RefsToPackage(ctx context.Context, loadpath string) ([]*pb.Ref, error)
+
+	// PackageFiles returns a page of the sorted files of a
+	// package, and the token of the next page.
+	PackageFiles(ctx context.Context, loadpath string, page Page) ([]string, string, error)
+
+	// ListPackages returns a page of the sorted load paths of the
+	// packages that start with prefix, and the token of the next
+	// page. An empty prefix lists all packages.
+	ListPackages(ctx context.Context, prefix string, page Page) ([]string, string, error)
+
+	// SearchSymbols returns the identifiers declared in packages
+	// that match a query, ranked by symbols.Rank. Identifiers
+	// declared in several versions of a package are returned once,
+	// from the latest version, and their Refs are the stored Refs
+	// to them.
+	SearchSymbols(ctx context.Context, q symbols.Query) ([]*symbols.Result, error)
+
+	// Close releases the resources held by the Store.
+	Close() error
+}
+
+// FilterF returns a function suitable for goref.PackageGraph.FilterF
+// that returns false if a package exists in the Store. It returns an
+// error if the Store can't be queried, so that LoadPackages fails
+// instead of loading every package.
+func FilterF(s Store) func(string, int64) (bool, error) {
+	return func(loadpath string, version int64) (bool, error) {
+		exists, err := s.PackageExists(context.Background(), loadpath, version)
+		return !exists, err
+	}
+}
+
+// LoadGraph stores the packages of a PackageGraph that the Store
+// doesn't have yet, with PutNewPackages.
+func LoadGraph(ctx context.Context, s Store, pg goref.PackageGraph) error {
+	packages := make([]*goref.Package, 0, len(pg.Packages))
+	for _, p := range pg.Packages {
+		packages = append(packages, p)
+	}
+	return PutNewPackages(ctx, s, packages)
+}
+
+// PutNewPackages stores the packages that the Store doesn't have yet.
+// Packages that already exist are found before anything is stored,
+// so that nothing is stored if the Store can't be queried.
+func PutNewPackages(ctx context.Context, s Store, packages []*goref.Package) error {
+	pending := make([]*goref.Package, 0)
+	for _, p := range packages {
+		exists, err := s.PackageExists(ctx, p.Path, p.Version)
+		if err != nil {
+			return err
+		}
+		if exists {
+			log.Infof("Package %s already exists in this store.", p)
+			continue
+		}
+		pending = append(pending, p)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	return s.PutPackages(ctx, pending)
+}
@@ -0,0 +1,123 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFilterF(t *testing.T) {
+	s := &mocks.Store{}
+	s.On("PackageExists", mock.Anything, "a", int64(1)).Return(true, nil)
+	s.On("PackageExists", mock.Anything, "b", int64(1)).Return(false, nil)
+	s.On("PackageExists", mock.Anything, "c", int64(1)).Return(false, errors.New("unreachable"))
+
+	f := store.FilterF(s)
+	pass, err := f("a", 1)
+	assert.NoError(t, err)
+	assert.False(t, pass)
+	pass, err = f("b", 1)
+	assert.NoError(t, err)
+	assert.True(t, pass)
+	_, err = f("c", 1)
+	assert.Error(t, err)
+}
+
+func TestLoadGraph(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.Packages["a"] = &goref.Package{Path: "a"}
+	pg.Packages["b"] = &goref.Package{Path: "b"}
+
+	s := &mocks.Store{}
+	s.On("PackageExists", mock.Anything, "a", int64(0)).Return(true, nil)
+	s.On("PackageExists", mock.Anything, "b", int64(0)).Return(false, nil)
+	s.On("PutPackages", mock.Anything, []*goref.Package{pg.Packages["b"]}).Return(nil)
+
+	assert.NoError(t, store.LoadGraph(context.Background(), s, *pg))
+	s.AssertExpectations(t)
+}
+
+func TestLoadGraph_nothingToStore(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.Packages["a"] = &goref.Package{Path: "a"}
+
+	s := &mocks.Store{}
+	s.On("PackageExists", mock.Anything, "a", int64(0)).Return(true, nil)
+
+	assert.NoError(t, store.LoadGraph(context.Background(), s, *pg))
+	s.AssertNotCalled(t, "PutPackages", mock.Anything, mock.Anything)
+}
+
+func TestLoadGraph_unreachable(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.Packages["a"] = &goref.Package{Path: "a"}
+	pg.Packages["b"] = &goref.Package{Path: "b"}
+
+	s := &mocks.Store{}
+	s.On("PackageExists", mock.Anything, "a", int64(0)).Return(false, nil)
+	s.On("PackageExists", mock.Anything, "b", int64(0)).Return(false, errors.New("unreachable"))
+
+	assert.Error(t, store.LoadGraph(context.Background(), s, *pg))
+	s.AssertNotCalled(t, "PutPackages", mock.Anything, mock.Anything)
+}
+
+func TestPutNewPackages(t *testing.T) {
+	a := &goref.Package{Path: "a", Version: 1}
+	b := &goref.Package{Path: "b", Version: 1}
+
+	s := &mocks.Store{}
+	s.On("PackageExists", mock.Anything, "a", int64(1)).Return(false, nil)
+	s.On("PackageExists", mock.Anything, "b", int64(1)).Return(true, nil)
+	s.On("PutPackages", mock.Anything, []*goref.Package{a}).Return(nil)
+
+	assert.NoError(t, store.PutNewPackages(context.Background(), s, []*goref.Package{a, b}))
+	assert.NoError(t, store.PutNewPackages(context.Background(), s, []*goref.Package{b}))
+	s.AssertExpectations(t)
+	s.AssertNumberOfCalls(t, "PutPackages", 1)
+}
+
+func TestPageStrings(t *testing.T) {
+	l := []string{"a", "b", "c", "d", "e"}
+	page, next, err := store.PageStrings(l, store.Page{Size: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, page)
+	assert.NotEmpty(t, next)
+
+	// Pages start after the last string of the previous page, even
+	// if it was removed since.
+	page, next, err = store.PageStrings([]string{"a", "c", "d", "e"}, store.Page{Size: 2, Token: next})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, page)
+	page, next, err = store.PageStrings(l, store.Page{Size: 2, Token: next})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e"}, page)
+	assert.Empty(t, next)
+
+	page, next, err = store.PageStrings(l, store.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, l, page)
+	assert.Empty(t, next)
+
+	_, _, err = store.PageStrings(l, store.Page{Token: "!"})
+	assert.Equal(t, store.ErrInvalidPageToken, err)
+}
+
+func TestNewPage(t *testing.T) {
+	p, err := store.NewPage(10, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, store.Page{Size: 10, Token: "token"}, p)
+	_, err = store.NewPage(-1, "")
+	assert.Error(t, err)
+}
+
+func TestPage_Limit(t *testing.T) {
+	assert.Equal(t, store.DefaultPageSize, store.Page{}.Limit())
+	assert.Equal(t, 10, store.Page{Size: 10}.Limit())
+	assert.Equal(t, store.MaxPageSize, store.Page{Size: store.MaxPageSize + 1}.Limit())
+}
@@ -0,0 +1,160 @@
+// Package storetest provides the tests that every implementation of
+// store.Store must pass. They store testprograms/interfaces and check
+// what the Store returns against its PackageGraph.
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/store"
+	"github.com/korfuri/goref/symbols"
+	"github.com/stretchr/testify/assert"
+)
+
+const pkgpath = "github.com/korfuri/goref/testprograms/interfaces"
+
+// loadGraph loads testprograms/interfaces and stores it into s.
+func loadGraph(t *testing.T, s store.Store) *goref.PackageGraph {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	assert.NoError(t, pg.LoadPackages([]string{pkgpath}, false))
+	pg.ComputeInterfaceImplementationMatrix()
+	assert.NoError(t, store.LoadGraph(context.Background(), s, *pg))
+	return pg
+}
+
+// expected returns the OutRefs of a PackageGraph that match f.
+func expected(pg *goref.PackageGraph, f func(r *goref.Ref) bool) []string {
+	refs := make([]string, 0)
+	for _, p := range pg.Packages {
+		for _, r := range p.OutRefs {
+			if f(r) {
+				refs = append(refs, r.ToProto().String())
+			}
+		}
+	}
+	return refs
+}
+
+// RoundTrip checks that an empty Store returns the packages, files,
+// Refs and symbols of the PackageGraph that it's loaded with.
+func RoundTrip(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	actual := func(refs []*pb.Ref, err error) []string {
+		assert.NoError(t, err)
+		l := make([]string, 0)
+		for _, r := range refs {
+			l = append(l, r.String())
+		}
+		return l
+	}
+
+	pg := loadGraph(t, s)
+
+	for _, p := range pg.Packages {
+		exists, err := s.PackageExists(ctx, p.Path, 0)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	}
+	exists, err := s.PackageExists(ctx, pkgpath, 1)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	packages, _, err := s.ListPackages(ctx, "github.com/korfuri/goref/testprograms/", store.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pkgpath, pkgpath + "/lib"}, packages)
+	packages, _, err = s.ListPackages(ctx, "", store.Page{})
+	assert.NoError(t, err)
+	assert.Len(t, packages, len(pg.Packages))
+
+	files, _, err := s.PackageFiles(ctx, pkgpath, store.Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, pg.Packages[pkgpath].Files, files)
+
+	lib := pkgpath + "/lib"
+	filename := pg.Packages[lib].Files[0]
+	toFile := expected(pg, func(r *goref.Ref) bool { return r.ToPosition.File == filename })
+	assert.NotEmpty(t, toFile)
+	refs, next, err := s.RefsToFile(ctx, filename, store.Page{})
+	assert.Empty(t, next)
+	assert.ElementsMatch(t, toFile, actual(refs, err))
+
+	filename = pg.Packages[pkgpath].Files[0]
+	fromFile := expected(pg, func(r *goref.Ref) bool { return r.FromPosition.File == filename })
+	assert.NotEmpty(t, fromFile)
+	assert.ElementsMatch(t, fromFile, actual(s.RefsFromFile(ctx, filename)))
+
+	toPackage := expected(pg, func(r *goref.Ref) bool { return r.ToPackage.Path == lib })
+	assert.ElementsMatch(t, toPackage, actual(s.RefsToPackage(ctx, lib)))
+
+	toIdent := expected(pg, func(r *goref.Ref) bool { return r.ToPackage.Path == lib && r.ToIdent == "IfaceLibA" })
+	assert.NotEmpty(t, toIdent)
+	assert.ElementsMatch(t, toIdent, actual(s.RefsToIdent(ctx, lib, "IfaceLibA")))
+	assert.Empty(t, actual(s.RefsToIdent(ctx, "does/not/exist", "IfaceLibA")))
+
+	fromIdent := expected(pg, func(r *goref.Ref) bool { return r.FromPackage.Path == pkgpath && r.FromIdent == "AB" })
+	assert.NotEmpty(t, fromIdent)
+	assert.ElementsMatch(t, fromIdent, actual(s.RefsFromIdent(ctx, pkgpath, "AB")))
+
+	syms := symbols.FromGraph(*pg)
+	for _, q := range []symbols.Query{
+		{Text: "IfaceLibA"},
+		{Text: "ILA", PackagePrefix: lib},
+		{Text: "IfaceLibAB", Kinds: []string{"type"}},
+		{Text: "Ifcae"},
+		{PackagePrefix: lib, Limit: symbols.MaxLimit},
+		// Only some of the prefix matches are returned.
+		{Text: "Iface", Limit: 2},
+	} {
+		expected := make([]*symbols.Result, 0)
+		for _, sym := range syms {
+			if m := q.Match(sym); m != symbols.NoMatch {
+				expected = append(expected, &symbols.Result{Symbol: sym, Match: m})
+			}
+		}
+		results, err := s.SearchSymbols(ctx, q)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, results, "Query %v", q)
+		assert.Equal(t, symbols.Rank(q, expected), results, "Query %v", q)
+	}
+}
+
+// Pages checks that an empty Store returns pages of Refs and of
+// packages once it's loaded with a PackageGraph.
+func Pages(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	pg := loadGraph(t, s)
+
+	// Pages of one Ref don't overlap and cover all Refs to the
+	// file.
+	filename := pg.Packages[pkgpath+"/lib"].Files[0]
+	all, _, err := s.RefsToFile(ctx, filename, store.Page{})
+	assert.NoError(t, err)
+	assert.True(t, len(all) > 1)
+	paged := make([]*pb.Ref, 0)
+	page := store.Page{Size: 1}
+	for {
+		refs, next, err := s.RefsToFile(ctx, filename, page)
+		assert.NoError(t, err)
+		assert.Len(t, refs, 1)
+		paged = append(paged, refs...)
+		if next == "" {
+			break
+		}
+		page.Token = next
+	}
+	assert.Equal(t, all, paged)
+
+	packages, next, err := s.ListPackages(ctx, "github.com/korfuri/goref/testprograms/", store.Page{Size: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pkgpath}, packages)
+	packages, next, err = s.ListPackages(ctx, "github.com/korfuri/goref/testprograms/", store.Page{Size: 1, Token: next})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{pkgpath + "/lib"}, packages)
+	assert.Empty(t, next)
+
+	_, _, err = s.RefsToFile(ctx, filename, store.Page{Token: "invalid"})
+	assert.Equal(t, store.ErrInvalidPageToken, err)
+}
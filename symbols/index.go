@@ -0,0 +1,148 @@
+package symbols
+
+import (
+	"strings"
+
+	"github.com/korfuri/goref"
+	pb "github.com/korfuri/goref/proto"
+)
+
+// An Index is an in-memory trigram index of symbols. It only matches
+// a query against the symbols that share enough trigrams with it to
+// match it, and only looks for camelCase matches, which are cheap to
+// find, in the others.
+type Index struct {
+	symbols []*Symbol
+
+	// words are the lowercase Words of the identifiers of
+	// symbols.
+	words [][]string
+
+	// trigrams maps each trigram of the lowercase identifiers of
+	// symbols, and of their Initials, to the positions in symbols
+	// of the symbols that contain it, in increasing order.
+	trigrams map[string][]int
+}
+
+// Trigrams returns the distinct trigrams of a string.
+func Trigrams(s string) []string {
+	rs := []rune(s)
+	seen := make(map[string]bool)
+	grams := make([]string, 0)
+	for i := 0; i+3 <= len(rs); i++ {
+		g := string(rs[i : i+3])
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
+
+// NewIndex returns an Index of symbols.
+func NewIndex(symbols []*Symbol) *Index {
+	idx := &Index{
+		symbols:  symbols,
+		trigrams: make(map[string][]int),
+	}
+	for i, s := range symbols {
+		words := Words(s.Location.Ident)
+		for j, w := range words {
+			words[j] = strings.ToLower(w)
+		}
+		idx.words = append(idx.words, words)
+		grams := Trigrams(strings.ToLower(s.Location.Ident))
+		grams = append(grams, Trigrams(Initials(s.Location.Ident))...)
+		for _, g := range grams {
+			// Trigrams of the identifier may also be
+			// trigrams of its initials.
+			if l := idx.trigrams[g]; len(l) == 0 || l[len(l)-1] != i {
+				idx.trigrams[g] = append(l, i)
+			}
+		}
+	}
+	return idx
+}
+
+// Len returns the number of symbols in the Index.
+func (idx *Index) Len() int {
+	return len(idx.symbols)
+}
+
+// candidates returns whether each symbol may be more than a camelCase
+// match of a lowercase query. Exact, prefix and substring matches
+// contain every trigram of the query, and each typo changes at most 4
+// of them (for a transposition), so symbols that share fewer trigrams
+// with the query can only be camelCase matches. Short queries, whose
+// typos may change all their trigrams, may match any symbol.
+func (idx *Index) candidates(q string) []bool {
+	result := make([]bool, len(idx.symbols))
+	grams := Trigrams(q)
+	threshold := len(grams) - 4*MaxEdits(q)
+	if threshold <= 0 {
+		for i := range result {
+			result[i] = true
+		}
+		return result
+	}
+	counts := make(map[int]int)
+	for _, g := range grams {
+		for _, i := range idx.trigrams[g] {
+			counts[i]++
+		}
+	}
+	for i, n := range counts {
+		result[i] = n >= threshold
+	}
+	return result
+}
+
+// Search returns the symbols that match a query, ranked by Rank.
+func (idx *Index) Search(q Query) []*Result {
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	results := make([]*Result, 0)
+	for i, candidate := range idx.candidates(text) {
+		s := idx.symbols[i]
+		m := NoMatch
+		if candidate {
+			m = q.Match(s)
+		} else if q.Accepts(s.Location.Package, s.Kind) && camelCase(text, idx.words[i]) {
+			m = CamelCaseMatch
+		}
+		if m != NoMatch {
+			results = append(results, &Result{Symbol: s, Match: m})
+		}
+	}
+	return Rank(q, results)
+}
+
+// FromGraph returns the symbols declared in the packages of a
+// PackageGraph. Their Refs are the Refs from other packages, which
+// are the ones that Stores keep.
+func FromGraph(pg goref.PackageGraph) []*Symbol {
+	// declKey identifies a declaration by the position and the
+	// name that Refs to it have.
+	type declKey struct {
+		pos  goref.Position
+		name string
+	}
+	symbols := make([]*Symbol, 0)
+	for _, p := range pg.Packages {
+		refs := make(map[declKey]int)
+		for _, r := range p.InRefs {
+			refs[declKey{r.ToPosition, r.ToIdent}]++
+		}
+		for _, d := range p.Decls() {
+			symbols = append(symbols, &Symbol{
+				Location: &pb.Location{
+					Position: d.Position.ToProto(),
+					Package:  p.Path,
+					Ident:    d.Ident(),
+				},
+				Kind: d.Kind.String(),
+				Refs: refs[declKey{d.Position, d.Name}],
+			})
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,118 @@
+package symbols_test
+
+import (
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/korfuri/goref/symbols"
+	"github.com/stretchr/testify/assert"
+)
+
+// findSymbol returns the Symbol with the provided package and
+// identifier in a slice of Symbols, or nil.
+func findSymbol(syms []*symbols.Symbol, loadpath, ident string) *symbols.Symbol {
+	for _, s := range syms {
+		if s.Location.Package == loadpath && s.Location.Ident == ident {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestFromGraph(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/unused"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	syms := symbols.FromGraph(*pg)
+
+	s := findSymbol(syms, libpath, "NewUsedType")
+	assert.NotNil(t, s)
+	assert.Equal(t, "func", s.Kind)
+	assert.Equal(t, 1, s.Refs)
+	assert.Contains(t, s.Location.Position.Filename, "testprograms/unused/lib/")
+
+	s = findSymbol(syms, libpath, "UsedType.UsedMethod")
+	assert.NotNil(t, s)
+	assert.Equal(t, "method", s.Kind)
+	assert.Equal(t, 1, s.Refs)
+
+	s = findSymbol(syms, libpath, "UnusedConst")
+	assert.NotNil(t, s)
+	assert.Equal(t, 0, s.Refs)
+}
+
+func TestIndex_search(t *testing.T) {
+	syms := []*symbols.Symbol{
+		symbol("github.com/korfuri/goref", "NewPackageGraph", "func", 40),
+		symbol("github.com/korfuri/goref", "PackageGraph", "type", 120),
+		symbol("github.com/korfuri/goref", "PackageGraph.LoadPackages", "method", 30),
+		symbol("github.com/korfuri/goref", "Package", "type", 200),
+		symbol("github.com/korfuri/goref/store", "NewPage", "func", 2),
+		symbol("golang.org/x/tools/go/loader", "Program.Package", "method", 5),
+	}
+	idx := symbols.NewIndex(syms)
+	assert.Equal(t, len(syms), idx.Len())
+
+	idents := func(results []*symbols.Result) []string {
+		l := make([]string, 0)
+		for _, r := range results {
+			l = append(l, r.Location.Ident+":"+r.Match.String())
+		}
+		return l
+	}
+
+	assert.Equal(t, []string{
+		"NewPackageGraph:camelcase",
+	}, idents(idx.Search(symbols.Query{Text: "NPG"})))
+	assert.Equal(t, []string{
+		"Package:exact",
+		"Program.Package:exact",
+		"PackageGraph:prefix",
+		"PackageGraph.LoadPackages:prefix",
+		"NewPackageGraph:camelcase",
+	}, idents(idx.Search(symbols.Query{Text: "package"})))
+	assert.Equal(t, []string{
+		"PackageGraph:exact",
+		"PackageGraph.LoadPackages:prefix",
+		"NewPackageGraph:camelcase",
+	}, idents(idx.Search(symbols.Query{Text: "PackageGraph"})))
+	assert.Equal(t, []string{
+		"PackageGraph.LoadPackages:fuzzy",
+	}, idents(idx.Search(symbols.Query{Text: "LoadPakages"})))
+	assert.Equal(t, []string{
+		"NewPackageGraph:camelcase",
+	}, idents(idx.Search(symbols.Query{Text: "NPaGr", Kinds: []string{"func"}})))
+	assert.Equal(t, []string{
+		"NewPackageGraph:prefix",
+		"NewPage:prefix",
+	}, idents(idx.Search(symbols.Query{Text: "NewPa", Kinds: []string{"func"}})))
+	assert.Equal(t, []string{
+		"NewPage:exact",
+	}, idents(idx.Search(symbols.Query{Text: "NewPage", PackagePrefix: "github.com/korfuri/goref/store"})))
+	assert.Len(t, idx.Search(symbols.Query{Limit: 2}), 2)
+	assert.Empty(t, idx.Search(symbols.Query{Text: "Corpus"}))
+}
+
+// TestIndex_matchesAll checks that an Index finds the same matches as
+// matching every symbol of a graph.
+func TestIndex_matchesAll(t *testing.T) {
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{"github.com/korfuri/goref/testprograms/unused"}, false)
+	syms := symbols.FromGraph(*pg)
+	idx := symbols.NewIndex(syms)
+
+	for _, text := range []string{"", "U", "ut", "Used", "usedtype", "UT", "UTUM", "Unsued", "UsedMethdo", "Type.Used", "sedMeth", "xyz"} {
+		q := symbols.Query{Text: text, Limit: symbols.MaxLimit}
+		expected := make([]*symbols.Result, 0)
+		for _, s := range syms {
+			if m := q.Match(s); m != symbols.NoMatch {
+				expected = append(expected, &symbols.Result{Symbol: s, Match: m})
+			}
+		}
+		assert.Equal(t, symbols.Rank(q, expected), idx.Search(q), "Query %q", text)
+	}
+}
@@ -0,0 +1,379 @@
+// Package symbols searches the identifiers declared in packages by
+// name. Queries match identifiers by prefix, by substring, by the
+// words of their camelCase name (e.g. "NPG" matches NewPackageGraph)
+// or with typos, and results are ranked by how well they match and by
+// how many Refs point to them.
+package symbols
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	pb "github.com/korfuri/goref/proto"
+)
+
+const (
+	// DefaultLimit is the number of results of a Query whose
+	// Limit is zero.
+	DefaultLimit = 50
+
+	// MaxLimit is the largest number of results of a Query.
+	// Larger limits are reduced to it.
+	MaxLimit = 1000
+)
+
+// A Symbol is an identifier declared in a package.
+type Symbol struct {
+	// Location of the declared identifier. Its Ident is
+	// qualified by its receiver type for methods and fields, as
+	// returned by goref.Decl.Ident.
+	Location *pb.Location
+
+	// Kind is the kind of declaration, as returned by
+	// goref.DeclKind.String, e.g. "func".
+	Kind string
+
+	// Refs is the number of Refs to the identifier.
+	Refs int
+}
+
+// Name returns the name of the symbol, which isn't qualified by a
+// receiver type. It's the To.Ident of the Refs to the symbol.
+func (s *Symbol) Name() string {
+	return s.Location.Ident[strings.LastIndex(s.Location.Ident, ".")+1:]
+}
+
+// A MatchType is how a query matches an identifier. Better matches
+// have greater values.
+type MatchType int
+
+// These are the possible types of matches.
+const (
+	// NoMatch is returned for identifiers that don't match.
+	NoMatch MatchType = iota
+
+	// FuzzyMatch is a match with typos.
+	FuzzyMatch
+
+	// SubstringMatch is a match anywhere in the identifier.
+	SubstringMatch
+
+	// CamelCaseMatch is a match of prefixes of consecutive words
+	// of the identifier, e.g. "NPG" or "NPGraph" for
+	// NewPackageGraph.
+	CamelCaseMatch
+
+	// PrefixMatch is a match of the start of the identifier, or
+	// of the start of its name for methods and fields.
+	PrefixMatch
+
+	// ExactMatch is a match of the whole identifier, or of the
+	// whole name of a method or field.
+	ExactMatch
+)
+
+func (mt MatchType) String() string {
+	switch mt {
+	case NoMatch:
+		return "none"
+	case FuzzyMatch:
+		return "fuzzy"
+	case SubstringMatch:
+		return "substring"
+	case CamelCaseMatch:
+		return "camelcase"
+	case PrefixMatch:
+		return "prefix"
+	case ExactMatch:
+		return "exact"
+	}
+	panic("Unknown MatchType used")
+}
+
+// A Query selects symbols.
+type Query struct {
+	// Text is matched against identifiers, ignoring case. An
+	// empty Text is a prefix of every identifier.
+	Text string
+
+	// Kinds are the kinds of declarations to return, e.g.
+	// "func". All kinds are returned if it's empty.
+	Kinds []string
+
+	// PackagePrefix is the prefix of the load paths of the
+	// packages to search.
+	PackagePrefix string
+
+	// Limit is the maximum number of results.
+	Limit int
+}
+
+// MaxResults returns the maximum number of results of the query.
+func (q Query) MaxResults() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return q.Limit
+}
+
+// Accepts returns whether a declaration of the provided kind in the
+// provided package is searched by the query.
+func (q Query) Accepts(loadpath, kind string) bool {
+	if !strings.HasPrefix(loadpath, q.PackagePrefix) {
+		return false
+	}
+	if len(q.Kinds) == 0 {
+		return true
+	}
+	for _, k := range q.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns how the query matches a symbol, or NoMatch if the
+// symbol isn't searched by the query.
+func (q Query) Match(s *Symbol) MatchType {
+	if !q.Accepts(s.Location.Package, s.Kind) {
+		return NoMatch
+	}
+	return Match(q.Text, s.Location.Ident)
+}
+
+// A Result is a Symbol that matched a Query.
+type Result struct {
+	*Symbol
+
+	// Match is how the Query matched the Symbol.
+	Match MatchType
+}
+
+// Rank sorts results by type of match, then by number of Refs, and
+// returns at most the query's MaxResults of them.
+func Rank(q Query, results []*Result) []*Result {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Match != b.Match {
+			return a.Match > b.Match
+		}
+		if a.Refs != b.Refs {
+			return a.Refs > b.Refs
+		}
+		if a.Location.Package != b.Location.Package {
+			return a.Location.Package < b.Location.Package
+		}
+		return a.Location.Ident < b.Location.Ident
+	})
+	if len(results) > q.MaxResults() {
+		results = results[:q.MaxResults()]
+	}
+	return results
+}
+
+// Survivors returns the results that Rank may return when the Refs
+// to them are counted: those whose match is at least as good as the
+// match of the last result that Rank returns, and which Refs rank
+// within their match. Stores only count the Refs to these.
+func Survivors(q Query, results []*Result) []*Result {
+	if len(results) <= q.MaxResults() {
+		return results
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Match > results[j].Match
+	})
+	n := q.MaxResults()
+	last := results[n-1].Match
+	for n < len(results) && results[n].Match == last {
+		n++
+	}
+	return results[:n]
+}
+
+// Words splits an identifier into its words, the way ElasticSearch's
+// goref_ident analyzer does: on characters that are neither letters
+// nor digits, between letters and digits and on case changes. For
+// instance "NewHTTPClient2" is made of "New", "HTTP", "Client" and
+// "2".
+func Words(ident string) []string {
+	words := make([]string, 0)
+	rs := []rune(ident)
+	start := -1
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(rs[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && boundary(rs, i) {
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(rs[start:]))
+	}
+	return words
+}
+
+// boundary returns whether a word of an identifier starts at the
+// i-th rune, which follows a letter or a digit.
+func boundary(rs []rune, i int) bool {
+	prev, r := rs[i-1], rs[i]
+	switch {
+	case unicode.IsDigit(prev) != unicode.IsDigit(r):
+		return true
+	case !unicode.IsUpper(prev) && unicode.IsUpper(r):
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(r):
+		// The last capital of an acronym starts the next word.
+		return i+1 < len(rs) && unicode.IsLetter(rs[i+1]) && !unicode.IsUpper(rs[i+1])
+	}
+	return false
+}
+
+// Initials returns the lowercase first letters of the words of an
+// identifier, e.g. "npg" for NewPackageGraph.
+func Initials(ident string) string {
+	initials := make([]rune, 0)
+	for _, w := range Words(ident) {
+		initials = append(initials, unicode.ToLower([]rune(w)[0]))
+	}
+	return string(initials)
+}
+
+// MaxEdits returns the number of typos allowed in a query, like
+// ElasticSearch's AUTO fuzziness: none for queries shorter than 3
+// characters, one up to 5 characters and two for longer queries.
+func MaxEdits(query string) int {
+	switch n := len([]rune(query)); {
+	case n < 3:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// Match returns how a query matches an identifier, ignoring case.
+// Methods and fields are matched by their qualified identifier (e.g.
+// "Type.Method") as well as by their name.
+func Match(query, ident string) MatchType {
+	q := strings.ToLower(strings.TrimSpace(query))
+	id := strings.ToLower(ident)
+	name := id[strings.LastIndex(id, ".")+1:]
+	switch {
+	case q == id || q == name:
+		return ExactMatch
+	case strings.HasPrefix(id, q) || strings.HasPrefix(name, q):
+		return PrefixMatch
+	case camelCase(q, Words(ident)):
+		return CamelCaseMatch
+	case strings.Contains(id, q):
+		return SubstringMatch
+	case fuzzy(q, name) || fuzzy(q, id):
+		return FuzzyMatch
+	}
+	return NoMatch
+}
+
+// camelCase returns whether a lowercase query is made of prefixes of
+// consecutive words, starting from any word.
+func camelCase(q string, words []string) bool {
+	for i := range words {
+		if humps(q, words[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// humps returns whether a lowercase query is made of prefixes of
+// consecutive words, starting from the first one.
+func humps(q string, words []string) bool {
+	if q == "" {
+		return true
+	}
+	if len(words) == 0 {
+		return false
+	}
+	w := strings.ToLower(words[0])
+	n := len(q)
+	if len(w) < n {
+		n = len(w)
+	}
+	for ; n > 0; n-- {
+		if q[:n] == w[:n] && humps(q[n:], words[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzy returns whether a lowercase query is within MaxEdits typos of
+// a prefix of a lowercase identifier. Typos are insertions, deletions
+// and substitutions of a character, and transpositions of two
+// adjacent characters.
+func fuzzy(q, id string) bool {
+	max := MaxEdits(q)
+	if max == 0 {
+		return false
+	}
+	a, b := []rune(q), []rune(id)
+	// Longer prefixes are more than max insertions away.
+	if len(b) > len(a)+max {
+		b = b[:len(a)+max]
+	}
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	prev := 0
+	for i := 1; i <= len(a); i++ {
+		best := d[i][0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+			best = min(best, d[i][j])
+		}
+		// Rows are computed from the two rows before them, and
+		// have no smaller distance than both, so the query
+		// can't match anymore.
+		if best > max && prev > max {
+			return false
+		}
+		prev = best
+	}
+	return min(d[len(a)][0], d[len(a)][1:]...) <= max
+}
+
+// min returns the smallest of its arguments.
+func min(n int, others ...int) int {
+	for _, o := range others {
+		if o < n {
+			n = o
+		}
+	}
+	return n
+}
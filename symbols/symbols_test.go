@@ -0,0 +1,135 @@
+package symbols_test
+
+import (
+	"testing"
+
+	pb "github.com/korfuri/goref/proto"
+	"github.com/korfuri/goref/symbols"
+	"github.com/stretchr/testify/assert"
+)
+
+// symbol returns a Symbol with the provided package, identifier, kind
+// and number of Refs.
+func symbol(loadpath, ident, kind string, refs int) *symbols.Symbol {
+	return &symbols.Symbol{
+		Location: &pb.Location{Package: loadpath, Ident: ident},
+		Kind:     kind,
+		Refs:     refs,
+	}
+}
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"New", "Package", "Graph"}, symbols.Words("NewPackageGraph"))
+	assert.Equal(t, []string{"New", "HTTP", "Client", "2"}, symbols.Words("NewHTTPClient2"))
+	assert.Equal(t, []string{"Package", "Graph", "Load", "Packages"}, symbols.Words("PackageGraph.LoadPackages"))
+	assert.Equal(t, []string{"max", "Page", "Size"}, symbols.Words("max_PageSize"))
+	assert.Equal(t, []string{"URL"}, symbols.Words("URL"))
+	assert.Empty(t, symbols.Words("_"))
+
+	assert.Equal(t, "npg", symbols.Initials("NewPackageGraph"))
+	assert.Equal(t, "nhc2", symbols.Initials("NewHTTPClient2"))
+}
+
+func TestMatch(t *testing.T) {
+	for _, tc := range []struct {
+		query, ident string
+		expected     symbols.MatchType
+	}{
+		{"NewPackageGraph", "NewPackageGraph", symbols.ExactMatch},
+		{"newpackagegraph", "NewPackageGraph", symbols.ExactMatch},
+		{"LoadPackages", "PackageGraph.LoadPackages", symbols.ExactMatch},
+		{"NewPack", "NewPackageGraph", symbols.PrefixMatch},
+		{"LoadP", "PackageGraph.LoadPackages", symbols.PrefixMatch},
+		{"", "NewPackageGraph", symbols.PrefixMatch},
+		{"NPG", "NewPackageGraph", symbols.CamelCaseMatch},
+		{"npg", "NewPackageGraph", symbols.CamelCaseMatch},
+		{"NewPacG", "NewPackageGraph", symbols.CamelCaseMatch},
+		{"NPGraph", "NewPackageGraph", symbols.CamelCaseMatch},
+		{"PG", "NewPackageGraph", symbols.CamelCaseMatch},
+		{"Graph", "NewPackageGraph", symbols.CamelCaseMatch},
+		{"ckageGr", "NewPackageGraph", symbols.SubstringMatch},
+		{"NewPakcageGraph", "NewPackageGraph", symbols.FuzzyMatch},
+		{"NewPackgeGraph", "NewPackageGraph", symbols.FuzzyMatch},
+		{"Grpah", "Graph", symbols.FuzzyMatch},
+		{"LoadPakages", "PackageGraph.LoadPackages", symbols.FuzzyMatch},
+		// Short queries don't allow typos.
+		{"Gp", "Graph", symbols.NoMatch},
+		{"NewPkcgeGrahp", "NewPackageGraph", symbols.NoMatch},
+		{"GPN", "NewPackageGraph", symbols.NoMatch},
+		{"Corpus", "NewPackageGraph", symbols.NoMatch},
+	} {
+		assert.Equal(t, tc.expected, symbols.Match(tc.query, tc.ident), "%q matching %q", tc.query, tc.ident)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	assert.Equal(t, symbols.DefaultLimit, symbols.Query{}.MaxResults())
+	assert.Equal(t, 3, symbols.Query{Limit: 3}.MaxResults())
+	assert.Equal(t, symbols.MaxLimit, symbols.Query{Limit: symbols.MaxLimit + 1}.MaxResults())
+
+	q := symbols.Query{
+		Text:          "Graph",
+		Kinds:         []string{"type", "func"},
+		PackagePrefix: "github.com/korfuri/",
+	}
+	assert.True(t, q.Accepts("github.com/korfuri/goref", "type"))
+	assert.False(t, q.Accepts("github.com/korfuri/goref", "method"))
+	assert.False(t, q.Accepts("golang.org/x/tools/go/loader", "type"))
+	assert.True(t, symbols.Query{}.Accepts("golang.org/x/tools/go/loader", "method"))
+
+	assert.Equal(t, symbols.ExactMatch, q.Match(symbol("github.com/korfuri/goref", "Graph", "type", 0)))
+	assert.Equal(t, symbols.NoMatch, q.Match(symbol("github.com/korfuri/goref", "Graph", "var", 0)))
+	assert.Equal(t, symbols.NoMatch, q.Match(symbol("github.com/korfuri/goref", "Corpus", "type", 0)))
+}
+
+func TestRank(t *testing.T) {
+	results := []*symbols.Result{
+		{Symbol: symbol("b", "Fuzzy", "func", 100), Match: symbols.FuzzyMatch},
+		{Symbol: symbol("b", "Prefix", "func", 1), Match: symbols.PrefixMatch},
+		{Symbol: symbol("b", "PrefixMore", "func", 10), Match: symbols.PrefixMatch},
+		{Symbol: symbol("b", "Exact", "func", 0), Match: symbols.ExactMatch},
+		{Symbol: symbol("a", "Prefix", "func", 1), Match: symbols.PrefixMatch},
+	}
+	ranked := symbols.Rank(symbols.Query{}, results)
+	idents := make([]string, 0)
+	for _, r := range ranked {
+		idents = append(idents, r.Location.Package+"."+r.Location.Ident)
+	}
+	assert.Equal(t, []string{"b.Exact", "b.PrefixMore", "a.Prefix", "b.Prefix", "b.Fuzzy"}, idents)
+
+	assert.Len(t, symbols.Rank(symbols.Query{Limit: 2}, results), 2)
+}
+
+func TestSurvivors(t *testing.T) {
+	results := func() []*symbols.Result {
+		return []*symbols.Result{
+			{Symbol: symbol("p", "Fuzzy", "func", 100), Match: symbols.FuzzyMatch},
+			{Symbol: symbol("p", "Prefix", "func", 1), Match: symbols.PrefixMatch},
+			{Symbol: symbol("p", "Exact", "func", 0), Match: symbols.ExactMatch},
+			{Symbol: symbol("p", "PrefixMore", "func", 10), Match: symbols.PrefixMatch},
+			{Symbol: symbol("p", "Substring", "func", 5), Match: symbols.SubstringMatch},
+		}
+	}
+	idents := func(results []*symbols.Result) []string {
+		l := make([]string, 0)
+		for _, r := range results {
+			l = append(l, r.Location.Ident)
+		}
+		return l
+	}
+
+	// All prefix matches may be returned, depending on their
+	// Refs, and worse matches can't be.
+	assert.Equal(t, []string{"Exact", "Prefix", "PrefixMore"}, idents(symbols.Survivors(symbols.Query{Limit: 2}, results())))
+	assert.Equal(t, []string{"Exact", "Prefix", "PrefixMore"}, idents(symbols.Survivors(symbols.Query{Limit: 3}, results())))
+	assert.Equal(t, []string{"Exact"}, idents(symbols.Survivors(symbols.Query{Limit: 1}, results())))
+	assert.Len(t, symbols.Survivors(symbols.Query{Limit: 4}, results()), 4)
+	assert.Len(t, symbols.Survivors(symbols.Query{}, results()), 5)
+
+	// Ranking the survivors returns the same results as ranking
+	// all results.
+	for limit := 1; limit <= 5; limit++ {
+		q := symbols.Query{Limit: limit}
+		assert.Equal(t, idents(symbols.Rank(q, results())), idents(symbols.Rank(q, symbols.Survivors(q, results()))))
+	}
+}
@@ -0,0 +1,5 @@
+// Package apidiff contains two versions of a GOPATH under testdata,
+// to test API breaking-change detection. The old GOPATH contains a
+// library and an application that uses it, the new GOPATH contains
+// a newer version of the library only.
+package apidiff
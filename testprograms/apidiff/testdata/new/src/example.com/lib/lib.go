@@ -0,0 +1,18 @@
+// Package lib is a library whose API changes between versions.
+package lib
+
+// Config is a struct whose Timeout field is removed.
+type Config struct {
+	Name string
+}
+
+// New has its signature changed.
+func New(name string, verbose bool) *Config {
+	return &Config{Name: name}
+}
+
+// Unchanged doesn't change.
+func Unchanged() {}
+
+// Added is new, which doesn't break anything.
+func Added() {}
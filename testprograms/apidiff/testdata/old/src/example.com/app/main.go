@@ -0,0 +1,17 @@
+// Package main uses the old version of lib.
+package main
+
+import "example.com/lib"
+
+// Closer is implemented by lib.Config.
+type Closer interface {
+	Close() error
+}
+
+func main() {
+	c := lib.New("app")
+	c.Timeout = 10
+	lib.Removed()
+	lib.Unchanged()
+	var _ Closer = c
+}
@@ -0,0 +1,25 @@
+// Package lib is a library whose API changes between versions.
+package lib
+
+// Config is a struct whose Timeout field is removed.
+type Config struct {
+	Name    string
+	Timeout int
+}
+
+// New has its signature changed.
+func New(name string) *Config {
+	return &Config{Name: name}
+}
+
+// Close is removed, which breaks Config's implementation of
+// app.Closer.
+func (c Config) Close() error {
+	return nil
+}
+
+// Removed is removed.
+func Removed() {}
+
+// Unchanged doesn't change.
+func Unchanged() {}
@@ -0,0 +1,30 @@
+package lib
+
+type Shape interface {
+	Area() int
+}
+
+type Square struct {
+	Side int
+}
+
+func (s Square) Area() int {
+	return s.Side * s.Side
+}
+
+// Circle is never instantiated.
+type Circle struct {
+	Radius int
+}
+
+func (c *Circle) Area() int {
+	return 3 * c.Radius * c.Radius
+}
+
+func Total(shapes ...Shape) int {
+	total := 0
+	for _, s := range shapes {
+		total += s.Area()
+	}
+	return total
+}
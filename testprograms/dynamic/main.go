@@ -0,0 +1,21 @@
+// Package main is a test program that demonstrates dynamic calls,
+// resolved with a call graph, in goref.
+package main
+
+import (
+	"github.com/korfuri/goref/testprograms/dynamic/lib"
+)
+
+type triangle struct {
+	base, height int
+}
+
+func (t triangle) Area() int {
+	return t.base * t.height / 2
+}
+
+func main() {
+	var s lib.Shape = lib.Square{Side: 2}
+	s.Area()
+	lib.Total(s, triangle{base: 1, height: 2})
+}
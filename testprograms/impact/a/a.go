@@ -0,0 +1,12 @@
+// Package a is at the bottom of the dependency chain.
+package a
+
+// A is used by b.B.
+func A() int {
+	return 1
+}
+
+// Other is used by b.Indep and d.D.
+func Other() int {
+	return 2
+}
@@ -0,0 +1,19 @@
+// Package b depends on a.
+package b
+
+import "github.com/korfuri/goref/testprograms/impact/a"
+
+// B calls a.A.
+func B() int {
+	return a.A()
+}
+
+// Local only calls a.A through B.
+func Local() int {
+	return B()
+}
+
+// Indep doesn't depend on a.A.
+func Indep() int {
+	return a.Other()
+}
@@ -0,0 +1,9 @@
+package b
+
+import "testing"
+
+func TestB(t *testing.T) {
+	if B() != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,9 @@
+// Package c depends on b.
+package c
+
+import "github.com/korfuri/goref/testprograms/impact/b"
+
+// C calls b.Local.
+func C() int {
+	return b.Local()
+}
@@ -0,0 +1,9 @@
+// Package d depends on a, but not on a.A.
+package d
+
+import "github.com/korfuri/goref/testprograms/impact/a"
+
+// D calls a.Other.
+func D() int {
+	return a.Other()
+}
@@ -0,0 +1,14 @@
+// Package main is a test program for change-impact analysis. Its
+// dependency chain is main -> c -> b -> a, and d is unrelated to
+// a.A.
+package main
+
+import (
+	"github.com/korfuri/goref/testprograms/impact/c"
+	"github.com/korfuri/goref/testprograms/impact/d"
+)
+
+func main() {
+	c.C()
+	d.D()
+}
@@ -0,0 +1,60 @@
+// Package lib exports identifiers, some of which are used by package
+// main and some of which are not.
+package lib
+
+// UsedConst is used by main.
+const UsedConst = 1
+
+// UnusedConst is not used by main.
+const UnusedConst = 2
+
+// UsedVar is used by main.
+var UsedVar = 3
+
+// UnusedVar is not used by main.
+var UnusedVar = 4
+
+// UsedType is used by main.
+type UsedType struct {
+	// UsedField is used by main.
+	UsedField int
+
+	// UnusedField is not used by main, but fields are never
+	// reported.
+	UnusedField int
+}
+
+// UnusedType is not used by main.
+type UnusedType struct{}
+
+// NewUsedType is used by main.
+func NewUsedType() *UsedType {
+	return &UsedType{}
+}
+
+// UnusedFunc is not used by main.
+func UnusedFunc() {
+	unexportedFunc()
+}
+
+// unexportedFunc is never reported as it's not exported.
+func unexportedFunc() {}
+
+// UsedMethod is used by main.
+func (t *UsedType) UsedMethod() {}
+
+// UnusedMethod is not used by main.
+func (t *UsedType) UnusedMethod() {}
+
+// String is not called directly, but implements fmt.Stringer.
+func (t *UsedType) String() string {
+	return "UsedType"
+}
+
+// Error is not called directly, but implements the predeclared error
+// interface.
+func (t *UsedType) Error() string {
+	return "UsedType"
+}
+
+func init() {}
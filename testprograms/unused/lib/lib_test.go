@@ -0,0 +1,8 @@
+package lib
+
+import "testing"
+
+// TestUnusedFunc is an entry point and is not reported.
+func TestUnusedFunc(t *testing.T) {
+	UnusedFunc()
+}
@@ -0,0 +1,16 @@
+// Package main is a test program that uses some, but not all, of
+// the exported identifiers of its library.
+package main
+
+import (
+	"fmt"
+
+	"github.com/korfuri/goref/testprograms/unused/lib"
+)
+
+func main() {
+	t := lib.NewUsedType()
+	t.UsedMethod()
+	t.UsedField = 42
+	fmt.Println(t, lib.UsedConst, lib.UsedVar)
+}
@@ -0,0 +1,118 @@
+package goref
+
+import (
+	"go/types"
+	"strings"
+)
+
+// testFuncPrefixes are the prefixes of functions that the go tool
+// treats as entry points in _test.go files.
+var testFuncPrefixes = []string{"Test", "Benchmark", "Example", "Fuzz"}
+
+// isEntryPoint returns whether a declaration is called by the
+// runtime or by the go tool rather than by other packages.
+func isEntryPoint(d *Decl) bool {
+	if d.Kind != FuncDecl {
+		return false
+	}
+	if d.Name == "main" || d.Name == "init" {
+		return true
+	}
+	if strings.HasSuffix(d.Position.File, "_test.go") {
+		for _, prefix := range testFuncPrefixes {
+			if strings.HasPrefix(d.Name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// satisfiesInterface returns whether the method described by d is
+// required by any of the provided interfaces that its receiver type
+// implements. Methods of interfaces always satisfy their own
+// interface.
+func satisfiesInterface(d *Decl, ifaces []*types.Interface) bool {
+	recv, ok := d.Package.Types.Scope().Lookup(d.Recv).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	typ := recv.Type()
+	if types.IsInterface(typ) {
+		return true
+	}
+	for _, i := range ifaces {
+		for m := 0; m < i.NumMethods(); m++ {
+			if i.Method(m).Name() != d.Name {
+				continue
+			}
+			if types.Implements(typ, i) || types.Implements(types.NewPointer(typ), i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UnusedDecls returns the exported declarations in the graph that
+// are not referenced by any other package in the graph, sorted by
+// package load path and position.
+//
+// Entry points are never reported: main and init functions, test
+// functions, methods required by an interface of the graph or by the
+// predeclared error interface that their type implements, and all
+// declarations of main packages. A
+// type is considered used if any of its methods or fields is used.
+// Fields themselves are never reported.
+//
+// Refs are only computed for packages that pass the graph's filterF,
+// so declarations used solely by filtered packages are reported as
+// unused.
+func (pg *PackageGraph) UnusedDecls() []*Decl {
+	ifaces := []*types.Interface{
+		types.Universe.Lookup("error").Type().Underlying().(*types.Interface),
+	}
+	for _, p := range pg.Packages {
+		for _, iface := range p.Interfaces {
+			ifaces = append(ifaces, iface.Underlying().(*types.Interface))
+		}
+	}
+
+	unused := make([]*Decl, 0)
+	for _, p := range pg.Packages {
+		if p.Name == "main" {
+			continue
+		}
+		decls := p.Decls()
+
+		// A type is considered used if any of its methods or
+		// fields is used, as values of that type may be
+		// obtained without naming the type.
+		refd := make(map[Position]bool)
+		for _, r := range p.InRefs {
+			if r.FromPackage != p {
+				refd[r.ToPosition] = true
+			}
+		}
+		used := make(map[string]bool)
+		for _, d := range decls {
+			if refd[d.Position] {
+				used[d.Ident()] = true
+				used[d.Recv] = true
+			}
+		}
+
+		for _, d := range decls {
+			if !d.Exported() || d.Kind == FieldDecl || isEntryPoint(d) || used[d.Ident()] {
+				continue
+			}
+			if d.Kind == MethodDecl && satisfiesInterface(d, ifaces) {
+				continue
+			}
+			unused = append(unused, d)
+		}
+	}
+
+	sortDecls(unused)
+	return unused
+}
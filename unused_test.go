@@ -0,0 +1,62 @@
+package goref_test
+
+import (
+	"testing"
+
+	"github.com/korfuri/goref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnusedDecls(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/unused"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, true)
+	assert.Contains(t, pg.Packages, libpath)
+
+	unused := make(map[string]goref.DeclKind)
+	for _, d := range pg.UnusedDecls() {
+		if d.Package.Path == libpath {
+			unused[d.Ident()] = d.Kind
+		}
+	}
+	assert.Equal(t, map[string]goref.DeclKind{
+		"UnusedConst":           goref.ConstDecl,
+		"UnusedVar":             goref.VarDecl,
+		"UnusedType":            goref.TypeDecl,
+		"UnusedFunc":            goref.FuncDecl,
+		"UsedType.UnusedMethod": goref.MethodDecl,
+	}, unused)
+
+	// Declarations of main packages are never reported.
+	for _, d := range pg.UnusedDecls() {
+		assert.NotEqual(t, pkgpath, d.Package.Path)
+	}
+}
+
+func TestDecls(t *testing.T) {
+	const (
+		pkgpath = "github.com/korfuri/goref/testprograms/unused"
+		libpath = pkgpath + "/lib"
+	)
+
+	pg := goref.NewPackageGraph(goref.ConstantVersion(0))
+	pg.LoadPackages([]string{pkgpath}, false)
+	lib := pg.Packages[libpath]
+
+	var used *goref.Decl
+	for _, d := range lib.Decls() {
+		if d.Ident() == "UsedType.UsedMethod" {
+			used = d
+		}
+	}
+	assert.NotNil(t, used)
+	assert.Equal(t, goref.MethodDecl, used.Kind)
+	assert.Equal(t, "method "+libpath+".UsedType.UsedMethod", used.String())
+	refs := lib.InRefsTo(used)
+	assert.Len(t, refs, 1)
+	assert.EqualValues(t, goref.Call, refs[0].RefType)
+}